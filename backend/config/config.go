@@ -2,13 +2,19 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Port     string
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Port      string
+	PublicURL string // 站点的对外访问地址，如 https://vote.example.com，用于分享链接和预览图片
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Signing   SigningConfig
+	Chart     ChartConfig
+	Storage   StorageConfig
 }
 
 type DatabaseConfig struct {
@@ -20,9 +26,35 @@ type JWTConfig struct {
 	ExpireTime time.Duration
 }
 
+type SigningConfig struct {
+	Key string // Base64 编码的 32 字节 Ed25519 种子，为空时不能签发和验证证书
+}
+
+type ChartConfig struct {
+	Font string // 图表 PNG 的补充字体文件路径（TTF/OTF/TTC），为空时使用编译时内置的补充字体
+}
+
+// StorageConfig 上传文件的存储位置
+type StorageConfig struct {
+	Driver   string // local 或 s3
+	Dir      string // local：保存文件的目录
+	BaseURL  string // local：访问文件的 URL 前缀
+	MaxSize  int64  // 单个图片的最大字节数
+	Endpoint string // s3：服务地址，如 http://minio:9000
+	Region   string
+	Bucket   string
+	// s3：访问文件的 URL 前缀，为空时使用 Endpoint/Bucket
+	PublicURL string
+	AccessKey string
+	SecretKey string
+}
+
+var current *Config
+
 func Load() *Config {
-	return &Config{
-		Port: getEnv("PORT", ":8080"),
+	current = &Config{
+		Port:      getEnv("PORT", ":8080"),
+		PublicURL: strings.TrimRight(getEnv("PUBLIC_URL", "http://localhost"), "/"),
 		Database: DatabaseConfig{
 			DSN: getEnv("DATABASE_DSN", "root:root@tcp(localhost:3306)/vote_db?charset=utf8mb4&parseTime=True&loc=Local"),
 		},
@@ -30,7 +62,35 @@ func Load() *Config {
 			ExpireTime: time.Hour * 24,
 			Secret:     getEnv("JWT_SECRET", "default_secret_key"),
 		},
+		Signing: SigningConfig{
+			Key: getEnv("SIGNING_KEY", ""),
+		},
+		Chart: ChartConfig{
+			Font: getEnv("CHART_FONT", ""),
+		},
+		Storage: StorageConfig{
+			Driver:    getEnv("STORAGE_DRIVER", "local"),
+			Dir:       getEnv("STORAGE_DIR", "uploads"),
+			BaseURL:   getEnv("STORAGE_BASE_URL", "/uploads"),
+			MaxSize:   getEnvInt("STORAGE_MAX_SIZE", 5<<20),
+			Endpoint:  getEnv("S3_ENDPOINT", ""),
+			Region:    getEnv("S3_REGION", "us-east-1"),
+			Bucket:    getEnv("S3_BUCKET", ""),
+			PublicURL: getEnv("S3_PUBLIC_URL", ""),
+			AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			SecretKey: getEnv("S3_SECRET_KEY", ""),
+		},
 	}
+
+	return current
+}
+
+// Get 返回已加载的配置，未加载时先加载
+func Get() *Config {
+	if current == nil {
+		return Load()
+	}
+	return current
 }
 
 func getEnv(key, defaultValue string) string {
@@ -40,3 +100,11 @@ func getEnv(key, defaultValue string) string {
 
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int64) int64 {
+	if value, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil && value > 0 {
+		return value
+	}
+
+	return defaultValue
+}
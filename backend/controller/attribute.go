@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttributeController struct {
+	attributeService *service.AttributeService
+}
+
+func NewAttributeController() *AttributeController {
+	return &AttributeController{
+		attributeService: service.NewAttributeService(),
+	}
+}
+
+func (ctrl *AttributeController) DefineAttribute(c *gin.Context) {
+	var req dto.AttributeDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.attributeService.DefineAttribute(&req, userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "属性保存成功", nil)
+}
+
+func (ctrl *AttributeController) DeleteAttribute(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.attributeService.DeleteAttribute(c.Param("name"), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "属性删除成功", nil)
+}
+
+func (ctrl *AttributeController) GetAttributes(c *gin.Context) {
+	attributes, err := ctrl.attributeService.GetAttributes()
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, attributes)
+}
+
+func (ctrl *AttributeController) GetMyAttributes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	attributes, err := ctrl.attributeService.GetUserAttributes(userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, attributes)
+}
+
+func (ctrl *AttributeController) SetMyAttributes(c *gin.Context) {
+	var req dto.SetAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.attributeService.SetUserAttributes(userID.(uint), &req, userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "属性保存成功", nil)
+}
+
+func (ctrl *AttributeController) SetUserAttributes(c *gin.Context) {
+	targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	var req dto.SetAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.attributeService.SetUserAttributes(uint(targetID), &req, userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "属性保存成功", nil)
+}
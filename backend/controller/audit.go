@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService *service.AuditService
+}
+
+func NewAuditController() *AuditController {
+	return &AuditController{
+		auditService: service.NewAuditService(),
+	}
+}
+
+func (ctrl *AuditController) CreateAudit(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var req dto.CreateAuditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	audit, err := ctrl.auditService.CreateAudit(uint(id), &req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "审计创建成功", audit)
+}
+
+func (ctrl *AuditController) GetVoteAudits(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	audits, err := ctrl.auditService.GetVoteAudits(uint(id), userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, audits)
+}
+
+func (ctrl *AuditController) GetAudit(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	audit, err := ctrl.auditService.GetAudit(uint(id), userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, audit)
+}
+
+func (ctrl *AuditController) DrawSamples(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	// 请求体可以为空，此时按预计所需抽样数抽取
+	var req dto.DrawSamplesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	audit, err := ctrl.auditService.DrawSamples(uint(id), &req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "抽样成功", audit)
+}
+
+func (ctrl *AuditController) RecordSample(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+	sequence, err := strconv.Atoi(c.Param("sequence"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的抽样序号")
+		return
+	}
+
+	var req dto.RecordSampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	audit, err := ctrl.auditService.RecordSample(uint(id), sequence, &req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "核对结果已记录", audit)
+}
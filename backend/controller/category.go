@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController struct {
+	categoryService *service.CategoryService
+}
+
+func NewCategoryController() *CategoryController {
+	return &CategoryController{
+		categoryService: service.NewCategoryService(),
+	}
+}
+
+func (ctrl *CategoryController) GetCategories(c *gin.Context) {
+	categories, err := ctrl.categoryService.GetCategories()
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, categories)
+}
+
+func (ctrl *CategoryController) CreateCategory(c *gin.Context) {
+	var req dto.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	category, err := ctrl.categoryService.CreateCategory(&req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "分类创建成功", category)
+}
+
+func (ctrl *CategoryController) UpdateCategory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var req dto.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	category, err := ctrl.categoryService.UpdateCategory(uint(id), &req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "分类更新成功", category)
+}
+
+func (ctrl *CategoryController) DeleteCategory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.categoryService.DeleteCategory(uint(id), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "分类删除成功", nil)
+}
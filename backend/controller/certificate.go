@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCertificateSize 验证时接受的证书文件大小上限
+const maxCertificateSize = 10 << 20
+
+type CertificateController struct {
+	certificateService *service.CertificateService
+}
+
+func NewCertificateController() *CertificateController {
+	return &CertificateController{
+		certificateService: service.NewCertificateService(),
+	}
+}
+
+func (ctrl *CertificateController) GetCertificate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	pdf, err := ctrl.certificateService.GetCertificate(uint(id), userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="vote-%d-certificate.pdf"`, id))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// VerifyCertificate 接受 multipart 表单中的 file 字段，或直接以请求体上传证书
+func (ctrl *CertificateController) VerifyCertificate(c *gin.Context) {
+	var reader io.Reader
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "文件读取失败")
+			return
+		}
+		defer f.Close()
+		reader = f
+	} else {
+		reader = c.Request.Body
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxCertificateSize+1))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "文件读取失败")
+		return
+	}
+	if len(data) == 0 || len(data) > maxCertificateSize {
+		utils.Error(c, http.StatusBadRequest, "无效的证书文件")
+		return
+	}
+
+	result, err := ctrl.certificateService.VerifyCertificate(data)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+func (ctrl *CertificateController) PublicKey(c *gin.Context) {
+	key, err := ctrl.certificateService.PublicKey()
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, key)
+}
@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CollaboratorController struct {
+	collaboratorService *service.CollaboratorService
+}
+
+func NewCollaboratorController() *CollaboratorController {
+	return &CollaboratorController{
+		collaboratorService: service.NewCollaboratorService(),
+	}
+}
+
+func (ctrl *CollaboratorController) GetCollaborators(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	collaborators, err := ctrl.collaboratorService.GetCollaborators(uint(id))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, collaborators)
+}
+
+func (ctrl *CollaboratorController) SetCollaborator(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var req dto.CollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.collaboratorService.SetCollaborator(uint(id), &req, userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "协作者已更新", nil)
+}
+
+func (ctrl *CollaboratorController) RemoveCollaborator(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	collaboratorID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.collaboratorService.RemoveCollaborator(uint(id), uint(collaboratorID), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "协作者已移除", nil)
+}
+
+func (ctrl *CollaboratorController) CreateTransfer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var req dto.TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	transfer, err := ctrl.collaboratorService.CreateTransfer(uint(id), &req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "转移请求已发送", gin.H{"id": transfer.ID})
+}
+
+func (ctrl *CollaboratorController) CancelTransfer(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.collaboratorService.CancelTransfer(uint(id), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "转移已取消", nil)
+}
+
+func (ctrl *CollaboratorController) GetIncomingTransfers(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	transfers, err := ctrl.collaboratorService.GetIncomingTransfers(userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, transfers)
+}
+
+func (ctrl *CollaboratorController) AcceptTransfer(c *gin.Context) {
+	transferID, err := strconv.ParseUint(c.Param("transferId"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.collaboratorService.AcceptTransfer(uint(transferID), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已接受所有权转移", nil)
+}
+
+func (ctrl *CollaboratorController) RejectTransfer(c *gin.Context) {
+	transferID, err := strconv.ParseUint(c.Param("transferId"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.collaboratorService.RejectTransfer(uint(transferID), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已拒绝所有权转移", nil)
+}
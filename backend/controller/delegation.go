@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DelegationController struct {
+	delegationService *service.DelegationService
+}
+
+func NewDelegationController() *DelegationController {
+	return &DelegationController{
+		delegationService: service.NewDelegationService(),
+	}
+}
+
+func (ctrl *DelegationController) SetDelegation(c *gin.Context) {
+	var req dto.DelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	delegation, err := ctrl.delegationService.SetDelegation(&req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "委托设置成功", gin.H{"id": delegation.ID})
+}
+
+func (ctrl *DelegationController) DeleteDelegation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.delegationService.DeleteDelegation(uint(id), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "委托已取消", nil)
+}
+
+func (ctrl *DelegationController) GetMyDelegations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	delegations, err := ctrl.delegationService.GetMyDelegations(userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, delegations)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	exportService *service.ExportService
+}
+
+func NewExportController() *ExportController {
+	return &ExportController{
+		exportService: service.NewExportService(),
+	}
+}
+
+var exportContentTypes = map[string]string{
+	service.ExportCSV:   "text/csv; charset=utf-8",
+	service.ExportJSONL: "application/x-ndjson; charset=utf-8",
+	service.ExportXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+func (ctrl *ExportController) Export(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var query dto.ExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	vote, err := ctrl.exportService.PrepareExport(uint(id), &query, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("vote-%d-%s.%s", vote.ID, query.Data, query.Format)
+	c.Header("Content-Type", exportContentTypes[query.Format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// 响应头已经发出，出错时只能中断输出
+	if err := ctrl.exportService.WriteExport(vote, &query, c.Writer, c.Writer.Flush); err != nil {
+		log.Println("导出失败:", err)
+	}
+}
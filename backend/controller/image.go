@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"vote-system-backend/config"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead 上传请求中除图片以外的表单内容的余量
+const multipartOverhead = 64 << 10
+
+type ImageController struct {
+	imageService *service.ImageService
+}
+
+func NewImageController() *ImageController {
+	return &ImageController{
+		imageService: service.NewImageService(),
+	}
+}
+
+func (ctrl *ImageController) UploadImage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+	optionID, err := strconv.ParseUint(c.Param("optionId"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的选项ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	maxSize := config.Get().Storage.MaxSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.Error(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("图片不能超过%gMB", float64(maxSize)/(1<<20)))
+			return
+		}
+		utils.Error(c, http.StatusBadRequest, "请选择图片")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "文件读取失败")
+		return
+	}
+	defer f.Close()
+	// 多读一个字节，超出大小时由服务层拒绝
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "文件读取失败")
+		return
+	}
+
+	image, err := ctrl.imageService.UploadImage(uint(id), uint(optionID), data, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "上传成功", image)
+}
+
+func (ctrl *ImageController) DeleteImage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的图片ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.imageService.DeleteImage(uint(id), uint(imageID), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
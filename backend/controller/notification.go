@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/http"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationController() *NotificationController {
+	return &NotificationController{
+		notificationService: service.NewNotificationService(),
+	}
+}
+
+func (ctrl *NotificationController) GetNotifications(c *gin.Context) {
+	var query dto.NotificationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	notifications, err := ctrl.notificationService.GetNotifications(&query, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, notifications)
+}
+
+func (ctrl *NotificationController) MarkRead(c *gin.Context) {
+	// 请求体可以为空，此时全部标记为已读
+	var req dto.MarkReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.notificationService.MarkRead(&req, userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已标记为已读", nil)
+}
@@ -0,0 +1,348 @@
+package controller
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"vote-system-backend/dto"
+	"vote-system-backend/middleware"
+	"vote-system-backend/pubsub"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+type PresentationController struct {
+	presentationService *service.PresentationService
+	voteService         *service.VoteService
+	jwtMiddleware       *jwt.GinJWTMiddleware
+}
+
+func NewPresentationController(jwtMiddleware *jwt.GinJWTMiddleware) *PresentationController {
+	return &PresentationController{
+		presentationService: service.NewPresentationService(),
+		voteService:         service.NewVoteService(),
+		jwtMiddleware:       jwtMiddleware,
+	}
+}
+
+func (ctrl *PresentationController) CreatePresentation(c *gin.Context) {
+	var req dto.CreatePresentationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	presentation, err := ctrl.presentationService.CreatePresentation(&req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "演示会话创建成功", gin.H{"id": presentation.ID, "code": presentation.Code})
+}
+
+func (ctrl *PresentationController) GetMyPresentations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	presentations, err := ctrl.presentationService.GetMyPresentations(userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, presentations)
+}
+
+func (ctrl *PresentationController) GetPresentation(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	presentation, err := ctrl.presentationService.GetPresentation(uint(id), userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, presentation)
+}
+
+// control 处理演示者的控制操作
+func (ctrl *PresentationController) control(c *gin.Context, action func(id uint, userID uint) error, message string) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := action(uint(id), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, message, nil)
+}
+
+func (ctrl *PresentationController) Next(c *gin.Context) {
+	ctrl.control(c, ctrl.presentationService.Next, "已切换到下一个投票")
+}
+
+func (ctrl *PresentationController) Prev(c *gin.Context) {
+	ctrl.control(c, ctrl.presentationService.Prev, "已切换到上一个投票")
+}
+
+func (ctrl *PresentationController) Goto(c *gin.Context) {
+	var req dto.GotoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	ctrl.control(c, func(id uint, userID uint) error {
+		return ctrl.presentationService.Goto(id, req.Index, userID)
+	}, "已切换投票")
+}
+
+func (ctrl *PresentationController) OpenCurrent(c *gin.Context) {
+	ctrl.control(c, ctrl.presentationService.OpenCurrent, "投票已开启")
+}
+
+func (ctrl *PresentationController) CloseCurrent(c *gin.Context) {
+	ctrl.control(c, ctrl.presentationService.CloseCurrent, "投票已关闭")
+}
+
+func (ctrl *PresentationController) End(c *gin.Context) {
+	ctrl.control(c, ctrl.presentationService.End, "演示会话已结束")
+}
+
+// Join 访客通过会话码加入，返回访客令牌，可用于提交当前展示的投票
+func (ctrl *PresentationController) Join(c *gin.Context) {
+	var req dto.JoinPresentationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	presentation, user, err := ctrl.presentationService.JoinAsGuest(req.Code)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, _, err := ctrl.jwtMiddleware.TokenGenerator(user)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "加入成功", dto.JoinPresentationResponse{
+		PresentationID: presentation.ID,
+		Title:          presentation.Title,
+		User: dto.UserResponse{
+			ID:       user.ID,
+			Username: user.Username,
+		},
+		Token: token,
+	})
+}
+
+// viewerID 从 query 中的 token 解析观众身份，未登录的观众返回0
+func (ctrl *PresentationController) viewerID(c *gin.Context) uint {
+	tokenString := c.Query("token")
+	if tokenString == "" {
+		return 0
+	}
+
+	token, err := ctrl.jwtMiddleware.ParseTokenString(tokenString)
+	if err != nil {
+		return 0
+	}
+
+	claims := jwt.ExtractClaimsFromToken(token)
+	if id, ok := claims[middleware.IdentityKey].(float64); ok {
+		return uint(id)
+	}
+	return 0
+}
+
+// Audience 观众通过 WebSocket 接收当前展示的投票、实时计票和在线人数
+func (ctrl *PresentationController) Audience(c *gin.Context) {
+	presentation, err := ctrl.presentationService.FindByCode(c.Param("code"))
+	if err != nil {
+		utils.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	viewerID := ctrl.viewerID(c)
+
+	server := websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			ctrl.serveAudience(ws, presentation.ID, viewerID)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin 浏览器发起的连接只接受同一主机的页面，防止其他站点借用观众的令牌；
+// 非浏览器客户端不携带 Origin，直接放行
+func checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !strings.EqualFold(u.Hostname(), host) {
+		return errors.New("不允许的来源")
+	}
+	config.Origin = u
+	return nil
+}
+
+func (ctrl *PresentationController) serveAudience(ws *websocket.Conn, presentationID uint, viewerID uint) {
+	defer ws.Close()
+
+	broker := pubsub.GetBroker()
+	events, cancel := broker.Subscribe(pubsub.PresentationTopic(presentationID))
+	defer cancel()
+
+	connID, err := ctrl.presentationService.Join(presentationID)
+	if err != nil {
+		return
+	}
+	defer ctrl.presentationService.Leave(presentationID, connID)
+
+	// 观众发送的消息只用于检测断开
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var message string
+		for {
+			if err := websocket.Message.Receive(ws, &message); err != nil {
+				return
+			}
+		}
+	}()
+
+	var (
+		voteID      uint
+		visible     bool
+		voteEvents  <-chan pubsub.Event
+		cancelVotes = func() {}
+	)
+	defer func() { cancelVotes() }()
+
+	// 推送当前展示内容，并订阅当前投票的计票事件
+	refresh := func() error {
+		showing, err := ctrl.presentationService.NowShowing(presentationID, viewerID)
+		if err != nil {
+			return err
+		}
+
+		current := uint(0)
+		visible = false
+		if showing.Vote != nil {
+			current = showing.Vote.VoteID
+			visible = showing.Vote.ResultsVisible
+		}
+		if current != voteID {
+			cancelVotes()
+			voteID, voteEvents, cancelVotes = current, nil, func() {}
+			if current != 0 {
+				voteEvents, cancelVotes = broker.Subscribe(pubsub.VoteTopic(current))
+			}
+		}
+
+		return websocket.JSON.Send(ws, pubsub.Event{Type: pubsub.EventNowShowing, Data: showing})
+	}
+
+	if err := refresh(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-done:
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Type == pubsub.EventNowShowing {
+				err = refresh()
+			} else {
+				err = websocket.JSON.Send(ws, event)
+			}
+
+		case event, ok := <-voteEvents:
+			if !ok {
+				voteEvents = nil
+				continue
+			}
+			switch event.Type {
+			case pubsub.EventBallot:
+				ballot, decodeErr := ballotForViewer(ctrl.voteService, event, viewerID, &visible)
+				if decodeErr != nil {
+					continue
+				}
+				err = websocket.JSON.Send(ws, pubsub.Event{Type: event.Type, Data: ballot})
+			case pubsub.EventState:
+				err = refresh()
+			}
+
+		case <-heartbeat.C:
+			ctrl.presentationService.Touch(connID)
+			err = websocket.JSON.Send(ws, pubsub.Event{Type: "ping", Data: time.Now().Unix()})
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
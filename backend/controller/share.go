@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shareCacheControl 分享图片和元数据允许短时间缓存
+const shareCacheControl = "public, max-age=60"
+
+// sharePage 供聊天软件抓取的预览页面，浏览器打开后跳转到投票详情页
+var sharePage = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:image" content="{{.ImageURL}}">
+<meta property="og:image:width" content="{{.ImageWidth}}">
+<meta property="og:image:height" content="{{.ImageHeight}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta http-equiv="refresh" content="0; url={{.URL}}">
+</head>
+<body><a href="{{.URL}}">{{.Title}}</a></body>
+</html>
+`))
+
+type ShareController struct {
+	shareService *service.ShareService
+}
+
+func NewShareController() *ShareController {
+	return &ShareController{
+		shareService: service.NewShareService(),
+	}
+}
+
+func shareID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (ctrl *ShareController) ChartSVG(c *gin.Context) {
+	id, ok := shareID(c)
+	if !ok {
+		return
+	}
+
+	svg, err := ctrl.shareService.ChartSVG(id)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", shareCacheControl)
+	c.Data(http.StatusOK, "image/svg+xml", svg)
+}
+
+func (ctrl *ShareController) ChartPNG(c *gin.Context) {
+	id, ok := shareID(c)
+	if !ok {
+		return
+	}
+
+	img, err := ctrl.shareService.ChartPNG(id)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", shareCacheControl)
+	c.Data(http.StatusOK, "image/png", img)
+}
+
+func (ctrl *ShareController) OGImage(c *gin.Context) {
+	id, ok := shareID(c)
+	if !ok {
+		return
+	}
+
+	img, err := ctrl.shareService.OGImage(id)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", shareCacheControl)
+	c.Data(http.StatusOK, "image/png", img)
+}
+
+func (ctrl *ShareController) GetMeta(c *gin.Context) {
+	id, ok := shareID(c)
+	if !ok {
+		return
+	}
+
+	meta, err := ctrl.shareService.GetMeta(id)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", shareCacheControl)
+	utils.Success(c, meta)
+}
+
+func (ctrl *ShareController) SharePage(c *gin.Context) {
+	id, ok := shareID(c)
+	if !ok {
+		return
+	}
+
+	meta, err := ctrl.shareService.GetMeta(id)
+	if err != nil {
+		utils.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", shareCacheControl)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	sharePage.Execute(c.Writer, meta)
+}
@@ -125,15 +125,21 @@ func (ctrl *VoteController) Vote(c *gin.Context) {
 }
 
 func (ctrl *VoteController) GetUserVotes(c *gin.Context) {
+	var query dto.VoteListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
 	userID, exists := c.Get("user_id")
 	if !exists {
 		utils.Error(c, http.StatusUnauthorized, "未授权")
 		return
 	}
 
-	votes, err := ctrl.voteService.GetUserVotes(userID.(uint))
+	votes, err := ctrl.voteService.GetUserVotes(userID.(uint), &query)
 	if err != nil {
-		utils.Error(c, http.StatusInternalServerError, err.Error())
+		utils.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -141,11 +147,252 @@ func (ctrl *VoteController) GetUserVotes(c *gin.Context) {
 }
 
 func (ctrl *VoteController) GetAllVotes(c *gin.Context) {
-	votes, err := ctrl.voteService.GetAllVotes()
+	var query dto.VoteListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	votes, err := ctrl.voteService.GetAllVotes(userID, &query)
 	if err != nil {
-		utils.Error(c, http.StatusInternalServerError, err.Error())
+		utils.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	utils.Success(c, votes)
 }
+
+func (ctrl *VoteController) GetHistory(c *gin.Context) {
+	var query dto.PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	history, err := ctrl.voteService.GetHistory(&query, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, history)
+}
+
+func (ctrl *VoteController) GetInbox(c *gin.Context) {
+	var query dto.PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	votes, err := ctrl.voteService.GetInbox(&query, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, votes)
+}
+
+func (ctrl *VoteController) Discover(c *gin.Context) {
+	var query dto.DiscoverQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	result, err := ctrl.voteService.Discover(&query, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+func (ctrl *VoteController) SearchVotes(c *gin.Context) {
+	var query dto.SearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	result, err := ctrl.voteService.SearchVotes(&query, userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+func (ctrl *VoteController) GetTagCloud(c *gin.Context) {
+	var query dto.TagCloudQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	tags, err := ctrl.voteService.GetTagCloud(query.Limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, tags)
+}
+
+func (ctrl *VoteController) CloseVote(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.voteService.CloseVote(uint(id), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "投票已关闭", nil)
+}
+
+func (ctrl *VoteController) GetVoters(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	voters, err := ctrl.voteService.GetVoters(uint(id), userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, voters)
+}
+
+func (ctrl *VoteController) GetTally(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	tally, err := ctrl.voteService.GetTally(uint(id), userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, tally)
+}
+
+func (ctrl *VoteController) GetResults(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	results, err := ctrl.voteService.GetResults(uint(id), userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, results)
+}
+
+func (ctrl *VoteController) GetTimeline(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var query dto.TimelineQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	timeline, err := ctrl.voteService.GetTimeline(uint(id), &query, userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, timeline)
+}
+
+func (ctrl *VoteController) GetCrosstab(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	var query dto.CrosstabQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	crosstab, err := ctrl.voteService.GetCrosstab(uint(id), query.Attribute, userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, crosstab)
+}
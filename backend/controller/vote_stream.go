@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"vote-system-backend/dto"
+	"vote-system-backend/pubsub"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSE 心跳间隔，防止代理断开空闲连接
+const streamHeartbeat = 25 * time.Second
+
+// StreamVote 通过 Server-Sent Events 推送计票增量、参与人数和状态变化
+func (ctrl *VoteController) StreamVote(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+	voteID := uint(id)
+	userID := c.GetUint("user_id")
+
+	// 先订阅再取快照，避免丢失两者之间的事件
+	events, cancel := pubsub.GetBroker().Subscribe(pubsub.VoteTopic(voteID))
+	defer cancel()
+
+	snapshot, err := ctrl.voteService.GetLiveSnapshot(voteID, userID)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent(pubsub.EventSnapshot, snapshot)
+	c.Writer.Flush()
+
+	visible := snapshot.ResultsVisible
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	// 截止时间到达时推送关闭状态，截止时间修改或重新开启后重新计时
+	var timer *time.Timer
+	var deadline <-chan time.Time
+	armDeadline := func(closed bool, at int64) {
+		if timer != nil {
+			timer.Stop()
+		}
+		timer, deadline = nil, nil
+		if !closed && at > 0 {
+			timer = time.NewTimer(time.Until(time.Unix(at, 0)))
+			deadline = timer.C
+		}
+	}
+	armDeadline(snapshot.Closed, snapshot.Deadline)
+	defer armDeadline(true, 0)
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+
+			switch event.Type {
+			case pubsub.EventBallot:
+				ballot, err := ballotForViewer(ctrl.voteService, event, userID, &visible)
+				if err != nil {
+					return true
+				}
+				c.SSEvent(event.Type, ballot)
+
+			case pubsub.EventState:
+				var state dto.StateEvent
+				if err := pubsub.Decode(event, &state); err != nil {
+					return true
+				}
+				c.SSEvent(event.Type, state)
+				if state.State == service.StateDeleted {
+					return false
+				}
+				// 重新开启、修改可见性、启用差分隐私或调整权限后，可见性可能变化
+				visible = ctrl.voteService.CanViewResults(voteID, userID)
+				// 已经过去的截止时间由截止事件通知，不再重复推送
+				if closed, at, err := ctrl.voteService.GetDeadline(voteID); err == nil {
+					armDeadline(closed || at <= time.Now().Unix(), at)
+				}
+
+			default:
+				c.SSEvent(event.Type, event.Data)
+			}
+
+		case <-deadline:
+			deadline = nil
+			c.SSEvent(pubsub.EventState, dto.StateEvent{VoteID: voteID, State: service.StateClosed})
+			visible = ctrl.voteService.CanViewResults(voteID, userID)
+
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+		}
+		return true
+	})
+}
+
+// ballotForViewer 按查看者的可见性过滤选票事件，不可见时只通知有新的选票，不包含票数和参与人数。
+// 事件可能经过跨进程的消息队列，因此解码后按字段过滤而不是断言类型；
+// 查看者本人投票后重新判断可见性（after_vote）
+func ballotForViewer(voteService *service.VoteService, event pubsub.Event, userID uint, visible *bool) (*dto.BallotEvent, error) {
+	var ballot dto.BallotEvent
+	if err := pubsub.Decode(event, &ballot); err != nil {
+		return nil, err
+	}
+	if userID != 0 && ballot.VoterID == userID {
+		*visible = voteService.CanViewResults(ballot.VoteID, userID)
+	}
+
+	filtered := &dto.BallotEvent{VoteID: ballot.VoteID}
+	if *visible {
+		filtered.Turnout = ballot.Turnout
+		filtered.Deltas = ballot.Deltas
+	}
+	return filtered, nil
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"vote-system-backend/dto"
+	"vote-system-backend/service"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WeightController struct {
+	weightService *service.WeightService
+}
+
+func NewWeightController() *WeightController {
+	return &WeightController{
+		weightService: service.NewWeightService(),
+	}
+}
+
+func (ctrl *WeightController) CreateWeightSet(c *gin.Context) {
+	var req dto.CreateWeightSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, "参数错误")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	set, err := ctrl.weightService.CreateWeightSet(&req, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "权重名单创建成功", gin.H{"id": set.ID})
+}
+
+func (ctrl *WeightController) GetMyWeightSets(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	sets, err := ctrl.weightService.GetMyWeightSets(userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, sets)
+}
+
+func (ctrl *WeightController) GetWeightSet(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	set, err := ctrl.weightService.GetWeightSet(uint(id), userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.Success(c, set)
+}
+
+// ImportWeights 支持 multipart 的 file 字段或直接以 text/csv 作为请求体
+func (ctrl *WeightController) ImportWeights(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var reader io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "文件读取失败")
+			return
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	count, err := ctrl.weightService.ImportCSV(uint(id), reader, userID.(uint))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "导入成功", gin.H{"count": count})
+}
+
+func (ctrl *WeightController) DeleteWeightSet(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "无效的ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := ctrl.weightService.DeleteWeightSet(uint(id), userID.(uint)); err != nil {
+		utils.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "删除成功", nil)
+}
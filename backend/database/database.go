@@ -8,6 +8,7 @@ import (
 
 	"vote-system-backend/config"
 	"vote-system-backend/model"
+	"vote-system-backend/utils"
 )
 
 var DB *gorm.DB
@@ -21,11 +22,66 @@ func Init(cfg *config.Config) {
 		panic("failed to connect database")
 	}
 
-	err = DB.AutoMigrate(&model.User{}, &model.Vote{}, &model.VoteOption{}, &model.UserVote{})
+	// voter_count 为新增的列时，迁移后按已有选票回填
+	backfillVoterCount := DB.Migrator().HasTable(&model.Vote{}) && !DB.Migrator().HasColumn(&model.Vote{}, "VoterCount")
+	// deadline_notified 为新增的列时，已经截止的投票视为已发送过事件，避免升级后补发
+	backfillDeadlineNotified := DB.Migrator().HasTable(&model.Vote{}) && !DB.Migrator().HasColumn(&model.Vote{}, "DeadlineNotified")
+	// description_html 为新增的列时，迁移后渲染已有的描述
+	backfillDescriptionHTML := DB.Migrator().HasTable(&model.Vote{}) && !DB.Migrator().HasColumn(&model.Vote{}, "DescriptionHTML")
+
+	err = DB.AutoMigrate(
+		&model.User{}, &model.Vote{}, &model.VoteOption{}, &model.UserVote{},
+		&model.VoteTag{}, &model.VoteCollaborator{}, &model.VoteTransfer{},
+		&model.Delegation{}, &model.WeightSet{}, &model.WeightEntry{},
+		&model.ProxyGrant{}, &model.Presentation{}, &model.PresentationItem{}, &model.PresentationGuest{}, &model.PresentationViewer{},
+		&model.WebhookSubscription{}, &model.OutboxEvent{}, &model.WebhookDelivery{},
+		&model.ResultSnapshot{},
+		&model.AttributeDefinition{}, &model.UserAttribute{}, &model.BallotAttribute{},
+		&model.PrivacyBudget{}, &model.PrivacyRelease{},
+		&model.Audit{}, &model.AuditSample{},
+		&model.Category{},
+		&model.Follow{}, &model.Bookmark{},
+		&model.Comment{}, &model.Notification{},
+		&model.OptionSuggestion{}, &model.WriteInAnswer{},
+		&model.OptionImage{},
+		&model.Survey{}, &model.SurveyQuestion{}, &model.SurveyResponse{}, &model.SurveyAnswer{},
+	)
 	if err != nil {
 		log.Fatal("数据库迁移失败:", err)
 	}
 
+	if backfillVoterCount {
+		err = DB.Exec("UPDATE votes SET voter_count = (SELECT COUNT(DISTINCT user_id) FROM user_votes WHERE user_votes.vote_id = votes.id)").Error
+		if err != nil {
+			log.Fatal("回填投票人数失败:", err)
+		}
+	}
+
+	if backfillDeadlineNotified {
+		err = DB.Exec("UPDATE votes SET deadline_notified = deadline WHERE deadline > 0 AND deadline <= UNIX_TIMESTAMP()").Error
+		if err != nil {
+			log.Fatal("回填截止事件失败:", err)
+		}
+	}
+
+	if backfillDescriptionHTML {
+		var votes []model.Vote
+		err = DB.Unscoped().Select("id", "description").Where("description <> ''").
+			FindInBatches(&votes, 500, func(tx *gorm.DB, batch int) error {
+				for _, vote := range votes {
+					err := DB.Model(&model.Vote{}).Unscoped().Where("id = ?", vote.ID).
+						UpdateColumn("description_html", utils.RenderMarkdown(vote.Description)).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		if err != nil {
+			log.Fatal("回填投票描述失败:", err)
+		}
+	}
+
 	log.Println("数据库连接成功")
 }
 
@@ -0,0 +1,46 @@
+package dto
+
+type AttributeDefinitionRequest struct {
+	Name    string   `json:"name" binding:"required,max=32"`
+	Label   string   `json:"label" binding:"max=64"`
+	Choices []string `json:"choices" binding:"max=50,dive,required,max=64"`
+}
+
+type AttributeDefinitionResponse struct {
+	Name    string   `json:"name"`
+	Label   string   `json:"label"`
+	Choices []string `json:"choices"`
+}
+
+// SetAttributesRequest 属性名到属性值的映射，值为空表示清除该属性
+type SetAttributesRequest struct {
+	Attributes map[string]string `json:"attributes" binding:"required"`
+}
+
+type CrosstabQuery struct {
+	Attribute string `form:"attribute" binding:"required"`
+}
+
+type CrosstabOption struct {
+	OptionID uint   `json:"option_id"`
+	Content  string `json:"content"`
+}
+
+// CrosstabGroup 一个属性值的交叉统计，被抑制的单元格为 null
+type CrosstabGroup struct {
+	Value  string `json:"value"`  // 未填写该属性的投票人为空字符串
+	Other  bool   `json:"other"`  // 人数过少的分组合并而成
+	Merged int    `json:"merged"` // 合并的分组数量
+	Voters int    `json:"voters"`
+	Counts []*int `json:"counts"`
+}
+
+type CrosstabResponse struct {
+	VoteID      uint             `json:"vote_id"`
+	Attribute   string           `json:"attribute"`
+	Label       string           `json:"label"`
+	MinCellSize int              `json:"min_cell_size"`
+	Options     []CrosstabOption `json:"options"`
+	Groups      []CrosstabGroup  `json:"groups"`
+	Suppressed  int              `json:"suppressed"` // 被抑制的单元格数量
+}
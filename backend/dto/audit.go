@@ -0,0 +1,71 @@
+package dto
+
+import "time"
+
+type CreateAuditRequest struct {
+	Seed       string  `json:"seed" binding:"required,min=6,max=128"` // 公开产生的种子，如当众掷骰子的结果
+	RiskLimit  float64 `json:"risk_limit" binding:"gte=0,lt=0.5"`     // 默认 0.05
+	MaxSamples int     `json:"max_samples" binding:"gte=0"`           // 默认为选票数
+}
+
+type DrawSamplesRequest struct {
+	Count int `json:"count" binding:"gte=0,lte=1000"` // 为0时按预计所需抽样数抽取
+}
+
+type RecordSampleRequest struct {
+	OptionIDs []uint `json:"option_ids"`
+	Invalid   bool   `json:"invalid"` // 选票无效或无法找到时为 true
+}
+
+type AuditReportedOption struct {
+	OptionID uint   `json:"option_id"`
+	Content  string `json:"content"`
+	Count    int    `json:"count"`
+}
+
+// AuditTest 获胜选项与一个落选选项之间的序贯检验
+type AuditTest struct {
+	LoserID     uint    `json:"loser_id"`
+	Content     string  `json:"content"`
+	Share       float64 `json:"share"`        // 公布结果中获胜选项在两者中的占比
+	Statistic   float64 `json:"statistic"`    // 似然比，达到 1/风险限制 时该对比确认
+	RiskMeasure float64 `json:"risk_measure"` // 该对比的风险度量
+	Confirmed   bool    `json:"confirmed"`
+}
+
+type AuditSampleResponse struct {
+	Sequence   int        `json:"sequence"`
+	BallotID   uint       `json:"ballot_id"`
+	UserID     uint       `json:"user_id"`
+	Username   string     `json:"username"`
+	Recorded   bool       `json:"recorded"`
+	Invalid    bool       `json:"invalid"`
+	OptionIDs  []uint     `json:"option_ids"`
+	Matches    *bool      `json:"matches"`
+	RecordedAt *time.Time `json:"recorded_at"`
+}
+
+type AuditResponse struct {
+	ID              uint                  `json:"id"`
+	VoteID          uint                  `json:"vote_id"`
+	VoteTitle       string                `json:"vote_title"`
+	AuditorID       uint                  `json:"auditor_id"`
+	Seed            string                `json:"seed"`
+	RiskLimit       float64               `json:"risk_limit"`
+	MaxSamples      int                   `json:"max_samples"`
+	BallotCount     int                   `json:"ballot_count"`
+	ManifestHash    string                `json:"manifest_hash"`
+	Reported        []AuditReportedOption `json:"reported"`
+	ReportedWinner  uint                  `json:"reported_winner"`
+	Status          string                `json:"status"`
+	RiskMeasure     float64               `json:"risk_measure"`
+	Message         string                `json:"message"`
+	Tests           []AuditTest           `json:"tests"`
+	SamplesDrawn    int                   `json:"samples_drawn"`
+	SamplesRecorded int                   `json:"samples_recorded"`
+	Discrepancies   int                   `json:"discrepancies"`
+	EstimatedTotal  int                   `json:"estimated_total"` // 公布结果正确时预计所需的抽样数
+	Samples         []AuditSampleResponse `json:"samples,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
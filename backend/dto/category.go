@@ -0,0 +1,24 @@
+package dto
+
+type CategoryRequest struct {
+	Name        string `json:"name" binding:"required,max=32"`
+	Description string `json:"description" binding:"max=255"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+type CategoryResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SortOrder   int    `json:"sort_order"`
+	VoteCount   int64  `json:"vote_count"`
+}
+
+type TagCloudQuery struct {
+	Limit int `form:"limit" binding:"gte=0,lte=200"`
+}
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
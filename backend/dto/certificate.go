@@ -0,0 +1,39 @@
+package dto
+
+type CertificateOption struct {
+	OptionID uint    `json:"option_id"`
+	Content  string  `json:"content"`
+	Count    int     `json:"count"`
+	Weight   float64 `json:"weight"`
+	Percent  float64 `json:"percent"`
+	Rank     int     `json:"rank"`
+}
+
+// Certificate 结果证书中经过签名的内容
+type Certificate struct {
+	VoteID       uint                `json:"vote_id"`
+	Title        string              `json:"title"`
+	Method       string              `json:"method"` // plurality 或 approval
+	Weighted     bool                `json:"weighted"`
+	Options      []CertificateOption `json:"options"`
+	Turnout      ResultTurnout       `json:"turnout"`
+	Winners      []uint              `json:"winners"`
+	Tie          bool                `json:"tie"`
+	CreatedAt    int64               `json:"created_at"`
+	Deadline     int64               `json:"deadline"`
+	FinalizedAt  int64               `json:"finalized_at"`
+	IssuedAt     int64               `json:"issued_at"`
+	BallotCount  int                 `json:"ballot_count"`  // 选票记录条数
+	BallotDigest string              `json:"ballot_digest"` // 全部选票记录的 SHA-256 摘要
+	PublicKey    string              `json:"public_key"`
+}
+
+type VerifyCertificateResponse struct {
+	Valid       bool         `json:"valid"`
+	Certificate *Certificate `json:"certificate,omitempty"`
+}
+
+type PublicKeyResponse struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // Base64
+}
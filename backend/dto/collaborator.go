@@ -0,0 +1,45 @@
+package dto
+
+import "time"
+
+type CollaboratorRequest struct {
+	Username      string `json:"username" binding:"required"`
+	CanEdit       bool   `json:"can_edit"`
+	CanClose      bool   `json:"can_close"`
+	CanViewVoters bool   `json:"can_view_voters"`
+	CanExport     bool   `json:"can_export"`
+}
+
+type CollaboratorResponse struct {
+	UserID        uint   `json:"user_id"`
+	Username      string `json:"username"`
+	CanEdit       bool   `json:"can_edit"`
+	CanClose      bool   `json:"can_close"`
+	CanViewVoters bool   `json:"can_view_voters"`
+	CanExport     bool   `json:"can_export"`
+}
+
+type TransferRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type TransferResponse struct {
+	ID           uint      `json:"id"`
+	VoteID       uint      `json:"vote_id"`
+	VoteTitle    string    `json:"vote_title"`
+	FromUserID   uint      `json:"from_user_id"`
+	FromUsername string    `json:"from_username"`
+	ToUserID     uint      `json:"to_user_id"`
+	ToUsername   string    `json:"to_username"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type VoterResponse struct {
+	UserID        uint      `json:"user_id"`
+	Username      string    `json:"username"`
+	OptionIDs     []uint    `json:"option_ids"`
+	VotedAt       time.Time `json:"voted_at"`
+	ProxyID       *uint     `json:"proxy_id"`
+	ProxyUsername string    `json:"proxy_username,omitempty"`
+}
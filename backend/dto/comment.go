@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+type CreateCommentRequest struct {
+	Content    string `json:"content" binding:"required,max=2000"`
+	ParentID   *uint  `json:"parent_id"`   // 回复的评论
+	RevealVote bool   `json:"reveal_vote"` // 在评论旁显示自己的投票
+}
+
+type UpdateCommentRequest struct {
+	Content    string `json:"content" binding:"required,max=2000"`
+	RevealVote bool   `json:"reveal_vote"`
+}
+
+// CommentBadge 评论作者公开的投票
+type CommentBadge struct {
+	OptionIDs []uint   `json:"option_ids"`
+	Options   []string `json:"options"`
+}
+
+type CommentResponse struct {
+	ID        uint              `json:"id"`
+	VoteID    uint              `json:"vote_id"`
+	ParentID  *uint             `json:"parent_id"`
+	UserID    uint              `json:"user_id"`
+	Username  string            `json:"username"`
+	Content   string            `json:"content"`
+	Badge     *CommentBadge     `json:"badge"` // 作者未公开、未投票或当前用户不能查看结果时为空
+	Pinned    bool              `json:"pinned"`
+	Deleted   bool              `json:"deleted"`
+	Removed   bool              `json:"removed"`
+	EditedAt  *time.Time        `json:"edited_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	Replies   []CommentResponse `json:"replies"`
+}
+
+type CommentList struct {
+	Pinned     []CommentResponse `json:"pinned"` // 置顶的讨论串，只在第一页返回
+	Items      []CommentResponse `json:"items"`
+	Total      int64             `json:"total"` // 未置顶的讨论串数
+	NextCursor string            `json:"next_cursor"`
+	HasMore    bool              `json:"has_more"`
+}
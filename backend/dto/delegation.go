@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type DelegationRequest struct {
+	Username string `json:"username" binding:"required"`
+	Tag      string `json:"tag" binding:"max=32"`
+}
+
+type DelegationResponse struct {
+	ID                uint      `json:"id"`
+	DelegatorID       uint      `json:"delegator_id"`
+	DelegatorUsername string    `json:"delegator_username"`
+	DelegateID        uint      `json:"delegate_id"`
+	DelegateUsername  string    `json:"delegate_username"`
+	Tag               string    `json:"tag"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type MyDelegationsResponse struct {
+	Outgoing []DelegationResponse `json:"outgoing"`
+	Incoming []DelegationResponse `json:"incoming"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type ExportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl xlsx"` // 默认 csv
+	Data   string `form:"data" binding:"omitempty,oneof=results ballots"`  // 默认 results
+}
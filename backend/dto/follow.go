@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type FollowUser struct {
+	UserID     uint      `json:"user_id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type FollowList struct {
+	Items      []FollowUser `json:"items"`
+	Total      int64        `json:"total"`
+	NextCursor string       `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+type NotificationQuery struct {
+	Unread bool   `form:"unread"` // 只返回未读通知
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"gte=0,lte=100"`
+}
+
+type MarkReadRequest struct {
+	IDs []uint `json:"ids"` // 为空时全部标记为已读
+}
+
+type NotificationResponse struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	ActorID   uint      `json:"actor_id"`
+	ActorName string    `json:"actor_name"`
+	VoteID    uint      `json:"vote_id"`
+	VoteTitle string    `json:"vote_title"`
+	CommentID uint      `json:"comment_id"`
+	Excerpt   string    `json:"excerpt"` // 评论内容摘要
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationList struct {
+	Items      []NotificationResponse `json:"items"`
+	Unread     int64                  `json:"unread"` // 未读通知总数
+	NextCursor string                 `json:"next_cursor"`
+	HasMore    bool                   `json:"has_more"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+type CreatePresentationRequest struct {
+	Title   string `json:"title" binding:"required"`
+	VoteIDs []uint `json:"vote_ids" binding:"required,min=1"`
+}
+
+type GotoRequest struct {
+	Index int `json:"index" binding:"min=0"`
+}
+
+type JoinPresentationRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type JoinPresentationResponse struct {
+	PresentationID uint         `json:"presentation_id"`
+	Title          string       `json:"title"`
+	User           UserResponse `json:"user"`
+	Token          string       `json:"token"`
+}
+
+// NowShowing 当前展示的投票，Vote 为空表示尚未开始或已结束
+type NowShowing struct {
+	PresentationID uint          `json:"presentation_id"`
+	Title          string        `json:"title"`
+	Active         bool          `json:"active"`
+	Index          int           `json:"index"`
+	Total          int           `json:"total"`
+	Vote           *LiveSnapshot `json:"vote"`
+}
+
+type ParticipantsEvent struct {
+	PresentationID uint `json:"presentation_id"`
+	Count          int  `json:"count"`
+}
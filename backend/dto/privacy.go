@@ -0,0 +1,53 @@
+package dto
+
+type PrivacySettingsRequest struct {
+	Mechanism string  `json:"mechanism" binding:"required,oneof=laplace gaussian"`
+	Epsilon   float64 `json:"epsilon" binding:"gt=0,lte=10"`
+	Delta     float64 `json:"delta" binding:"gte=0,lt=0.01"` // 高斯机制使用，默认 1e-6
+}
+
+type PrivacyBudgetResponse struct {
+	VoteID    uint    `json:"vote_id"`
+	Enabled   bool    `json:"enabled"`
+	Mechanism string  `json:"mechanism"`
+	Epsilon   float64 `json:"epsilon"`
+	Delta     float64 `json:"delta"`
+	Spent     float64 `json:"spent"`
+	Remaining float64 `json:"remaining"`
+	Releases  int64   `json:"releases"`
+}
+
+type NoisyQuery struct {
+	Epsilon   float64 `form:"epsilon" binding:"gte=0"` // 为0时返回最近一次发布的结果
+	Attribute string  `form:"attribute"`               // 指定时发布按属性的交叉统计
+}
+
+type NoisyOption struct {
+	OptionID uint    `json:"option_id"`
+	Content  string  `json:"content"`
+	Count    int     `json:"count"`
+	Percent  float64 `json:"percent"`
+}
+
+type NoisyGroup struct {
+	Value  string `json:"value"`
+	Voters int    `json:"voters"`
+	Counts []int  `json:"counts"`
+}
+
+type NoisyResultsResponse struct {
+	VoteID      uint          `json:"vote_id"`
+	ReleaseID   uint          `json:"release_id"`
+	Mechanism   string        `json:"mechanism"`
+	Epsilon     float64       `json:"epsilon"`
+	Delta       float64       `json:"delta,omitempty"`
+	Sensitivity float64       `json:"sensitivity"`
+	NoiseScale  float64       `json:"noise_scale"` // 拉普拉斯机制为 b，高斯机制为标准差
+	Voters      int           `json:"voters"`
+	Options     []NoisyOption `json:"options"`
+	Attribute   string        `json:"attribute,omitempty"`
+	Groups      []NoisyGroup  `json:"groups,omitempty"`
+	ReleasedAt  int64         `json:"released_at"`
+	Cached      bool          `json:"cached"` // 是否为已发布的结果（未消耗预算）
+	Remaining   float64       `json:"remaining"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type ProxyGrantRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ProxyGrantResponse struct {
+	ID                uint       `json:"id"`
+	VoteID            uint       `json:"vote_id"`
+	PrincipalID       uint       `json:"principal_id"`
+	PrincipalUsername string     `json:"principal_username"`
+	ProxyID           uint       `json:"proxy_id"`
+	ProxyUsername     string     `json:"proxy_username"`
+	Status            string     `json:"status"`
+	Used              bool       `json:"used"`
+	Signature         string     `json:"signature"`
+	AcceptedAt        *time.Time `json:"accepted_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+type MyProxiesResponse struct {
+	Granted  []ProxyGrantResponse `json:"granted"`
+	Received []ProxyGrantResponse `json:"received"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+type ShareLeadingOption struct {
+	OptionID uint    `json:"option_id"`
+	Content  string  `json:"content"`
+	Percent  float64 `json:"percent"`
+}
+
+// ShareMeta 链接预览使用的投票元数据
+type ShareMeta struct {
+	VoteID         uint                `json:"vote_id"`
+	Title          string              `json:"title"`
+	Description    string              `json:"description"`
+	URL            string              `json:"url"`
+	ImageURL       string              `json:"image_url"`
+	ImageWidth     int                 `json:"image_width"`
+	ImageHeight    int                 `json:"image_height"`
+	ChartSVGURL    string              `json:"chart_svg_url"`
+	ChartPNGURL    string              `json:"chart_png_url"`
+	Ended          bool                `json:"ended"`
+	ResultsVisible bool                `json:"results_visible"`
+	Voters         int                 `json:"voters"`
+	LeadingOption  *ShareLeadingOption `json:"leading_option"` // 结果不可见、无人投票或平票时为空
+	Tie            bool                `json:"tie"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+type SuggestOptionRequest struct {
+	Content string `json:"content" binding:"required,max=255"`
+}
+
+type SuggestionQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+}
+
+type SuggestionResponse struct {
+	ID        uint      `json:"id"`
+	VoteID    uint      `json:"vote_id"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	Content   string    `json:"content"`
+	Status    string    `json:"status"`
+	OptionID  *uint     `json:"option_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WriteInGroup 归一化后相同的一组填写内容
+type WriteInGroup struct {
+	Normalized string  `json:"normalized"`
+	Content    string  `json:"content"` // 最常见的写法
+	Count      int     `json:"count"`
+	Weight     float64 `json:"weight"`
+	Promotable bool    `json:"promotable"` // 达到提升所需的票数
+}
+
+type WriteInResponse struct {
+	VoteID    uint           `json:"vote_id"`
+	Threshold int            `json:"threshold"`
+	Groups    []WriteInGroup `json:"groups"`
+}
+
+type PromoteWriteInRequest struct {
+	Normalized string `json:"normalized" binding:"required"`
+	Content    string `json:"content" binding:"max=255"` // 新选项的内容，为空时使用最常见的写法
+}
@@ -0,0 +1,90 @@
+package dto
+
+type SurveyQuestionRequest struct {
+	Type        string   `json:"type" binding:"required,oneof=single multi text likert nps number"`
+	Title       string   `json:"title" binding:"required,max=255"`
+	Description string   `json:"description" binding:"max=2000"`
+	Required    bool     `json:"required"`
+	Options     []string `json:"options" binding:"max=50,dive,required,max=255"` // 单选和多选题的选项
+	ScaleMin    int      `json:"scale_min"`                                      // 李克特量表的最小值，默认1
+	ScaleMax    int      `json:"scale_max"`                                      // 李克特量表的最大值，默认5
+	MinLabel    string   `json:"min_label" binding:"max=32"`
+	MaxLabel    string   `json:"max_label" binding:"max=32"`
+	NumberMin   *float64 `json:"number_min"`
+	NumberMax   *float64 `json:"number_max"`
+}
+
+type CreateSurveyRequest struct {
+	Title            string                  `json:"title" binding:"required,max=255"`
+	Description      string                  `json:"description" binding:"max=5000"`
+	Deadline         int64                   `json:"deadline"`
+	ResultVisibility string                  `json:"result_visibility" binding:"omitempty,oneof=always after_vote after_close"`
+	Questions        []SurveyQuestionRequest `json:"questions" binding:"required,min=1,max=100,dive"`
+}
+
+// UpdateSurveyRequest 修改问卷，Questions 为空时不修改问题
+type UpdateSurveyRequest struct {
+	Title            string                  `json:"title" binding:"required,max=255"`
+	Description      string                  `json:"description" binding:"max=5000"`
+	Deadline         int64                   `json:"deadline"`
+	ResultVisibility string                  `json:"result_visibility" binding:"omitempty,oneof=always after_vote after_close"`
+	Questions        []SurveyQuestionRequest `json:"questions" binding:"max=100,dive"`
+}
+
+// SurveyAnswerRequest 一个问题的回答，按问题类型填写其中一项
+type SurveyAnswerRequest struct {
+	QuestionID uint     `json:"question_id" binding:"required"`
+	OptionIDs  []uint   `json:"option_ids"` // 单选和多选题
+	Text       string   `json:"text" binding:"max=2000"`
+	Number     *float64 `json:"number"` // 量表、NPS 和数字题
+}
+
+type SubmitSurveyRequest struct {
+	Answers []SurveyAnswerRequest `json:"answers" binding:"max=100,dive"`
+}
+
+type SurveyOptionResult struct {
+	OptionID uint    `json:"option_id"`
+	Content  string  `json:"content"`
+	Count    int     `json:"count"`
+	Percent  float64 `json:"percent"` // 占回答该题人数的百分比
+}
+
+// SurveyBucket 量表和 NPS 题中一个分值的人数
+type SurveyBucket struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+// SurveyQuestionResult 一个问题的汇总结果，只有与问题类型对应的字段有值
+type SurveyQuestionResult struct {
+	QuestionID uint   `json:"question_id"`
+	Position   int    `json:"position"`
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Required   bool   `json:"required"`
+	Answered   int    `json:"answered"` // 回答了该题的人数
+
+	Options []SurveyOptionResult `json:"options,omitempty"`
+
+	Distribution []SurveyBucket `json:"distribution,omitempty"`
+	Average      *float64       `json:"average,omitempty"`
+	Median       *float64       `json:"median,omitempty"`
+	Min          *float64       `json:"min,omitempty"`
+	Max          *float64       `json:"max,omitempty"`
+
+	// NPS：推荐者（9-10分）比例减去贬损者（0-6分）比例，范围 -100 到 100
+	NPS        *float64 `json:"nps,omitempty"`
+	Promoters  int      `json:"promoters,omitempty"`
+	Passives   int      `json:"passives,omitempty"`
+	Detractors int      `json:"detractors,omitempty"`
+
+	Texts []string `json:"texts,omitempty"` // 最近的文本回答
+}
+
+type SurveyResults struct {
+	SurveyID  uint                   `json:"survey_id"`
+	Title     string                 `json:"title"`
+	Responses int                    `json:"responses"`
+	Questions []SurveyQuestionResult `json:"questions"`
+}
@@ -1,21 +1,237 @@
 package dto
 
+import "time"
+
 type CreateVoteRequest struct {
-	Title    string   `json:"title" binding:"required"`
-	Options  []string `json:"options" binding:"required,min=2"`
-	Multi    bool     `json:"multi"`
-	Deadline int64    `json:"deadline"`
+	Title              string   `json:"title" binding:"required"`
+	Description        string   `json:"description" binding:"max=5000"`
+	Options            []string `json:"options" binding:"required,min=2"`
+	OptionDescriptions []string `json:"option_descriptions" binding:"dive,max=2000"` // 与 Options 按位置对应，可以比 Options 短
+	Multi              bool     `json:"multi"`
+	Deadline           int64    `json:"deadline"`
+	Tags               []string `json:"tags" binding:"max=10,dive,max=32"`
+	CategoryID         *uint    `json:"category_id"`
+	WeightSetID        *uint    `json:"weight_set_id"`
+	ResultVisibility   string   `json:"result_visibility" binding:"omitempty,oneof=always after_vote after_close"`
+	Suggestions        string   `json:"suggestions" binding:"omitempty,oneof=off immediate approval"`
+	WriteIn            bool     `json:"write_in"`
+	WriteInThreshold   int      `json:"write_in_threshold" binding:"gte=0"`
 }
 
 type UpdateVoteRequest struct {
-	ID       uint     `json:"id" binding:"required"`
-	Title    string   `json:"title" binding:"required"`
-	Options  []string `json:"options" binding:"required,min=2"`
-	Multi    bool     `json:"multi"`
-	Deadline int64    `json:"deadline"`
+	ID                 uint     `json:"id" binding:"required"`
+	Title              string   `json:"title" binding:"required"`
+	Description        string   `json:"description" binding:"max=5000"`
+	Options            []string `json:"options" binding:"required,min=2"`
+	OptionDescriptions []string `json:"option_descriptions" binding:"dive,max=2000"` // 与 Options 按位置对应，可以比 Options 短
+	Multi              bool     `json:"multi"`
+	Deadline           int64    `json:"deadline"`
+	Tags               []string `json:"tags" binding:"max=10,dive,max=32"`
+	CategoryID         *uint    `json:"category_id"`
+	WeightSetID        *uint    `json:"weight_set_id"`
+	ResultVisibility   string   `json:"result_visibility" binding:"omitempty,oneof=always after_vote after_close"`
+	Suggestions        string   `json:"suggestions" binding:"omitempty,oneof=off immediate approval"`
+	WriteIn            bool     `json:"write_in"`
+	WriteInThreshold   int      `json:"write_in_threshold" binding:"gte=0"`
 }
 
 type VoteRequest struct {
-	VoteID    uint   `json:"vote_id" binding:"required"`
-	OptionIDs []uint `json:"option_ids" binding:"required"`
+	VoteID     uint   `json:"vote_id" binding:"required"`
+	OptionIDs  []uint `json:"option_ids" binding:"required"`
+	OnBehalfOf uint   `json:"on_behalf_of"`
+	WriteIn    string `json:"write_in" binding:"max=100"` // 选择“其他”选项时填写的内容
+}
+
+type TallyOption struct {
+	OptionID        uint    `json:"option_id"`
+	Content         string  `json:"content"`
+	Direct          int     `json:"direct"`
+	Delegated       int     `json:"delegated"`
+	Total           int     `json:"total"`
+	DirectWeight    float64 `json:"direct_weight"`
+	DelegatedWeight float64 `json:"delegated_weight"`
+	TotalWeight     float64 `json:"total_weight"`
+}
+
+type TallyResponse struct {
+	VoteID          uint          `json:"vote_id"`
+	Weighted        bool          `json:"weighted"`
+	DirectVoters    int           `json:"direct_voters"`
+	DelegatedVoters int           `json:"delegated_voters"`
+	VoterWeight     float64       `json:"voter_weight"`
+	Options         []TallyOption `json:"options"`
+}
+
+type OptionDelta struct {
+	OptionID uint    `json:"option_id"`
+	Count    int     `json:"count"`
+	Weight   float64 `json:"weight"`
+}
+
+// BallotEvent 实时推送的计票增量
+type BallotEvent struct {
+	VoteID  uint          `json:"vote_id"`
+	VoterID uint          `json:"voter_id,omitempty"` // 投票人，只用于判断可见性，推送给客户端前清除
+	Turnout int64         `json:"turnout"`
+	Deltas  []OptionDelta `json:"deltas,omitempty"`
+}
+
+// StateEvent 实时推送的投票状态变化
+type StateEvent struct {
+	VoteID uint   `json:"vote_id"`
+	State  string `json:"state"`
+}
+
+// LiveSnapshot 建立实时连接时推送的当前结果
+type LiveSnapshot struct {
+	VoteID         uint                 `json:"vote_id"`
+	Title          string               `json:"title"`
+	Deadline       int64                `json:"deadline"`
+	Closed         bool                 `json:"closed"`
+	Turnout        int64                `json:"turnout"`
+	ResultsVisible bool                 `json:"results_visible"`
+	Options        []LiveSnapshotOption `json:"options"`
+}
+
+type LiveSnapshotOption struct {
+	OptionID      uint    `json:"option_id"`
+	Content       string  `json:"content"`
+	Count         int     `json:"count"`
+	WeightedCount float64 `json:"weighted_count"`
+}
+
+type ResultOption struct {
+	OptionID            uint    `json:"option_id"`
+	Content             string  `json:"content"`
+	Count               int     `json:"count"`
+	Weight              float64 `json:"weight"`
+	PercentOfVoters     float64 `json:"percent_of_voters"`
+	PercentOfSelections float64 `json:"percent_of_selections"`
+	PercentOfWeight     float64 `json:"percent_of_weight"`
+	Rank                int     `json:"rank"`
+}
+
+type ResultTurnout struct {
+	Voters          int     `json:"voters"`
+	DirectVoters    int     `json:"direct_voters"`
+	DelegatedVoters int     `json:"delegated_voters"`
+	Selections      int     `json:"selections"`
+	VoterWeight     float64 `json:"voter_weight"`
+}
+
+type ResultsResponse struct {
+	VoteID      uint           `json:"vote_id"`
+	Title       string         `json:"title"`
+	Multi       bool           `json:"multi"`
+	Weighted    bool           `json:"weighted"`
+	Final       bool           `json:"final"`
+	FinalizedAt *int64         `json:"finalized_at"`
+	Turnout     ResultTurnout  `json:"turnout"`
+	Options     []ResultOption `json:"options"`
+	Winners     []uint         `json:"winners"`
+	Tie         bool           `json:"tie"`
+}
+
+type TimelineQuery struct {
+	Bucket   string `form:"bucket" binding:"omitempty,oneof=minute hour day"`
+	TimeZone string `form:"tz"`
+	From     int64  `form:"from"` // Unix 秒，可选
+	To       int64  `form:"to"`
+}
+
+type TimelineOption struct {
+	OptionID uint   `json:"option_id"`
+	Content  string `json:"content"`
+}
+
+// TimelinePoint 一个时间桶的统计，数组与 TimelineResponse.Options 一一对应
+type TimelinePoint struct {
+	Start             string    `json:"start"`
+	Voters            int       `json:"voters"`
+	CumulativeVoters  int       `json:"cumulative_voters"`
+	Counts            []int     `json:"counts"`
+	CumulativeCounts  []int     `json:"cumulative_counts"`
+	Weights           []float64 `json:"weights"`
+	CumulativeWeights []float64 `json:"cumulative_weights"`
+}
+
+type TimelineResponse struct {
+	VoteID   uint             `json:"vote_id"`
+	Bucket   string           `json:"bucket"`
+	TimeZone string           `json:"time_zone"`
+	Options  []TimelineOption `json:"options"`
+	Points   []TimelinePoint  `json:"points"`
+}
+
+// 投票列表排序方式
+const (
+	SortNewest = "newest" // 最新创建
+	SortEnding = "ending" // 最早截止，无截止时间的排在最后
+	SortVotes  = "votes"  // 参与人数最多
+)
+
+type VoteListQuery struct {
+	Cursor       string `form:"cursor"` // 上一页返回的 next_cursor
+	Limit        int    `form:"limit" binding:"gte=0,lte=100"`
+	Sort         string `form:"sort" binding:"omitempty,oneof=newest ending votes"`
+	State        string `form:"state" binding:"omitempty,oneof=open closed"`
+	CreatorID    uint   `form:"creator_id"`
+	Multi        *bool  `form:"multi"`
+	Weighted     *bool  `form:"weighted"`
+	Voted        *bool  `form:"voted"` // 当前用户是否已投票
+	DeadlineFrom int64  `form:"deadline_from"`
+	DeadlineTo   int64  `form:"deadline_to"`
+	Tag          string `form:"tag"`
+	CategoryID   uint   `form:"category_id"`
+}
+
+type SearchQuery struct {
+	Q          string `form:"q" binding:"required,max=100"`
+	Cursor     string `form:"cursor"`
+	Limit      int    `form:"limit" binding:"gte=0,lte=50"`
+	State      string `form:"state" binding:"omitempty,oneof=open closed"`
+	Tag        string `form:"tag"`
+	CategoryID uint   `form:"category_id"`
+}
+
+// 发现页推荐类型
+const (
+	FeedTrending = "trending" // 近期投票速度最快
+	FeedEnding   = "ending"   // 即将截止
+	FeedNew      = "new"      // 最新创建
+)
+
+type DiscoverQuery struct {
+	Feed   string `form:"feed" binding:"omitempty,oneof=trending ending new"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"gte=0,lte=50"`
+}
+
+type PageQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"gte=0,lte=100"`
+}
+
+// HistoryItem 用户参与过的一个投票及自己的选择
+type HistoryItem struct {
+	VoteID         uint      `json:"vote_id"`
+	Title          string    `json:"title"`
+	Multi          bool      `json:"multi"`
+	Deadline       int64     `json:"deadline"`
+	State          string    `json:"state"` // open 或 closed
+	CategoryID     *uint     `json:"category_id"`
+	Tags           []string  `json:"tags"`
+	OptionIDs      []uint    `json:"option_ids"`
+	Options        []string  `json:"options"` // 所选选项的内容
+	Weight         float64   `json:"weight"`
+	ProxyID        *uint     `json:"proxy_id"` // 由代理人代投时为代理人ID
+	VotedAt        time.Time `json:"voted_at"`
+	ResultsVisible bool      `json:"results_visible"`
+}
+
+type HistoryResponse struct {
+	Items      []HistoryItem `json:"items"`
+	Total      int64         `json:"total"`
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
 }
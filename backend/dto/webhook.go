@@ -0,0 +1,36 @@
+package dto
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=512"`
+	Events []string `json:"events" binding:"dive,oneof=poll.created poll.opened poll.closed ballot.cast"`
+	VoteID *uint    `json:"vote_id"`
+	Tags   []string `json:"tags" binding:"max=10,dive,max=32"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=512"`
+	Events []string `json:"events" binding:"dive,oneof=poll.created poll.opened poll.closed ballot.cast"`
+	VoteID *uint    `json:"vote_id"`
+	Tags   []string `json:"tags" binding:"max=10,dive,max=32"`
+	Active bool     `json:"active"`
+}
+
+// WebhookPayload 投递给订阅方的请求体
+type WebhookPayload struct {
+	ID         uint        `json:"id"`
+	Event      string      `json:"event"`
+	VoteID     uint        `json:"vote_id"`
+	OccurredAt int64       `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+type BallotCastData struct {
+	UserID    uint    `json:"user_id"`
+	OptionIDs []uint  `json:"option_ids"`
+	Weight    float64 `json:"weight"`
+	ProxyID   *uint   `json:"proxy_id,omitempty"`
+}
+
+type PollStateData struct {
+	Title string `json:"title"`
+}
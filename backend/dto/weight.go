@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type CreateWeightSetRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+type WeightSetSummary struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Members     int64     `json:"members"`
+	TotalWeight float64   `json:"total_weight"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WeightEntryResponse struct {
+	UserID   uint    `json:"user_id"`
+	Username string  `json:"username"`
+	Weight   float64 `json:"weight"`
+}
+
+type WeightSetResponse struct {
+	ID      uint                  `json:"id"`
+	Name    string                `json:"name"`
+	Entries []WeightEntryResponse `json:"entries"`
+}
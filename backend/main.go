@@ -2,9 +2,13 @@ package main
 
 import (
 	"log"
+	_ "time/tzdata" // 运行镜像基于 scratch，没有时区数据库，时间线按时区统计时使用内置的时区数据
 	"vote-system-backend/config"
 	"vote-system-backend/database"
 	"vote-system-backend/router"
+	"vote-system-backend/service"
+	"vote-system-backend/storage"
+	"vote-system-backend/utils"
 )
 
 func main() {
@@ -14,6 +18,29 @@ func main() {
 	// 初始化数据库
 	database.Init(cfg)
 
+	// 初始化上传文件存储
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatal("存储初始化失败:", err)
+	}
+	storage.SetStorage(store)
+
+	// 加载 CHART_FONT 指定的图表字体，优先于编译时内置的补充字体
+	if cfg.Chart.Font != "" {
+		if err := utils.LoadChartFont(cfg.Chart.Font); err != nil {
+			log.Println("图表字体加载失败:", err)
+		}
+	}
+
+	// 启动 webhook 投递器
+	service.StartWebhookDispatcher()
+
+	// 启动过期访客清理
+	service.StartGuestReaper()
+
+	// 启动发现页排名刷新
+	service.StartDiscoverRefresher()
+
 	// 设置路由
 	r := router.SetupRouter(cfg)
 
@@ -9,13 +9,22 @@ import (
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
 	IdentityKey = "user_id"
+	// GuestKey 访客令牌的标记，GuestExpiresKey 为访客账号的过期时间
+	GuestKey        = "guest"
+	GuestExpiresKey = "guest_exp"
 )
 
+// guestRoutes 访客令牌可以访问的路由，访客只能参与演示会话中的投票
+var guestRoutes = map[string]bool{
+	"POST /api/vote/submit": true,
+}
+
 type UserClaims struct {
 	ID       uint   `json:"id"`
 	Username string `json:"username"`
@@ -23,26 +32,69 @@ type UserClaims struct {
 
 func PayloadFunc(data interface{}) jwt.MapClaims {
 	if user, ok := data.(*model.User); ok {
-		return jwt.MapClaims{
+		claims := jwt.MapClaims{
 			IdentityKey: user.ID,
 			"username":  user.Username,
 		}
+		if user.Guest {
+			claims[GuestKey] = true
+			claims[GuestExpiresKey] = user.CreatedAt.Add(model.GuestLifetime).Unix()
+		}
+		return claims
 	}
 	return jwt.MapClaims{}
 }
 
+// guestExpires 返回访客令牌中的账号过期时间，普通用户返回 false
+func guestExpires(claims jwt.MapClaims) (time.Time, bool) {
+	if guest, _ := claims[GuestKey].(bool); !guest {
+		return time.Time{}, false
+	}
+	// 签发时为 int64，从令牌中解析出来后为 float64
+	switch exp := claims[GuestExpiresKey].(type) {
+	case int64:
+		return time.Unix(exp, 0), true
+	case float64:
+		return time.Unix(int64(exp), 0), true
+	}
+	return time.Time{}, true
+}
+
+// TimeoutFunc 访客令牌在访客账号过期时失效，刷新也不能延长
+func TimeoutFunc(timeout time.Duration) func(data interface{}) time.Duration {
+	return func(data interface{}) time.Duration {
+		var claims jwt.MapClaims
+		switch v := data.(type) {
+		case gojwt.MapClaims:
+			claims = jwt.MapClaims(v)
+		case jwt.MapClaims:
+			claims = v
+		}
+		if expires, guest := guestExpires(claims); guest {
+			return min(timeout, time.Until(expires))
+		}
+		return timeout
+	}
+}
+
 func IdentityHandler(c *gin.Context) interface{} {
 	claims := jwt.ExtractClaims(c)
+	guest, _ := claims[GuestKey].(bool)
 	return &model.User{
 		ID:       uint(claims[IdentityKey].(float64)),
 		Username: claims["username"].(string),
+		Guest:    guest,
 	}
 }
 
 func Authorizator(data interface{}, c *gin.Context) bool {
 	if userClaims, ok := data.(*model.User); ok {
+		if userClaims.Guest && !guestRoutes[c.Request.Method+" "+c.FullPath()] {
+			return false
+		}
 		c.Set("user_id", userClaims.ID)
 		c.Set("username", userClaims.Username)
+		c.Set("guest", userClaims.Guest)
 		return true
 	}
 	return false
@@ -71,6 +123,7 @@ func JWT(cfg *config.Config) (*jwt.GinJWTMiddleware, error) {
 		Realm:       "vote-system",
 		Key:         []byte(cfg.JWT.Secret),
 		Timeout:     cfg.JWT.ExpireTime,
+		TimeoutFunc: TimeoutFunc(cfg.JWT.ExpireTime),
 		MaxRefresh:  time.Hour * 24,
 		IdentityKey: IdentityKey,
 
@@ -79,7 +132,8 @@ func JWT(cfg *config.Config) (*jwt.GinJWTMiddleware, error) {
 		Authenticator:   Authenticator,
 		Authorizator:    Authorizator,
 
-		TokenLookup:   "header: Authorization",
+		// EventSource 等无法设置请求头的客户端可以通过 query 传递 token
+		TokenLookup:   "header: Authorization, query: token",
 		TokenHeadName: "Bearer",
 		TimeFunc:      time.Now,
 	})
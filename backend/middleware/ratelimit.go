@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+	"vote-system-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 计数器数量超过该值时清理过期的计数器
+const rateLimitSweepSize = 10000
+
+type rateCounter struct {
+	start time.Time
+	count int
+}
+
+// RateLimit 按客户端IP限制请求频率，每个时间窗口最多 limit 次。
+// 计数保存在进程内，多副本部署时每个副本分别计数
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	counters := make(map[string]*rateCounter)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		if len(counters) > rateLimitSweepSize {
+			for key, counter := range counters {
+				if now.Sub(counter.start) >= window {
+					delete(counters, key)
+				}
+			}
+		}
+		counter := counters[ip]
+		if counter == nil || now.Sub(counter.start) >= window {
+			counter = &rateCounter{start: now}
+			counters[ip] = counter
+		}
+		counter.count++
+		exceeded := counter.count > limit
+		mu.Unlock()
+
+		if exceeded {
+			utils.Error(c, http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// AttributeDefinition 管理员定义的用户资料属性，如部门、地区、角色
+type AttributeDefinition struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"type:varchar(32);uniqueIndex;not null"`
+	Label     string    `json:"label" gorm:"type:varchar(64)"`
+	Choices   string    `json:"-" gorm:"type:text"` // 逗号分隔的可选值，为空时允许任意值
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserAttribute struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	UserID    uint      `json:"-" gorm:"uniqueIndex:idx_user_attribute"`
+	Name      string    `json:"name" gorm:"type:varchar(32);uniqueIndex:idx_user_attribute"`
+	Value     string    `json:"value" gorm:"type:varchar(64)"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BallotAttribute 投票时记录的投票人属性快照，之后修改资料不影响已投的票
+type BallotAttribute struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	VoteID    uint      `json:"vote_id" gorm:"index:idx_ballot_attribute"`
+	UserID    uint      `json:"user_id" gorm:"index:idx_ballot_attribute"`
+	Name      string    `json:"name" gorm:"type:varchar(32)"`
+	Value     string    `json:"value" gorm:"type:varchar(64)"`
+	CreatedAt time.Time `json:"created_at"`
+}
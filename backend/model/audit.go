@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// 风险限制审计状态
+const (
+	AuditInProgress = "in_progress"
+	AuditPassed     = "passed"     // 达到风险限制，确认公布的获胜选项
+	AuditFullCount  = "full_count" // 需要全面人工计票
+)
+
+// Audit 投票结束后的选票抽样风险限制审计（BRAVO）。抽样由公开种子决定，
+// 任何人拿到种子和选票清单都可以复现抽样结果
+type Audit struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	VoteID         uint      `json:"vote_id" gorm:"index"`
+	AuditorID      uint      `json:"auditor_id" gorm:"index"`
+	Seed           string    `json:"seed" gorm:"type:varchar(128);not null"`
+	RiskLimit      float64   `json:"risk_limit"`
+	MaxSamples     int       `json:"max_samples"`                           // 达到该抽样数仍未确认时需要全面人工计票
+	BallotCount    int       `json:"ballot_count"`                          // 选票清单中的选票数
+	ManifestHash   string    `json:"manifest_hash" gorm:"type:varchar(64)"` // 选票清单的 SHA-256
+	Reported       string    `json:"-" gorm:"type:text"`                    // 公布的各选项票数，JSON
+	ReportedWinner uint      `json:"reported_winner"`
+	Status         string    `json:"status" gorm:"type:varchar(16);default:in_progress"`
+	RiskMeasure    float64   `json:"risk_measure"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Samples []AuditSample `json:"samples" gorm:"foreignKey:AuditID;constraint:OnDelete:CASCADE"`
+}
+
+// AuditSample 一次抽样及审计员人工核对的结果。抽样是有放回的，同一张选票可能被多次抽中
+type AuditSample struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	AuditID    uint       `json:"audit_id" gorm:"uniqueIndex:idx_audit_sample"`
+	Sequence   int        `json:"sequence" gorm:"uniqueIndex:idx_audit_sample"` // 从1开始的抽样序号
+	BallotID   uint       `json:"ballot_id"`                                    // 选票中最小的 UserVote ID
+	UserID     uint       `json:"user_id"`
+	Recorded   bool       `json:"recorded" gorm:"default:false"`
+	Invalid    bool       `json:"invalid" gorm:"default:false"`        // 核对时选票无效或无法找到
+	Selections string     `json:"selections" gorm:"type:varchar(255)"` // 核对得到的选项 ID，逗号分隔
+	Matches    *bool      `json:"matches"`                             // 核对结果是否与系统记录一致
+	RecordedBy uint       `json:"recorded_by"`
+	RecordedAt *time.Time `json:"recorded_at"`
+}
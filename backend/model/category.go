@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Category 管理员维护的投票分类，每个投票最多属于一个分类
+type Category struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"type:varchar(32);uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	SortOrder   int       `json:"sort_order" gorm:"default:0"` // 越小越靠前
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
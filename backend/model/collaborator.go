@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// 协作者权限
+const (
+	PermissionEdit       = "edit"
+	PermissionClose      = "close"
+	PermissionViewVoters = "view_voters"
+	PermissionExport     = "export"
+)
+
+// 所有权转移状态
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferRejected  = "rejected"
+	TransferCancelled = "cancelled"
+)
+
+type VoteCollaborator struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	VoteID        uint      `json:"vote_id" gorm:"uniqueIndex:idx_vote_collaborator"`
+	UserID        uint      `json:"user_id" gorm:"uniqueIndex:idx_vote_collaborator"`
+	CanEdit       bool      `json:"can_edit" gorm:"default:false"`
+	CanClose      bool      `json:"can_close" gorm:"default:false"`
+	CanViewVoters bool      `json:"can_view_voters" gorm:"default:false"`
+	CanExport     bool      `json:"can_export" gorm:"default:false"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Has 判断协作者是否拥有指定权限
+func (c *VoteCollaborator) Has(permission string) bool {
+	switch permission {
+	case PermissionEdit:
+		return c.CanEdit
+	case PermissionClose:
+		return c.CanClose
+	case PermissionViewVoters:
+		return c.CanViewVoters
+	case PermissionExport:
+		return c.CanExport
+	}
+	return false
+}
+
+type VoteTransfer struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	VoteID     uint      `json:"vote_id" gorm:"index"`
+	FromUserID uint      `json:"from_user_id"`
+	ToUserID   uint      `json:"to_user_id" gorm:"index"`
+	Status     string    `json:"status" gorm:"type:varchar(16);default:pending"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Comment 投票下的讨论评论。删除或移除后保留记录以维持回复的层级，内容不再返回
+type Comment struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	VoteID     uint       `json:"vote_id" gorm:"index"`
+	ParentID   *uint      `json:"parent_id" gorm:"index"` // 回复的评论，为空表示顶层评论
+	RootID     *uint      `json:"root_id" gorm:"index"`   // 所在讨论串的顶层评论
+	UserID     uint       `json:"user_id" gorm:"index"`
+	Content    string     `json:"content" gorm:"type:text"`
+	RevealVote bool       `json:"reveal_vote" gorm:"default:false"` // 是否显示自己的投票
+	Pinned     bool       `json:"pinned" gorm:"default:false"`
+	PinnedAt   *time.Time `json:"pinned_at"`
+	Deleted    bool       `json:"deleted" gorm:"default:false"` // 作者删除
+	RemovedBy  *uint      `json:"removed_by"`                   // 移除评论的管理者
+	EditedAt   *time.Time `json:"edited_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Visible 评论未被删除或移除
+func (c *Comment) Visible() bool {
+	return !c.Deleted && c.RemovedBy == nil
+}
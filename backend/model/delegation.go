@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Delegation 将投票权委托给他人，Tag 为空表示对所有投票生效
+type Delegation struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	DelegatorID uint      `json:"delegator_id" gorm:"uniqueIndex:idx_delegator_tag"`
+	DelegateID  uint      `json:"delegate_id" gorm:"index"`
+	Tag         string    `json:"tag" gorm:"type:varchar(32);uniqueIndex:idx_delegator_tag"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
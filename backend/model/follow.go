@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Follow 用户关注另一位用户，关注后可以在关注动态中看到对方新建的投票
+type Follow struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	FollowerID uint      `json:"follower_id" gorm:"uniqueIndex:idx_follow"`
+	FolloweeID uint      `json:"followee_id" gorm:"uniqueIndex:idx_follow;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Bookmark 用户收藏的投票
+type Bookmark struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_bookmark"`
+	VoteID    uint      `json:"vote_id" gorm:"uniqueIndex:idx_bookmark;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OptionImage 选项的图片，文件保存在存储中，URL 在返回时按当前存储生成
+type OptionImage struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	VoteID      uint      `json:"vote_id" gorm:"index"`
+	OptionID    uint      `json:"option_id" gorm:"index"`
+	Key         string    `json:"-" gorm:"type:varchar(255);not null"`
+	ThumbKey    string    `json:"-" gorm:"type:varchar(255);not null"`
+	ContentType string    `json:"content_type" gorm:"type:varchar(32)"`
+	Size        int64     `json:"size"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	UploaderID  uint      `json:"uploader_id"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	URL      string `json:"url" gorm:"-"`
+	ThumbURL string `json:"thumb_url" gorm:"-"`
+}
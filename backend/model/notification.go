@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// 通知类型
+const (
+	NotificationMention = "mention" // 在评论中被提到
+)
+
+type Notification struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index:idx_notification_user,priority:1"`
+	Read      bool      `json:"read" gorm:"default:false;index:idx_notification_user,priority:2"`
+	Type      string    `json:"type" gorm:"type:varchar(16);not null"`
+	ActorID   uint      `json:"actor_id"` // 触发通知的用户
+	VoteID    uint      `json:"vote_id"`
+	CommentID uint      `json:"comment_id" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Presentation 演示会话，演示者按顺序展示一组投票
+type Presentation struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Title        string    `json:"title" gorm:"not null"`
+	PresenterID  uint      `json:"presenter_id" gorm:"index"`
+	Code         string    `json:"code" gorm:"type:varchar(8);uniqueIndex"`
+	CurrentIndex int       `json:"current_index" gorm:"default:-1"` // -1 表示尚未开始
+	Active       bool      `json:"active" gorm:"default:true"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// 关联
+	Items []PresentationItem `json:"items" gorm:"foreignKey:PresentationID;constraint:OnDelete:CASCADE"`
+}
+
+type PresentationItem struct {
+	ID             uint `json:"-" gorm:"primaryKey"`
+	PresentationID uint `json:"-" gorm:"index"`
+	VoteID         uint `json:"vote_id"`
+	Position       int  `json:"position"`
+}
+
+// PresentationViewer 在线的观众连接。保存在数据库中，多个副本共享，按最近的心跳统计在线人数
+type PresentationViewer struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PresentationID uint      `json:"presentation_id" gorm:"index"`
+	SeenAt         time.Time `json:"seen_at" gorm:"index"`
+}
+
+// PresentationGuest 访客加入的演示会话，访客只能参与该会话中的投票
+type PresentationGuest struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	PresentationID uint      `json:"presentation_id" gorm:"index"`
+	UserID         uint      `json:"user_id" gorm:"uniqueIndex"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	MechanismLaplace  = "laplace"
+	MechanismGaussian = "gaussian"
+)
+
+// 差分隐私发布的统计类型
+const (
+	ReleaseResults  = "results"
+	ReleaseCrosstab = "crosstab"
+)
+
+// PrivacyBudget 投票的差分隐私预算，启用后精确票数只对管理员可见
+type PrivacyBudget struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	VoteID    uint      `json:"vote_id" gorm:"uniqueIndex"`
+	Mechanism string    `json:"mechanism" gorm:"type:varchar(16);not null"`
+	Epsilon   float64   `json:"epsilon"` // 总预算
+	Delta     float64   `json:"delta"`   // 仅高斯机制使用
+	Spent     float64   `json:"spent"`   // 已消耗的预算
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PrivacyRelease 一次加噪发布的结果。相同的查询在选票未变化时直接返回已发布的结果，不再消耗预算
+type PrivacyRelease struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	VoteID      uint      `json:"vote_id" gorm:"index:idx_privacy_release"`
+	Kind        string    `json:"kind" gorm:"type:varchar(16);index:idx_privacy_release"`
+	Attribute   string    `json:"attribute" gorm:"type:varchar(32);index:idx_privacy_release"`
+	Epsilon     float64   `json:"epsilon"`
+	BallotCount int64     `json:"ballot_count"` // 发布时的选票记录数，用于判断选票是否变化
+	RequestedBy uint      `json:"requested_by"`
+	Payload     string    `json:"-" gorm:"type:mediumtext"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// 代理授权状态
+const (
+	ProxyPending  = "pending"
+	ProxyAccepted = "accepted"
+	ProxyDeclined = "declined"
+	ProxyRevoked  = "revoked"
+)
+
+// ProxyGrant 委托人授权代理人在指定投票中代为投票
+type ProxyGrant struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	VoteID      uint       `json:"vote_id" gorm:"index"`
+	PrincipalID uint       `json:"principal_id" gorm:"index"`
+	ProxyID     uint       `json:"proxy_id" gorm:"index"`
+	Status      string     `json:"status" gorm:"type:varchar(16);default:pending"`
+	Signature   string     `json:"signature" gorm:"type:varchar(64)"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
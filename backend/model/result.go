@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// ResultSnapshot 投票结束后冻结的最终结果，重新开启投票时删除
+type ResultSnapshot struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	VoteID    uint      `json:"vote_id" gorm:"uniqueIndex"`
+	Payload   string    `json:"payload" gorm:"type:mediumtext"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// 选项建议状态
+const (
+	SuggestionPending  = "pending"
+	SuggestionApproved = "approved"
+	SuggestionRejected = "rejected"
+)
+
+// OptionSuggestion 投票人建议的新选项
+type OptionSuggestion struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	VoteID     uint      `json:"vote_id" gorm:"index"`
+	UserID     uint      `json:"user_id" gorm:"index"`
+	Content    string    `json:"content" gorm:"type:varchar(255);not null"`
+	Status     string    `json:"status" gorm:"type:varchar(16);default:pending"`
+	OptionID   *uint     `json:"option_id"`   // 采纳后创建的选项
+	ReviewedBy *uint     `json:"reviewed_by"` // 立即加入的建议为空
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	Option *VoteOption `json:"-" gorm:"foreignKey:OptionID;constraint:OnDelete:SET NULL"`
+}
+
+// WriteInAnswer 选择“其他”选项时填写的内容，与对应的投票记录一一对应。
+// 提升为正式选项后，投票记录改为指向该选项，投票时间和权重不变
+type WriteInAnswer struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	VoteID           uint      `json:"vote_id" gorm:"index:idx_write_in_key,priority:1"`
+	UserVoteID       uint      `json:"user_vote_id" gorm:"uniqueIndex"`
+	UserID           uint      `json:"user_id"`
+	Content          string    `json:"content" gorm:"type:varchar(100);not null"`
+	Normalized       string    `json:"normalized" gorm:"type:varchar(100);index:idx_write_in_key,priority:2"` // 归一化后的内容，相同的视为同一答案
+	PromotedOptionID *uint     `json:"promoted_option_id"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	UserVote UserVote `json:"-" gorm:"foreignKey:UserVoteID;constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 问卷的问题类型
+const (
+	QuestionSingle = "single" // 单选，由一个投票承载
+	QuestionMulti  = "multi"  // 多选，由一个投票承载
+	QuestionText   = "text"   // 文本
+	QuestionLikert = "likert" // 李克特量表，回答为 ScaleMin 到 ScaleMax 的整数
+	QuestionNPS    = "nps"    // 净推荐值，回答为 0 到 10 的整数
+	QuestionNumber = "number" // 数字，可以限制范围
+)
+
+// Survey 由多个有序问题组成的问卷，所有问题一次提交
+type Survey struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Title            string         `json:"title" gorm:"type:varchar(255);not null"`
+	Description      string         `json:"description" gorm:"type:text"`
+	DescriptionHTML  string         `json:"description_html" gorm:"type:text"`
+	Deadline         int64          `json:"deadline"`
+	Closed           bool           `json:"closed" gorm:"default:false"`
+	ResultVisibility string         `json:"result_visibility" gorm:"type:varchar(16);default:always"`
+	ResponseCount    int            `json:"response_count" gorm:"default:0"`
+	CreatorID        uint           `json:"creator_id" gorm:"index"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Questions []SurveyQuestion `json:"questions,omitempty" gorm:"foreignKey:SurveyID;constraint:OnDelete:CASCADE"`
+}
+
+// SurveyQuestion 问卷中的一个问题。单选和多选题的选项和计票由 Vote 承载
+type SurveyQuestion struct {
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	SurveyID        uint   `json:"survey_id" gorm:"index"`
+	Position        int    `json:"position"`
+	Type            string `json:"type" gorm:"type:varchar(16);not null"`
+	Title           string `json:"title" gorm:"type:varchar(255);not null"`
+	Description     string `json:"description" gorm:"type:text"`
+	DescriptionHTML string `json:"description_html" gorm:"type:text"`
+	Required        bool   `json:"required" gorm:"default:false"`
+	VoteID          *uint  `json:"vote_id"`
+
+	// 量表题的范围和两端的说明，NPS 固定为 0 到 10
+	ScaleMin int    `json:"scale_min"`
+	ScaleMax int    `json:"scale_max"`
+	MinLabel string `json:"min_label" gorm:"type:varchar(32)"`
+	MaxLabel string `json:"max_label" gorm:"type:varchar(32)"`
+
+	// 数字题的范围，为空表示不限
+	NumberMin *float64 `json:"number_min"`
+	NumberMax *float64 `json:"number_max"`
+
+	Vote *Vote `json:"vote,omitempty" gorm:"foreignKey:VoteID"`
+}
+
+// SurveyResponse 一位用户对问卷的一次提交
+type SurveyResponse struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SurveyID  uint      `json:"survey_id" gorm:"uniqueIndex:idx_survey_response"`
+	UserID    uint      `json:"user_id" gorm:"uniqueIndex:idx_survey_response"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Answers []SurveyAnswer `json:"answers,omitempty" gorm:"foreignKey:ResponseID;constraint:OnDelete:CASCADE"`
+}
+
+// SurveyAnswer 非选择题的回答，选择题的回答记录在对应投票的 UserVote 中
+type SurveyAnswer struct {
+	ID         uint     `json:"id" gorm:"primaryKey"`
+	ResponseID uint     `json:"response_id" gorm:"index"`
+	QuestionID uint     `json:"question_id" gorm:"index"`
+	Text       string   `json:"text" gorm:"type:text"`
+	Number     *float64 `json:"number" gorm:"type:decimal(20,6)"`
+}
@@ -6,15 +6,29 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin" // 管理员可以导出所有投票、管理分类和用户属性，并查看差分隐私投票的精确结果
+)
+
+// GuestLifetime 访客账号的有效期，过期后令牌失效，未投票的访客账号由后台清理
+const GuestLifetime = 12 * time.Hour
+
 type User struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
 	Username     string         `json:"username" gorm:"unique;not null"`
 	PasswordHash string         `json:"-" gorm:"not null"`
+	Guest        bool           `json:"guest" gorm:"default:false"` // 通过演示会话码加入的访客
+	Role         string         `json:"role" gorm:"type:varchar(16);default:user"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联
-	Votes     []Vote     `json:"votes" gorm:"foreignKey:CreatorID"`
-	UserVotes []UserVote `json:"user_votes" gorm:"foreignKey:UserID"`
+	Votes      []Vote          `json:"votes" gorm:"foreignKey:CreatorID"`
+	UserVotes  []UserVote      `json:"user_votes" gorm:"foreignKey:UserID"`
+	Attributes []UserAttribute `json:"attributes,omitempty" gorm:"foreignKey:UserID"`
+	Following  []Follow        `json:"-" gorm:"foreignKey:FollowerID"` // 我关注的用户
+	Followers  []Follow        `json:"-" gorm:"foreignKey:FolloweeID"` // 关注我的用户
+	Bookmarks  []Bookmark      `json:"-" gorm:"foreignKey:UserID"`
 }
@@ -6,38 +6,81 @@ import (
 	"gorm.io/gorm"
 )
 
+// 结果可见性
+const (
+	ResultsAlways     = "always"
+	ResultsAfterVote  = "after_vote"
+	ResultsAfterClose = "after_close"
+)
+
+// 投票人建议选项的方式
+const (
+	SuggestionsOff       = "off"
+	SuggestionsImmediate = "immediate" // 建议的选项立即加入
+	SuggestionsApproval  = "approval"  // 需要创建者或协作者审核
+)
+
 type Vote struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Title     string         `json:"title" gorm:"not null"`
-	Multi     bool           `json:"multi" gorm:"default:false"`
-	Deadline  int64          `json:"deadline"`
-	CreatorID uint           `json:"creator_id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Title            string         `json:"title" gorm:"not null;index:idx_votes_search,class:FULLTEXT,option:WITH PARSER ngram"`
+	Description      string         `json:"description" gorm:"type:text;index:idx_votes_search,class:FULLTEXT,option:WITH PARSER ngram"`
+	DescriptionHTML  string         `json:"description_html" gorm:"type:text"` // 由 Description 的 Markdown 渲染
+	Multi            bool           `json:"multi" gorm:"default:false"`
+	Deadline         int64          `json:"deadline"`
+	Closed           bool           `json:"closed" gorm:"default:false"`
+	DeadlineNotified int64          `json:"-" gorm:"default:0"` // 已发送 poll.closed 事件的截止时间，截止时间修改后到期会再次发送
+	CategoryID       *uint          `json:"category_id" gorm:"index"`
+	WeightSetID      *uint          `json:"weight_set_id"`                                            // 为空表示每人权重为1
+	ResultVisibility string         `json:"result_visibility" gorm:"type:varchar(16);default:always"` // 结果可见性
+	Suggestions      string         `json:"suggestions" gorm:"type:varchar(16);default:off"`          // 投票人建议选项的方式
+	WriteIn          bool           `json:"write_in" gorm:"default:false"`                            // 是否提供“其他”选项并收集填写的内容
+	WriteInThreshold int            `json:"write_in_threshold" gorm:"default:1"`                      // 填写内容提升为正式选项所需的最少票数
+	VoterCount       int            `json:"voter_count" gorm:"default:0;index"`                       // 直接投票的人数，用于列表排序
+	CommentCount     int            `json:"comment_count" gorm:"default:0"`                           // 未删除的评论数
+	SurveyID         *uint          `json:"survey_id" gorm:"index"`                                   // 问卷中选择题对应的投票，不单独出现在列表中
+	CreatorID        uint           `json:"creator_id"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联
 	Options   []VoteOption `json:"options" gorm:"foreignKey:VoteID;constraint:OnDelete:CASCADE"`
 	UserVotes []UserVote   `json:"user_votes" gorm:"foreignKey:VoteID;constraint:OnDelete:CASCADE"`
+	Tags      []VoteTag    `json:"tags" gorm:"foreignKey:VoteID;constraint:OnDelete:CASCADE"`
+}
+
+// VoteTag 投票的标签。随委托一起引入，用于限定委托的范围；标签筛选、标签云和 webhook 过滤也使用它
+type VoteTag struct {
+	ID     uint   `json:"-" gorm:"primaryKey"`
+	VoteID uint   `json:"-" gorm:"index"`
+	Name   string `json:"name" gorm:"type:varchar(32);index;not null"`
 }
 
 type VoteOption struct {
-	ID      uint   `json:"id" gorm:"primaryKey"`
-	VoteID  uint   `json:"vote_id"`
-	Content string `json:"content" gorm:"not null"`
-	Count   int    `json:"count" gorm:"default:0"`
+	ID            uint    `json:"id" gorm:"primaryKey"`
+	VoteID        uint    `json:"vote_id"`
+	Content       string  `json:"content" gorm:"not null;index:idx_vote_options_search,class:FULLTEXT,option:WITH PARSER ngram"`
+	Count         int     `json:"count" gorm:"default:0"`
+	WeightedCount float64 `json:"weighted_count" gorm:"type:decimal(20,6);default:0"` // 未启用权重时与 Count 相同
+	WriteIn       bool    `json:"write_in" gorm:"default:false"`                      // “其他”选项，选择时需要填写内容
+	// 选项说明，支持 Markdown
+	Description     string `json:"description" gorm:"type:text"`
+	DescriptionHTML string `json:"description_html" gorm:"type:text"`
 
 	// 关联
-	UserVotes []UserVote `json:"user_votes" gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE"`
+	UserVotes []UserVote    `json:"user_votes" gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE"`
+	Images    []OptionImage `json:"images,omitempty" gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE"`
 }
 
 type UserVote struct {
-	ID       uint `json:"id" gorm:"primaryKey"`
-	UserID   uint `json:"user_id"`
-	VoteID   uint `json:"vote_id"`
-	OptionID uint `json:"option_id"`
+	ID       uint    `json:"id" gorm:"primaryKey"`
+	UserID   uint    `json:"user_id"`
+	VoteID   uint    `json:"vote_id" gorm:"index:idx_user_votes_vote_time,priority:1"`
+	OptionID uint    `json:"option_id"`
+	Weight   float64 `json:"weight" gorm:"type:decimal(20,6);default:1"` // 投票时记录的权重
+	ProxyID  *uint   `json:"proxy_id"`                                   // 代理投票时为实际提交人
 
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_user_votes_vote_time,priority:2"`
 
 	// 复合唯一索引，防止重复投票
 	// 单选：一个用户对一个投票只能有一条记录
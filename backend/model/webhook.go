@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// Webhook 事件类型
+const (
+	EventPollCreated = "poll.created"
+	EventPollOpened  = "poll.opened"
+	EventPollClosed  = "poll.closed"
+	EventBallotCast  = "ballot.cast"
+)
+
+// Webhook 投递状态
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookSubscription 用户订阅自己创建的投票的事件
+type WebhookSubscription struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	URL       string    `json:"url" gorm:"type:varchar(512);not null"`
+	Secret    string    `json:"-" gorm:"type:varchar(64);not null"`
+	Events    string    `json:"events" gorm:"type:varchar(255)"` // 逗号分隔，为空表示全部事件
+	VoteID    *uint     `json:"vote_id"`                         // 为空表示全部投票
+	Tags      string    `json:"tags" gorm:"type:varchar(255)"`   // 逗号分隔，只接收带有其中任一标签的投票的事件，为空表示不限
+	Active    bool      `json:"active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OutboxEvent 与业务数据在同一事务中写入的事件，由投递器异步分发
+type OutboxEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Type      string    `json:"type" gorm:"type:varchar(32)"`
+	VoteID    uint      `json:"vote_id"`
+	OwnerID   uint      `json:"owner_id"`
+	Payload   string    `json:"payload" gorm:"type:text"`
+	Processed bool      `json:"processed" gorm:"index;default:false"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery 每个订阅对每个事件的一次投递记录
+type WebhookDelivery struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint      `json:"subscription_id" gorm:"index"`
+	EventID        uint      `json:"event_id"`
+	EventType      string    `json:"event_type" gorm:"type:varchar(32)"`
+	Status         string    `json:"status" gorm:"type:varchar(16);index"`
+	Attempts       int       `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at" gorm:"index"`
+	LastStatusCode int       `json:"last_status_code"`
+	LastError      string    `json:"last_error" gorm:"type:varchar(512)"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// WeightSet 投票权重名单，可在多个投票之间复用（如股东名册）
+type WeightSet struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	OwnerID   uint      `json:"owner_id" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联
+	Entries []WeightEntry `json:"entries,omitempty" gorm:"foreignKey:SetID;constraint:OnDelete:CASCADE"`
+}
+
+type WeightEntry struct {
+	ID     uint    `json:"-" gorm:"primaryKey"`
+	SetID  uint    `json:"-" gorm:"uniqueIndex:idx_set_user"`
+	UserID uint    `json:"user_id" gorm:"uniqueIndex:idx_set_user"`
+	Weight float64 `json:"weight" gorm:"type:decimal(20,6);not null"`
+}
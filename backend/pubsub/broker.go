@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 事件类型
+const (
+	EventSnapshot = "snapshot"
+	EventBallot   = "ballot"
+	EventState    = "state"
+
+	EventNowShowing   = "now_showing"
+	EventParticipants = "participants"
+)
+
+// Event 推送给订阅者的事件，Data 需要可以序列化为JSON，以便替换为跨进程的消息队列
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Decode 把事件数据解码到 v。进程内传递时 Data 是原始结构体，经过消息队列后是解码出的 JSON 对象，
+// 统一经过 JSON 转换，两种情况都能正确处理
+func Decode(event Event, v interface{}) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Broker 发布/订阅接口，多副本部署时可以替换为 Redis、NATS 等实现
+type Broker interface {
+	Publish(topic string, event Event)
+	// Subscribe 返回事件通道和取消订阅函数
+	Subscribe(topic string) (<-chan Event, func())
+}
+
+var broker Broker = NewMemoryBroker()
+
+func SetBroker(b Broker) {
+	broker = b
+}
+
+func GetBroker() Broker {
+	return broker
+}
+
+// VoteTopic 返回投票对应的主题
+func VoteTopic(voteID uint) string {
+	return fmt.Sprintf("vote:%d", voteID)
+}
+
+// PresentationTopic 返回演示会话对应的主题
+func PresentationTopic(presentationID uint) string {
+	return fmt.Sprintf("presentation:%d", presentationID)
+}
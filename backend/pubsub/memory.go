@@ -0,0 +1,55 @@
+package pubsub
+
+import "sync"
+
+// 每个订阅者的缓冲区大小，消费过慢时丢弃新事件
+const subscriberBuffer = 64
+
+// MemoryBroker 进程内的发布/订阅实现
+type MemoryBroker struct {
+	mu     sync.RWMutex
+	topics map[string]map[chan Event]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(topic string, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.topics[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (b *MemoryBroker) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[chan Event]struct{})
+	}
+	b.topics[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.topics[topic], ch)
+			if len(b.topics[topic]) == 0 {
+				delete(b.topics, topic)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"log"
+	"strings"
+	"time"
 	"vote-system-backend/config"
 	"vote-system-backend/controller"
 	"vote-system-backend/middleware"
@@ -9,6 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// 每个IP每分钟最多以访客身份加入的次数
+const guestJoinLimit = 10
+
 func SetupRouter(cfg *config.Config) *gin.Engine {
 	r := gin.Default()
 
@@ -23,6 +28,30 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	// 控制器
 	authController := controller.NewAuthController()
 	voteController := controller.NewVoteController()
+	collaboratorController := controller.NewCollaboratorController()
+	delegationController := controller.NewDelegationController()
+	weightController := controller.NewWeightController()
+	proxyController := controller.NewProxyController()
+	presentationController := controller.NewPresentationController(jwtMiddleware)
+	webhookController := controller.NewWebhookController()
+	exportController := controller.NewExportController()
+	certificateController := controller.NewCertificateController()
+	shareController := controller.NewShareController()
+	attributeController := controller.NewAttributeController()
+	privacyController := controller.NewPrivacyController()
+	auditController := controller.NewAuditController()
+	categoryController := controller.NewCategoryController()
+	socialController := controller.NewSocialController()
+	commentController := controller.NewCommentController()
+	notificationController := controller.NewNotificationController()
+	suggestionController := controller.NewSuggestionController()
+	imageController := controller.NewImageController()
+	surveyController := controller.NewSurveyController()
+
+	// 本地存储的上传文件由后端直接提供
+	if (cfg.Storage.Driver == "" || cfg.Storage.Driver == "local") && strings.HasPrefix(cfg.Storage.BaseURL, "/") {
+		r.Static(cfg.Storage.BaseURL, cfg.Storage.Dir)
+	}
 
 	// 公共路由
 	api := r.Group("/api")
@@ -46,6 +75,195 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			vote.POST("/submit", voteController.Vote)
 			vote.GET("/my", voteController.GetUserVotes)
 			vote.GET("/all", voteController.GetAllVotes)
+			vote.GET("/history", voteController.GetHistory)
+			vote.GET("/inbox", voteController.GetInbox)
+			vote.GET("/discover", voteController.Discover)
+			vote.GET("/following", socialController.GetFeed)
+			vote.GET("/bookmarks", socialController.GetBookmarks)
+			vote.POST("/:id/bookmark", socialController.Bookmark)
+			vote.DELETE("/:id/bookmark", socialController.RemoveBookmark)
+			vote.GET("/:id/comments", commentController.GetComments)
+			vote.POST("/:id/comments", commentController.CreateComment)
+			vote.GET("/:id/suggestions", suggestionController.GetSuggestions)
+			vote.POST("/:id/suggestions", suggestionController.SuggestOption)
+			vote.GET("/:id/write-ins", suggestionController.GetWriteIns)
+			vote.POST("/:id/write-ins/promote", suggestionController.PromoteWriteIn)
+			vote.POST("/:id/options/:optionId/images", imageController.UploadImage)
+			vote.DELETE("/:id/images/:imageId", imageController.DeleteImage)
+			vote.GET("/search", voteController.SearchVotes)
+			vote.GET("/tags", voteController.GetTagCloud)
+			vote.POST("/:id/close", voteController.CloseVote)
+			vote.GET("/:id/voters", voteController.GetVoters)
+			vote.GET("/:id/tally", voteController.GetTally)
+			vote.GET("/:id/results", voteController.GetResults)
+			vote.GET("/:id/timeline", voteController.GetTimeline)
+			vote.GET("/:id/crosstab", voteController.GetCrosstab)
+			vote.GET("/:id/privacy", privacyController.GetBudget)
+			vote.PUT("/:id/privacy", privacyController.SetBudget)
+			vote.DELETE("/:id/privacy", privacyController.DisableBudget)
+			vote.GET("/:id/noisy-results", privacyController.GetNoisyResults)
+			vote.GET("/:id/stream", voteController.StreamVote)
+			vote.GET("/:id/export", exportController.Export)
+			vote.GET("/:id/certificate", certificateController.GetCertificate)
+			vote.POST("/:id/audit", auditController.CreateAudit)
+			vote.GET("/:id/audits", auditController.GetVoteAudits)
+
+			// 协作者与所有权转移
+			vote.GET("/:id/collaborators", collaboratorController.GetCollaborators)
+			vote.PUT("/:id/collaborators", collaboratorController.SetCollaborator)
+			vote.DELETE("/:id/collaborators/:userId", collaboratorController.RemoveCollaborator)
+			vote.POST("/:id/transfer", collaboratorController.CreateTransfer)
+			vote.DELETE("/:id/transfer", collaboratorController.CancelTransfer)
+			vote.GET("/transfers", collaboratorController.GetIncomingTransfers)
+			vote.POST("/transfers/:transferId/accept", collaboratorController.AcceptTransfer)
+			vote.POST("/transfers/:transferId/reject", collaboratorController.RejectTransfer)
+
+			// 代理投票
+			vote.POST("/:id/proxy", proxyController.GrantProxy)
+			vote.GET("/:id/proxies", proxyController.GetVoteProxies)
+		}
+
+		delegation := api.Group("/delegation", jwtMiddleware.MiddlewareFunc())
+		{
+			delegation.POST("/set", delegationController.SetDelegation)
+			delegation.GET("/my", delegationController.GetMyDelegations)
+			delegation.DELETE("/:id", delegationController.DeleteDelegation)
+		}
+
+		weight := api.Group("/weight", jwtMiddleware.MiddlewareFunc())
+		{
+			weight.POST("/create", weightController.CreateWeightSet)
+			weight.GET("/my", weightController.GetMyWeightSets)
+			weight.GET("/:id", weightController.GetWeightSet)
+			weight.POST("/:id/import", weightController.ImportWeights)
+			weight.DELETE("/:id", weightController.DeleteWeightSet)
+		}
+
+		// 关注用户
+		user := api.Group("/user", jwtMiddleware.MiddlewareFunc())
+		{
+			user.GET("/following", socialController.GetFollowing)
+			user.GET("/followers", socialController.GetFollowers)
+			user.POST("/:id/follow", socialController.Follow)
+			user.DELETE("/:id/follow", socialController.Unfollow)
+		}
+
+		// 选项建议审核
+		suggestion := api.Group("/suggestion", jwtMiddleware.MiddlewareFunc())
+		{
+			suggestion.POST("/:id/approve", suggestionController.ApproveSuggestion)
+			suggestion.POST("/:id/reject", suggestionController.RejectSuggestion)
+		}
+
+		// 问卷
+		survey := api.Group("/survey", jwtMiddleware.MiddlewareFunc())
+		{
+			survey.POST("/create", surveyController.CreateSurvey)
+			survey.GET("/mine", surveyController.GetMySurveys)
+			survey.GET("/:id", surveyController.GetSurvey)
+			survey.PUT("/:id", surveyController.UpdateSurvey)
+			survey.DELETE("/:id", surveyController.DeleteSurvey)
+			survey.POST("/:id/close", surveyController.CloseSurvey)
+			survey.POST("/:id/submit", surveyController.SubmitSurvey)
+			survey.GET("/:id/results", surveyController.GetResults)
+		}
+
+		// 评论
+		comment := api.Group("/comment", jwtMiddleware.MiddlewareFunc())
+		{
+			comment.PUT("/:id", commentController.UpdateComment)
+			comment.DELETE("/:id", commentController.DeleteComment)
+			comment.POST("/:id/pin", commentController.PinComment)
+			comment.DELETE("/:id/pin", commentController.UnpinComment)
+		}
+
+		// 通知
+		notification := api.Group("/notification", jwtMiddleware.MiddlewareFunc())
+		{
+			notification.GET("/list", notificationController.GetNotifications)
+			notification.POST("/read", notificationController.MarkRead)
+		}
+
+		// 投票分类，由管理员维护
+		category := api.Group("/category", jwtMiddleware.MiddlewareFunc())
+		{
+			category.GET("/list", categoryController.GetCategories)
+			category.POST("/create", categoryController.CreateCategory)
+			category.PUT("/:id", categoryController.UpdateCategory)
+			category.DELETE("/:id", categoryController.DeleteCategory)
+		}
+
+		// 风险限制审计
+		audit := api.Group("/audit", jwtMiddleware.MiddlewareFunc())
+		{
+			audit.GET("/:id", auditController.GetAudit)
+			audit.POST("/:id/draw", auditController.DrawSamples)
+			audit.PUT("/:id/samples/:sequence", auditController.RecordSample)
+		}
+
+		proxy := api.Group("/proxy", jwtMiddleware.MiddlewareFunc())
+		{
+			proxy.GET("/my", proxyController.GetMyProxies)
+			proxy.POST("/:id/accept", proxyController.AcceptProxy)
+			proxy.POST("/:id/decline", proxyController.DeclineProxy)
+			proxy.POST("/:id/revoke", proxyController.RevokeProxy)
+		}
+
+		// 演示模式，观众通过会话码加入，无需登录
+		presentation := api.Group("/presentation")
+		{
+			presentation.POST("/join", middleware.RateLimit(guestJoinLimit, time.Minute), presentationController.Join)
+			presentation.GET("/ws/:code", presentationController.Audience)
+
+			presenter := presentation.Group("", jwtMiddleware.MiddlewareFunc())
+			{
+				presenter.POST("/create", presentationController.CreatePresentation)
+				presenter.GET("/my", presentationController.GetMyPresentations)
+				presenter.GET("/:id", presentationController.GetPresentation)
+				presenter.POST("/:id/next", presentationController.Next)
+				presenter.POST("/:id/prev", presentationController.Prev)
+				presenter.POST("/:id/goto", presentationController.Goto)
+				presenter.POST("/:id/open", presentationController.OpenCurrent)
+				presenter.POST("/:id/close", presentationController.CloseCurrent)
+				presenter.POST("/:id/end", presentationController.End)
+			}
+		}
+
+		webhook := api.Group("/webhook", jwtMiddleware.MiddlewareFunc())
+		{
+			webhook.POST("/create", webhookController.CreateSubscription)
+			webhook.GET("/my", webhookController.GetMySubscriptions)
+			webhook.PUT("/:id", webhookController.UpdateSubscription)
+			webhook.DELETE("/:id", webhookController.DeleteSubscription)
+			webhook.GET("/:id/deliveries", webhookController.GetDeliveries)
+			webhook.POST("/deliveries/:id/redeliver", webhookController.Redeliver)
+		}
+
+		attribute := api.Group("/attribute", jwtMiddleware.MiddlewareFunc())
+		{
+			attribute.GET("/list", attributeController.GetAttributes)
+			attribute.POST("/define", attributeController.DefineAttribute)
+			attribute.DELETE("/:name", attributeController.DeleteAttribute)
+			attribute.GET("/my", attributeController.GetMyAttributes)
+			attribute.PUT("/my", attributeController.SetMyAttributes)
+			attribute.PUT("/user/:userId", attributeController.SetUserAttributes)
+		}
+
+		// 结果证书验证，无需登录
+		certificate := api.Group("/certificate")
+		{
+			certificate.GET("/public-key", certificateController.PublicKey)
+			certificate.POST("/verify", certificateController.VerifyCertificate)
+		}
+
+		// 分享链接预览，无需登录，按未登录用户的结果可见性渲染
+		share := api.Group("/share")
+		{
+			share.GET("/:id", shareController.SharePage)
+			share.GET("/:id/meta", shareController.GetMeta)
+			share.GET("/:id/chart.svg", shareController.ChartSVG)
+			share.GET("/:id/chart.png", shareController.ChartPNG)
+			share.GET("/:id/og.png", shareController.OGImage)
 		}
 
 	}
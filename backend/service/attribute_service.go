@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type AttributeService struct{}
+
+func NewAttributeService() *AttributeService {
+	return &AttributeService{}
+}
+
+func toAttributeResponse(def *model.AttributeDefinition) dto.AttributeDefinitionResponse {
+	choices := []string{}
+	if def.Choices != "" {
+		choices = strings.Split(def.Choices, ",")
+	}
+	return dto.AttributeDefinitionResponse{Name: def.Name, Label: def.Label, Choices: choices}
+}
+
+func findAttribute(name string) (*model.AttributeDefinition, error) {
+	var def model.AttributeDefinition
+	if err := database.GetDB().Where("name = ?", name).First(&def).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("属性不存在")
+		}
+		return nil, err
+	}
+	return &def, nil
+}
+
+// DefineAttribute 新增或修改属性定义，仅管理员可用
+func (s *AttributeService) DefineAttribute(req *dto.AttributeDefinitionRequest, userID uint) error {
+	if !isAdmin(userID) {
+		return errors.New("仅管理员可以定义属性")
+	}
+	if !attributeNamePattern.MatchString(req.Name) {
+		return errors.New("属性名只能包含小写字母、数字和下划线，且以字母开头")
+	}
+
+	choices := make([]string, 0, len(req.Choices))
+	for _, c := range req.Choices {
+		c = strings.TrimSpace(c)
+		if strings.Contains(c, ",") {
+			return errors.New("可选值不能包含逗号")
+		}
+		if c != "" {
+			choices = append(choices, c)
+		}
+	}
+
+	var def model.AttributeDefinition
+	err := database.GetDB().Where("name = ?", req.Name).First(&def).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	def.Name = req.Name
+	def.Label = req.Label
+	def.Choices = strings.Join(choices, ",")
+	return database.GetDB().Save(&def).Error
+}
+
+// DeleteAttribute 删除属性定义及用户填写的值，已投选票的快照保留
+func (s *AttributeService) DeleteAttribute(name string, userID uint) error {
+	if !isAdmin(userID) {
+		return errors.New("仅管理员可以删除属性")
+	}
+
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("name = ?", name).Delete(&model.AttributeDefinition{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("属性不存在")
+		}
+		return tx.Where("name = ?", name).Delete(&model.UserAttribute{}).Error
+	})
+}
+
+// GetAttributes 返回全部属性定义
+func (s *AttributeService) GetAttributes() ([]dto.AttributeDefinitionResponse, error) {
+	var defs []model.AttributeDefinition
+	if err := database.GetDB().Order("id").Find(&defs).Error; err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.AttributeDefinitionResponse, 0, len(defs))
+	for i := range defs {
+		resp = append(resp, toAttributeResponse(&defs[i]))
+	}
+	return resp, nil
+}
+
+// GetUserAttributes 返回用户填写的属性
+func (s *AttributeService) GetUserAttributes(userID uint) (map[string]string, error) {
+	var attrs []model.UserAttribute
+	if err := database.GetDB().Where("user_id = ?", userID).Find(&attrs).Error; err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(attrs))
+	for _, a := range attrs {
+		values[a.Name] = a.Value
+	}
+	return values, nil
+}
+
+// SetUserAttributes 设置用户的属性值，管理员可以设置其他用户的属性
+func (s *AttributeService) SetUserAttributes(targetID uint, req *dto.SetAttributesRequest, userID uint) error {
+	if targetID != userID && !isAdmin(userID) {
+		return errors.New("仅管理员可以修改其他用户的属性")
+	}
+
+	var defs []model.AttributeDefinition
+	if err := database.GetDB().Find(&defs).Error; err != nil {
+		return err
+	}
+	definitions := make(map[string]*model.AttributeDefinition, len(defs))
+	for i := range defs {
+		definitions[defs[i].Name] = &defs[i]
+	}
+
+	for name, value := range req.Attributes {
+		def, ok := definitions[name]
+		if !ok {
+			return errors.New("属性不存在: " + name)
+		}
+		value = strings.TrimSpace(value)
+		if len([]rune(value)) > 64 {
+			return errors.New("属性值过长: " + name)
+		}
+		if value != "" && def.Choices != "" && !slices.Contains(strings.Split(def.Choices, ","), value) {
+			return errors.New("属性值不在可选范围内: " + name)
+		}
+		req.Attributes[name] = value
+	}
+
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		for name, value := range req.Attributes {
+			if value == "" {
+				if err := tx.Where("user_id = ? AND name = ?", targetID, name).Delete(&model.UserAttribute{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			var attr model.UserAttribute
+			err := tx.Where("user_id = ? AND name = ?", targetID, name).First(&attr).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			attr.UserID = targetID
+			attr.Name = name
+			attr.Value = value
+			if err := tx.Save(&attr).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// snapshotAttributes 在投票事务中记录投票人当前的属性
+func snapshotAttributes(tx *gorm.DB, voteID uint, userID uint) error {
+	var attrs []model.UserAttribute
+	if err := tx.Where("user_id = ?", userID).Find(&attrs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("vote_id = ? AND user_id = ?", voteID, userID).Delete(&model.BallotAttribute{}).Error; err != nil {
+		return err
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	snapshot := make([]model.BallotAttribute, 0, len(attrs))
+	for _, a := range attrs {
+		snapshot = append(snapshot, model.BallotAttribute{VoteID: voteID, UserID: userID, Name: a.Name, Value: a.Value})
+	}
+	return tx.Create(&snapshot).Error
+}
@@ -0,0 +1,594 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRiskLimit = 0.05
+	// maxDrawPerRequest 单次最多抽取的选票数
+	maxDrawPerRequest = 1000
+)
+
+type AuditService struct{}
+
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// manifestBallot 选票清单中的一张选票，以投票人最早的一条 UserVote 的 ID 作为选票编号
+type manifestBallot struct {
+	BallotID uint
+	UserID   uint
+}
+
+// auditManifest 返回按选票编号排序的选票清单及其哈希，审计期间清单不能变化
+func auditManifest(voteID uint) ([]manifestBallot, string, error) {
+	var ballots []manifestBallot
+	err := database.GetDB().Model(&model.UserVote{}).
+		Select("MIN(id) AS ballot_id, user_id").
+		Where("vote_id = ?", voteID).
+		Group("user_id").
+		Order("ballot_id").
+		Scan(&ballots).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	h := sha256.New()
+	for _, b := range ballots {
+		fmt.Fprintf(h, "%d|%d\n", b.BallotID, b.UserID)
+	}
+	return ballots, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sampleIndex 返回第 sequence 次抽样在选票清单中的位置：SHA-256(种子,序号) 对选票数取模
+func sampleIndex(seed string, sequence int, n int) int {
+	sum := sha256.Sum256([]byte(seed + "," + strconv.Itoa(sequence)))
+	index := new(big.Int).SetBytes(sum[:])
+	return int(index.Mod(index, big.NewInt(int64(n))).Int64())
+}
+
+func joinIDs(ids []uint) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitIDs(s string) []uint {
+	ids := []uint{}
+	if s == "" {
+		return ids
+	}
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.ParseUint(part, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// auditVote 加载投票并检查审计权限，启用差分隐私的投票只有管理员可以审计
+func auditVote(voteID uint, userID uint) (*model.Vote, error) {
+	var vote model.Vote
+	if err := database.GetDB().Preload("Options").First(&vote, voteID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投票不存在")
+		}
+		return nil, err
+	}
+	if !hasPermission(&vote, userID, model.PermissionViewVoters) {
+		return nil, errors.New("没有权限审计此投票")
+	}
+	if privacyEnabled(vote.ID) && !isAdmin(userID) {
+		return nil, errors.New("启用差分隐私的投票只有管理员可以审计")
+	}
+	return &vote, nil
+}
+
+func findAudit(id uint) (*model.Audit, error) {
+	var audit model.Audit
+	err := database.GetDB().Preload("Samples", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence")
+	}).First(&audit, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("审计不存在")
+		}
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// auditEvaluation 按抽样序号依次计算的 BRAVO 检验结果
+type auditEvaluation struct {
+	tests     []dto.AuditTest
+	risk      float64
+	status    string
+	message   string
+	recorded  int // 从第1号开始连续已核对的抽样数
+	estimated int
+}
+
+// evaluateAudit 对获胜选项与每个落选选项分别进行 BRAVO 序贯检验。抽中的选票只选了获胜选项时
+// 似然比乘以 2s，只选了落选选项时乘以 2(1-s)，其余不变，s 为公布结果中获胜选项在两者中的占比。
+// 所有对比的似然比都达到 1/风险限制 时审计通过。序贯检验要求按抽样顺序核对，
+// 只统计从第1号开始连续已核对的抽样
+func evaluateAudit(audit *model.Audit, vote *model.Vote, reported []dto.AuditReportedOption) *auditEvaluation {
+	eval := &auditEvaluation{risk: 1, status: model.AuditInProgress}
+
+	var winner int
+	contents := make(map[uint]string)
+	for _, option := range vote.Options {
+		contents[option.ID] = option.Content
+	}
+	for _, r := range reported {
+		if r.OptionID == audit.ReportedWinner {
+			winner = r.Count
+		}
+	}
+	if audit.ReportedWinner == 0 {
+		eval.status = model.AuditFullCount
+		eval.message = "公布结果为平票，无法通过抽样确认，需要全面人工计票"
+		return eval
+	}
+
+	type pair struct {
+		loser     uint
+		share     float64
+		statistic float64
+	}
+	var pairs []*pair
+	for _, r := range reported {
+		if r.OptionID == audit.ReportedWinner {
+			continue
+		}
+		pairs = append(pairs, &pair{loser: r.OptionID, share: float64(winner) / float64(winner+r.Count), statistic: 1})
+	}
+
+	for _, sample := range audit.Samples {
+		if !sample.Recorded {
+			break
+		}
+		eval.recorded++
+		if sample.Invalid {
+			continue
+		}
+		selections := splitIDs(sample.Selections)
+		hasWinner := slices.Contains(selections, audit.ReportedWinner)
+		for _, p := range pairs {
+			hasLoser := slices.Contains(selections, p.loser)
+			switch {
+			case hasWinner && !hasLoser:
+				p.statistic *= 2 * p.share
+			case hasLoser && !hasWinner:
+				p.statistic *= 2 * (1 - p.share)
+			}
+		}
+	}
+
+	threshold := 1 / audit.RiskLimit
+	confirmed := true
+	eval.risk = 0
+	for _, p := range pairs {
+		test := dto.AuditTest{
+			LoserID:     p.loser,
+			Content:     contents[p.loser],
+			Share:       p.share,
+			Statistic:   p.statistic,
+			RiskMeasure: 1,
+			Confirmed:   p.statistic >= threshold,
+		}
+		if p.statistic > 1 {
+			test.RiskMeasure = 1 / p.statistic
+		}
+		if !test.Confirmed {
+			confirmed = false
+		}
+		eval.risk = math.Max(eval.risk, test.RiskMeasure)
+		eval.tests = append(eval.tests, test)
+	}
+	eval.estimated = estimateSamples(audit, winner, reported)
+
+	switch {
+	case confirmed:
+		eval.status = model.AuditPassed
+		eval.message = "已达到风险限制，公布的获胜选项得到确认"
+	case len(audit.Samples) >= audit.MaxSamples && eval.recorded == len(audit.Samples):
+		eval.status = model.AuditFullCount
+		eval.message = "已达到最大抽样数仍未达到风险限制，需要全面人工计票"
+	case eval.recorded < len(audit.Samples):
+		eval.message = fmt.Sprintf("请核对第 %d 号抽样", eval.recorded+1)
+	default:
+		eval.message = "尚未达到风险限制，请继续抽样"
+	}
+	return eval
+}
+
+// estimateSamples 估算公布结果正确时所需的平均抽样数，取差距最小的落选选项：
+// ln(1/α) / (p_w·ln(2s) + p_l·ln(2(1-s)))，p 为选项得票占选票数的比例
+func estimateSamples(audit *model.Audit, winner int, reported []dto.AuditReportedOption) int {
+	estimate := 0
+	n := float64(audit.BallotCount)
+	for _, r := range reported {
+		if r.OptionID == audit.ReportedWinner {
+			continue
+		}
+		s := float64(winner) / float64(winner+r.Count)
+		drift := float64(winner) / n * math.Log(2*s)
+		if r.Count > 0 {
+			drift += float64(r.Count) / n * math.Log(2*(1-s))
+		}
+		if drift <= 0 {
+			return audit.MaxSamples
+		}
+		estimate = max(estimate, int(math.Ceil(math.Log(1/audit.RiskLimit)/drift)))
+	}
+	return min(estimate, audit.MaxSamples)
+}
+
+func toAuditResponse(audit *model.Audit, vote *model.Vote, withSamples bool) (*dto.AuditResponse, error) {
+	var reported []dto.AuditReportedOption
+	if err := json.Unmarshal([]byte(audit.Reported), &reported); err != nil {
+		return nil, err
+	}
+	eval := evaluateAudit(audit, vote, reported)
+
+	resp := &dto.AuditResponse{
+		ID:              audit.ID,
+		VoteID:          audit.VoteID,
+		VoteTitle:       vote.Title,
+		AuditorID:       audit.AuditorID,
+		Seed:            audit.Seed,
+		RiskLimit:       audit.RiskLimit,
+		MaxSamples:      audit.MaxSamples,
+		BallotCount:     audit.BallotCount,
+		ManifestHash:    audit.ManifestHash,
+		Reported:        reported,
+		ReportedWinner:  audit.ReportedWinner,
+		Status:          audit.Status,
+		RiskMeasure:     audit.RiskMeasure,
+		Message:         eval.message,
+		Tests:           eval.tests,
+		SamplesDrawn:    len(audit.Samples),
+		SamplesRecorded: eval.recorded,
+		EstimatedTotal:  eval.estimated,
+		CreatedAt:       audit.CreatedAt,
+		UpdatedAt:       audit.UpdatedAt,
+	}
+	for _, sample := range audit.Samples {
+		if sample.Matches != nil && !*sample.Matches {
+			resp.Discrepancies++
+		}
+	}
+	if !withSamples {
+		return resp, nil
+	}
+
+	userIDs := make([]uint, 0, len(audit.Samples))
+	for _, sample := range audit.Samples {
+		userIDs = append(userIDs, sample.UserID)
+	}
+	names, err := usernamesByID(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	resp.Samples = make([]dto.AuditSampleResponse, 0, len(audit.Samples))
+	for _, sample := range audit.Samples {
+		resp.Samples = append(resp.Samples, dto.AuditSampleResponse{
+			Sequence:   sample.Sequence,
+			BallotID:   sample.BallotID,
+			UserID:     sample.UserID,
+			Username:   names[sample.UserID],
+			Recorded:   sample.Recorded,
+			Invalid:    sample.Invalid,
+			OptionIDs:  splitIDs(sample.Selections),
+			Matches:    sample.Matches,
+			RecordedAt: sample.RecordedAt,
+		})
+	}
+	return resp, nil
+}
+
+// refreshAudit 重新计算风险度量和审计状态并保存
+func refreshAudit(tx *gorm.DB, audit *model.Audit, vote *model.Vote) error {
+	var reported []dto.AuditReportedOption
+	if err := json.Unmarshal([]byte(audit.Reported), &reported); err != nil {
+		return err
+	}
+	eval := evaluateAudit(audit, vote, reported)
+	audit.Status = eval.status
+	audit.RiskMeasure = eval.risk
+	return tx.Model(audit).Updates(map[string]interface{}{
+		"status":       audit.Status,
+		"risk_measure": audit.RiskMeasure,
+	}).Error
+}
+
+// CreateAudit 为已结束的投票创建风险限制审计，记录选票清单和公布的结果。
+// 加权投票的获胜选项由权重决定，不能按人头抽样审计；有委托票计入结果的投票，
+// 公布的结果包含委托票，只核对直接投票无法确认，同样不能审计
+func (s *AuditService) CreateAudit(voteID uint, req *dto.CreateAuditRequest, userID uint) (*dto.AuditResponse, error) {
+	vote, err := auditVote(voteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !voteEnded(vote) {
+		return nil, errors.New("投票尚未结束")
+	}
+	if vote.WeightSetID != nil {
+		return nil, errors.New("加权投票暂不支持抽样审计")
+	}
+
+	manifest, hash, err := auditManifest(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest) == 0 {
+		return nil, errors.New("没有可审计的选票")
+	}
+
+	ballots, err := directBallots(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	inherited, err := delegatedBallots(vote, ballots)
+	if err != nil {
+		return nil, err
+	}
+	if len(inherited) > 0 {
+		return nil, errors.New("有委托票计入结果的投票暂不支持抽样审计")
+	}
+	counts, _ := countBallots(ballots)
+	reported := make([]dto.AuditReportedOption, 0, len(vote.Options))
+	for _, option := range vote.Options {
+		reported = append(reported, dto.AuditReportedOption{OptionID: option.ID, Content: option.Content, Count: counts[option.ID]})
+	}
+	sort.SliceStable(reported, func(i, j int) bool {
+		return reported[i].Count > reported[j].Count
+	})
+	payload, err := json.Marshal(reported)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := model.Audit{
+		VoteID:       vote.ID,
+		AuditorID:    userID,
+		Seed:         req.Seed,
+		RiskLimit:    req.RiskLimit,
+		MaxSamples:   req.MaxSamples,
+		BallotCount:  len(manifest),
+		ManifestHash: hash,
+		Reported:     string(payload),
+		Status:       model.AuditInProgress,
+		RiskMeasure:  1,
+	}
+	if audit.RiskLimit == 0 {
+		audit.RiskLimit = defaultRiskLimit
+	}
+	if audit.MaxSamples == 0 {
+		audit.MaxSamples = len(manifest)
+	}
+	// 平票时不设获胜选项
+	if len(reported) == 1 || reported[0].Count > reported[1].Count {
+		audit.ReportedWinner = reported[0].OptionID
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&audit).Error; err != nil {
+			return err
+		}
+		return refreshAudit(tx, &audit, vote)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAuditResponse(&audit, vote, true)
+}
+
+// GetVoteAudits 返回投票的所有审计
+func (s *AuditService) GetVoteAudits(voteID uint, userID uint) ([]dto.AuditResponse, error) {
+	vote, err := auditVote(voteID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var audits []model.Audit
+	if err := database.GetDB().Preload("Samples", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence")
+	}).Where("vote_id = ?", vote.ID).Order("id DESC").Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AuditResponse, 0, len(audits))
+	for i := range audits {
+		resp, err := toAuditResponse(&audits[i], vote, false)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+	return responses, nil
+}
+
+// GetAudit 返回审计详情及全部抽样
+func (s *AuditService) GetAudit(id uint, userID uint) (*dto.AuditResponse, error) {
+	audit, err := findAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	vote, err := auditVote(audit.VoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toAuditResponse(audit, vote, true)
+}
+
+// DrawSamples 按种子继续抽取选票。count 为0时抽到预计所需的抽样数，至少抽取1张
+func (s *AuditService) DrawSamples(id uint, req *dto.DrawSamplesRequest, userID uint) (*dto.AuditResponse, error) {
+	audit, err := findAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	vote, err := auditVote(audit.VoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if audit.Status != model.AuditInProgress {
+		return nil, errors.New("审计已结束")
+	}
+
+	drawn := len(audit.Samples)
+	if drawn >= audit.MaxSamples {
+		return nil, errors.New("已达到最大抽样数")
+	}
+	count := req.Count
+	if count == 0 {
+		var reported []dto.AuditReportedOption
+		if err := json.Unmarshal([]byte(audit.Reported), &reported); err != nil {
+			return nil, err
+		}
+		count = max(evaluateAudit(audit, vote, reported).estimated-drawn, 1)
+	}
+	count = min(count, audit.MaxSamples-drawn, maxDrawPerRequest)
+
+	manifest, hash, err := auditManifest(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hash != audit.ManifestHash {
+		return nil, errors.New("选票清单已变化，无法继续审计")
+	}
+
+	// 有放回抽样，抽中已核对过的选票时沿用之前的核对结果
+	recorded := make(map[uint]model.AuditSample)
+	for _, sample := range audit.Samples {
+		if sample.Recorded {
+			recorded[sample.BallotID] = sample
+		}
+	}
+	samples := make([]model.AuditSample, 0, count)
+	for i := 1; i <= count; i++ {
+		sequence := drawn + i
+		b := manifest[sampleIndex(audit.Seed, sequence, len(manifest))]
+		sample := model.AuditSample{AuditID: audit.ID, Sequence: sequence, BallotID: b.BallotID, UserID: b.UserID}
+		if prev, ok := recorded[b.BallotID]; ok {
+			sample.Recorded = true
+			sample.Invalid = prev.Invalid
+			sample.Selections = prev.Selections
+			sample.Matches = prev.Matches
+			sample.RecordedBy = prev.RecordedBy
+			sample.RecordedAt = prev.RecordedAt
+		}
+		samples = append(samples, sample)
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&samples).Error; err != nil {
+			return err
+		}
+		audit.Samples = append(audit.Samples, samples...)
+		return refreshAudit(tx, audit, vote)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAuditResponse(audit, vote, true)
+}
+
+// RecordSample 记录审计员对一张抽中选票的人工核对结果，并与系统记录比对。
+// 审计结束前可以修改，同一张选票的其他抽样一并更新
+func (s *AuditService) RecordSample(id uint, sequence int, req *dto.RecordSampleRequest, userID uint) (*dto.AuditResponse, error) {
+	audit, err := findAudit(id)
+	if err != nil {
+		return nil, err
+	}
+	vote, err := auditVote(audit.VoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if audit.Status != model.AuditInProgress {
+		return nil, errors.New("审计已结束")
+	}
+
+	index := slices.IndexFunc(audit.Samples, func(sample model.AuditSample) bool {
+		return sample.Sequence == sequence
+	})
+	if index < 0 {
+		return nil, errors.New("抽样不存在")
+	}
+	ballotID := audit.Samples[index].BallotID
+
+	selections := []uint{}
+	if !req.Invalid {
+		for _, optionID := range req.OptionIDs {
+			valid := slices.ContainsFunc(vote.Options, func(option model.VoteOption) bool {
+				return option.ID == optionID
+			})
+			if !valid {
+				return nil, errors.New("选项不属于此投票")
+			}
+			if !slices.Contains(selections, optionID) {
+				selections = append(selections, optionID)
+			}
+		}
+		if len(selections) == 0 {
+			return nil, errors.New("请填写核对到的选项，空白或无法找到的选票请标记为无效")
+		}
+		if !vote.Multi && len(selections) > 1 {
+			return nil, errors.New("单选投票的选票只能有一个选项")
+		}
+	}
+	slices.Sort(selections)
+
+	var record []uint
+	if err := database.GetDB().Model(&model.UserVote{}).
+		Where("vote_id = ? AND user_id = ?", vote.ID, audit.Samples[index].UserID).
+		Order("option_id").Pluck("option_id", &record).Error; err != nil {
+		return nil, err
+	}
+	matches := !req.Invalid && slices.Equal(selections, record)
+
+	now := time.Now()
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		for i := range audit.Samples {
+			sample := &audit.Samples[i]
+			if sample.BallotID != ballotID {
+				continue
+			}
+			sample.Recorded = true
+			sample.Invalid = req.Invalid
+			sample.Selections = joinIDs(selections)
+			sample.Matches = &matches
+			sample.RecordedBy = userID
+			sample.RecordedAt = &now
+			if err := tx.Save(sample).Error; err != nil {
+				return err
+			}
+		}
+		return refreshAudit(tx, audit, vote)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toAuditResponse(audit, vote, true)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+)
+
+// auditSamples 返回 n 个已核对的抽样，selections 为每个抽样核对得到的选项
+func auditSamples(selections ...string) []model.AuditSample {
+	samples := make([]model.AuditSample, len(selections))
+	for i, s := range selections {
+		samples[i] = model.AuditSample{Sequence: i + 1, Recorded: true, Selections: s}
+	}
+	return samples
+}
+
+func repeat(s string, n int) []string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = s
+	}
+	return list
+}
+
+func TestEvaluateAudit(t *testing.T) {
+	vote := &model.Vote{Options: []model.VoteOption{{ID: 1, Content: "A"}, {ID: 2, Content: "B"}}}
+	reported := []dto.AuditReportedOption{{OptionID: 1, Count: 60}, {OptionID: 2, Count: 40}}
+
+	gap := auditSamples("1", "1", "1")
+	gap[1].Recorded = false
+	invalid := auditSamples(repeat("1", 13)...)
+	invalid[0].Invalid = true
+
+	tests := []struct {
+		name      string
+		winner    uint
+		max       int
+		samples   []model.AuditSample
+		status    string
+		message   string
+		recorded  int
+		statistic float64
+	}{
+		{
+			name:    "tied result needs a full count",
+			winner:  0,
+			max:     100,
+			status:  model.AuditFullCount,
+			message: "公布结果为平票，无法通过抽样确认，需要全面人工计票",
+		},
+		{
+			name:      "enough winner samples pass",
+			winner:    1,
+			max:       100,
+			samples:   auditSamples(repeat("1", 13)...),
+			status:    model.AuditPassed,
+			message:   "已达到风险限制，公布的获胜选项得到确认",
+			recorded:  13,
+			statistic: math.Pow(1.2, 13),
+		},
+		{
+			name:      "one sample short",
+			winner:    1,
+			max:       100,
+			samples:   auditSamples(repeat("1", 12)...),
+			status:    model.AuditInProgress,
+			message:   "尚未达到风险限制，请继续抽样",
+			recorded:  12,
+			statistic: math.Pow(1.2, 12),
+		},
+		{
+			name:      "invalid samples do not count",
+			winner:    1,
+			max:       100,
+			samples:   invalid,
+			status:    model.AuditInProgress,
+			message:   "尚未达到风险限制，请继续抽样",
+			recorded:  13,
+			statistic: math.Pow(1.2, 12),
+		},
+		{
+			name:      "only consecutive samples are counted",
+			winner:    1,
+			max:       100,
+			samples:   gap,
+			status:    model.AuditInProgress,
+			message:   "请核对第 2 号抽样",
+			recorded:  1,
+			statistic: 1.2,
+		},
+		{
+			name:      "loser samples reach the maximum",
+			winner:    1,
+			max:       3,
+			samples:   auditSamples("2", "2", "1,2"),
+			status:    model.AuditFullCount,
+			message:   "已达到最大抽样数仍未达到风险限制，需要全面人工计票",
+			recorded:  3,
+			statistic: 0.8 * 0.8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &model.Audit{
+				RiskLimit:      0.1,
+				MaxSamples:     tt.max,
+				BallotCount:    100,
+				ReportedWinner: tt.winner,
+				Samples:        tt.samples,
+			}
+			eval := evaluateAudit(audit, vote, reported)
+			if eval.status != tt.status {
+				t.Errorf("status = %q, want %q", eval.status, tt.status)
+			}
+			if eval.message != tt.message {
+				t.Errorf("message = %q, want %q", eval.message, tt.message)
+			}
+			if eval.recorded != tt.recorded {
+				t.Errorf("recorded = %d, want %d", eval.recorded, tt.recorded)
+			}
+			if tt.winner == 0 {
+				return
+			}
+			if len(eval.tests) != 1 {
+				t.Fatalf("tests = %d, want 1", len(eval.tests))
+			}
+			if got := eval.tests[0].Statistic; math.Abs(got-tt.statistic) > 1e-9 {
+				t.Errorf("statistic = %v, want %v", got, tt.statistic)
+			}
+		})
+	}
+}
+
+func TestEstimateSamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		ballots  int
+		reported []dto.AuditReportedOption
+		want     int
+	}{
+		{"clear margin", 100, []dto.AuditReportedOption{{OptionID: 1, Count: 60}, {OptionID: 2, Count: 40}}, 115},
+		{"closest loser decides", 100, []dto.AuditReportedOption{{OptionID: 1, Count: 50}, {OptionID: 2, Count: 40}, {OptionID: 3, Count: 10}}, 414},
+		{"unopposed winner", 60, []dto.AuditReportedOption{{OptionID: 1, Count: 60}, {OptionID: 2, Count: 0}}, 4},
+		{"tied with winner", 100, []dto.AuditReportedOption{{OptionID: 1, Count: 50}, {OptionID: 2, Count: 50}}, 500},
+		{"capped at the maximum", 1000, []dto.AuditReportedOption{{OptionID: 1, Count: 505}, {OptionID: 2, Count: 495}}, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &model.Audit{RiskLimit: 0.1, MaxSamples: 500, BallotCount: tt.ballots, ReportedWinner: 1}
+			if got := estimateSamples(audit, tt.reported[0].Count, tt.reported); got != tt.want {
+				t.Errorf("estimateSamples() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAuditRefusesDelegatedVotes(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 4)
+	votes := NewVoteService()
+	audits := NewAuditService()
+
+	vote, err := votes.CreateVote(&dto.CreateVoteRequest{Title: "年度议题", Options: []string{"甲", "乙"}}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := optionsByContent(t, vote.ID)
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{options["甲"].ID}}, users[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{options["乙"].ID}}, users[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	// user4 委托给 user2，公布的结果中“甲”以 2 比 1 获胜
+	if _, err := NewDelegationService().SetDelegation(&dto.DelegationRequest{Username: users[1].Username}, users[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.GetDB().Model(&model.Vote{}).Where("id = ?", vote.ID).Update("closed", true).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	req := &dto.CreateAuditRequest{Seed: "314159265"}
+	if _, err := audits.CreateAudit(vote.ID, req, users[0].ID); err == nil {
+		t.Fatal("created an audit for a vote with delegated ballots")
+	}
+
+	// 委托人自己投票后没有委托票计入，可以审计
+	if err := database.GetDB().Model(&model.Vote{}).Where("id = ?", vote.ID).Update("closed", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{options["甲"].ID}}, users[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.GetDB().Model(&model.Vote{}).Where("id = ?", vote.ID).Update("closed", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	audit, err := audits.CreateAudit(vote.ID, req, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if audit.BallotCount != 3 || audit.ReportedWinner != options["甲"].ID {
+		t.Errorf("audit = %d ballots winner %d, want 3 ballots winner %d", audit.BallotCount, audit.ReportedWinner, options["甲"].ID)
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type CategoryService struct{}
+
+func NewCategoryService() *CategoryService {
+	return &CategoryService{}
+}
+
+// checkCategory 检查分类是否存在，为空表示不设分类
+func checkCategory(categoryID *uint) error {
+	if categoryID == nil {
+		return nil
+	}
+	var count int64
+	if err := database.GetDB().Model(&model.Category{}).Where("id = ?", *categoryID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("分类不存在")
+	}
+	return nil
+}
+
+func findCategory(id uint) (*model.Category, error) {
+	var category model.Category
+	if err := database.GetDB().First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("分类不存在")
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// checkCategoryName 检查分类名是否为空或与其他分类重复
+func checkCategoryName(name string, excludeID uint) error {
+	if name == "" {
+		return errors.New("分类名不能为空")
+	}
+	var count int64
+	if err := database.GetDB().Model(&model.Category{}).Where("name = ? AND id <> ?", name, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("分类名已存在")
+	}
+	return nil
+}
+
+// GetCategories 返回全部分类及其中的投票数
+func (s *CategoryService) GetCategories() ([]dto.CategoryResponse, error) {
+	var categories []model.Category
+	if err := database.GetDB().Order("sort_order, id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		CategoryID uint
+		Count      int64
+	}
+	if err := database.GetDB().Model(&model.Vote{}).
+		Select("category_id, COUNT(*) AS count").
+		Where("category_id IS NOT NULL").
+		Group("category_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byCategory := make(map[uint]int64)
+	for _, c := range counts {
+		byCategory[c.CategoryID] = c.Count
+	}
+
+	responses := make([]dto.CategoryResponse, 0, len(categories))
+	for _, c := range categories {
+		responses = append(responses, dto.CategoryResponse{
+			ID:          c.ID,
+			Name:        c.Name,
+			Description: c.Description,
+			SortOrder:   c.SortOrder,
+			VoteCount:   byCategory[c.ID],
+		})
+	}
+	return responses, nil
+}
+
+// CreateCategory 新建分类，仅管理员可用
+func (s *CategoryService) CreateCategory(req *dto.CategoryRequest, userID uint) (*model.Category, error) {
+	if !isAdmin(userID) {
+		return nil, errors.New("仅管理员可以管理分类")
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := checkCategoryName(name, 0); err != nil {
+		return nil, err
+	}
+
+	category := model.Category{Name: name, Description: req.Description, SortOrder: req.SortOrder}
+	if err := database.GetDB().Create(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory 修改分类，仅管理员可用
+func (s *CategoryService) UpdateCategory(id uint, req *dto.CategoryRequest, userID uint) (*model.Category, error) {
+	if !isAdmin(userID) {
+		return nil, errors.New("仅管理员可以管理分类")
+	}
+	category, err := findCategory(id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := checkCategoryName(name, category.ID); err != nil {
+		return nil, err
+	}
+
+	category.Name = name
+	category.Description = req.Description
+	category.SortOrder = req.SortOrder
+	if err := database.GetDB().Save(category).Error; err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory 删除分类，其中的投票变为未分类，仅管理员可用
+func (s *CategoryService) DeleteCategory(id uint, userID uint) error {
+	if !isAdmin(userID) {
+		return errors.New("仅管理员可以管理分类")
+	}
+	category, err := findCategory(id)
+	if err != nil {
+		return err
+	}
+
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Vote{}).Unscoped().Where("category_id = ?", category.ID).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"vote-system-backend/config"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/utils"
+)
+
+const (
+	MethodPlurality = "plurality" // 单选，得票最多者胜
+	MethodApproval  = "approval"  // 多选，每个选项独立计票
+)
+
+// 证书内容和签名以注释行的形式追加在 PDF 末尾，阅读器会忽略 %%EOF 之后的内容。
+// 签名覆盖签名行之前的全部字节，包括 PDF 本身和证书内容
+const (
+	certificateMarker = "%VOTE-CERTIFICATE "
+	signatureMarker   = "%VOTE-SIGNATURE "
+)
+
+type CertificateService struct{}
+
+func NewCertificateService() *CertificateService {
+	return &CertificateService{}
+}
+
+// signingKey 返回服务端的 Ed25519 私钥。未单独配置时拒绝签发和验证证书，
+// 不能由 JWT 密钥派生，否则使用默认 JWT 密钥的部署签发的证书可以被任何人伪造
+func signingKey() (ed25519.PrivateKey, error) {
+	cfg := config.Get()
+	if cfg.Signing.Key == "" {
+		return nil, errors.New("未配置签名密钥，无法签发或验证证书")
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.Signing.Key)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("签名密钥配置无效")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ballotDigest 按记录顺序计算全部选票的 SHA-256 摘要，逐行读取不占用大量内存
+func ballotDigest(voteID uint) (string, int, error) {
+	rows, err := database.GetDB().Model(&model.UserVote{}).
+		Select("id, user_id, option_id, weight, created_at").
+		Where("vote_id = ?", voteID).
+		Order("id").
+		Rows()
+	if err != nil {
+		return "", 0, err
+	}
+	defer rows.Close()
+
+	hash := sha256.New()
+	count := 0
+	for rows.Next() {
+		var uv model.UserVote
+		if err := rows.Scan(&uv.ID, &uv.UserID, &uv.OptionID, &uv.Weight, &uv.CreatedAt); err != nil {
+			return "", 0, err
+		}
+		fmt.Fprintf(hash, "%d|%d|%d|%.6f|%d\n", uv.ID, uv.UserID, uv.OptionID, uv.Weight, uv.CreatedAt.Unix())
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), count, nil
+}
+
+// PublicKey 返回用于验证证书的公钥
+func (s *CertificateService) PublicKey() (*dto.PublicKeyResponse, error) {
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PublicKeyResponse{
+		Algorithm: "Ed25519",
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+// buildCertificate 根据冻结的最终结果生成证书内容
+func buildCertificate(vote *model.Vote) (*dto.Certificate, error) {
+	results, err := finalResults(vote)
+	if err != nil {
+		return nil, err
+	}
+	digest, count, err := ballotDigest(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	method := MethodPlurality
+	if vote.Multi {
+		method = MethodApproval
+	}
+
+	cert := &dto.Certificate{
+		VoteID:       vote.ID,
+		Title:        vote.Title,
+		Method:       method,
+		Weighted:     results.Weighted,
+		Options:      make([]dto.CertificateOption, 0, len(results.Options)),
+		Turnout:      results.Turnout,
+		Winners:      results.Winners,
+		Tie:          results.Tie,
+		CreatedAt:    vote.CreatedAt.Unix(),
+		Deadline:     vote.Deadline,
+		IssuedAt:     time.Now().Unix(),
+		BallotCount:  count,
+		BallotDigest: digest,
+		PublicKey:    base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+	}
+	if results.FinalizedAt != nil {
+		cert.FinalizedAt = *results.FinalizedAt
+	}
+	for _, option := range results.Options {
+		pct := option.PercentOfVoters
+		if results.Weighted {
+			pct = option.PercentOfWeight
+		}
+		cert.Options = append(cert.Options, dto.CertificateOption{
+			OptionID: option.OptionID,
+			Content:  option.Content,
+			Count:    option.Count,
+			Weight:   option.Weight,
+			Percent:  pct,
+			Rank:     option.Rank,
+		})
+	}
+	return cert, nil
+}
+
+func formatTimestamp(ts int64) string {
+	if ts == 0 {
+		return "无"
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05 MST")
+}
+
+// renderCertificate 把证书内容排版为 PDF
+func renderCertificate(cert *dto.Certificate) []byte {
+	doc := utils.NewPDFDocument()
+	doc.Text(22, "投票结果证书")
+	doc.Gap(4)
+	doc.Text(15, cert.Title)
+	doc.Rule()
+
+	method := "单选，得票最多者胜"
+	if cert.Method == MethodApproval {
+		method = "多选，每个选项独立计票"
+	}
+	if cert.Weighted {
+		method += "，按权重计票"
+	}
+	info := [][2]string{
+		{"投票编号", fmt.Sprintf("%d", cert.VoteID)},
+		{"计票方式", method},
+		{"创建时间", formatTimestamp(cert.CreatedAt)},
+		{"截止时间", formatTimestamp(cert.Deadline)},
+		{"结束时间", formatTimestamp(cert.FinalizedAt)},
+		{"签发时间", formatTimestamp(cert.IssuedAt)},
+	}
+	for _, row := range info {
+		doc.Line(11, utils.PDFCell{X: 0, Text: row[0]}, utils.PDFCell{X: 80, Text: row[1]})
+	}
+	doc.Rule()
+
+	doc.Text(14, "投票结果")
+	names := make(map[uint]string, len(cert.Options))
+	for _, option := range cert.Options {
+		names[option.OptionID] = option.Content
+		amount := fmt.Sprintf("%d 票", option.Count)
+		if cert.Weighted {
+			amount = fmt.Sprintf("%d 票 / 权重 %g", option.Count, option.Weight)
+		}
+		doc.Text(11, fmt.Sprintf("第%d名  %s", option.Rank, option.Content))
+		doc.Line(10,
+			utils.PDFCell{X: 0, Text: amount},
+			utils.PDFCell{X: 440, Text: fmt.Sprintf("%.2f%%", option.Percent)},
+		)
+		// 与前端结果页相同的蓝色横条
+		doc.Bar(option.Percent/100, 0.145, 0.388, 0.922)
+	}
+	doc.Gap(6)
+
+	turnout := fmt.Sprintf("参与人数 %d（直接投票 %d，委托 %d），选择总数 %d",
+		cert.Turnout.Voters, cert.Turnout.DirectVoters, cert.Turnout.DelegatedVoters, cert.Turnout.Selections)
+	if cert.Weighted {
+		turnout += fmt.Sprintf("，总权重 %g", cert.Turnout.VoterWeight)
+	}
+	doc.Text(11, turnout)
+
+	winners := make([]string, 0, len(cert.Winners))
+	for _, id := range cert.Winners {
+		winners = append(winners, names[id])
+	}
+	switch {
+	case len(winners) == 0:
+		doc.Text(11, "获胜选项：无")
+	case cert.Tie:
+		doc.Text(11, "获胜选项（平票）："+strings.Join(winners, "、"))
+	default:
+		doc.Text(11, "获胜选项："+winners[0])
+	}
+	doc.Rule()
+
+	doc.Text(14, "选票摘要")
+	doc.Text(10, fmt.Sprintf("选票记录 %d 条，SHA-256：", cert.BallotCount))
+	doc.Text(10, cert.BallotDigest)
+	doc.Gap(6)
+	doc.Text(9, "本文件由服务器使用 Ed25519 密钥签名，可通过 POST /api/certificate/verify 验证真伪。公钥：")
+	doc.Text(9, cert.PublicKey)
+
+	return doc.Bytes()
+}
+
+// GetCertificate 生成已结束投票的签名 PDF 结果证书
+func (s *CertificateService) GetCertificate(id uint, userID uint) ([]byte, error) {
+	vote, err := loadVisibleVote(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !voteEnded(vote) {
+		return nil, errors.New("投票尚未结束")
+	}
+
+	cert, err := buildCertificate(vote)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(cert)
+	if err != nil {
+		return nil, err
+	}
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(renderCertificate(cert))
+	buf.WriteString(certificateMarker + base64.StdEncoding.EncodeToString(payload) + "\n")
+	signature := ed25519.Sign(key, buf.Bytes())
+	buf.WriteString(signatureMarker + base64.StdEncoding.EncodeToString(signature) + "\n")
+	return buf.Bytes(), nil
+}
+
+// VerifyCertificate 验证证书文件是否由本服务签发且未被修改
+func (s *CertificateService) VerifyCertificate(data []byte) (*dto.VerifyCertificateResponse, error) {
+	invalid := &dto.VerifyCertificateResponse{Valid: false}
+
+	index := bytes.LastIndex(data, []byte("\n"+signatureMarker))
+	if index < 0 {
+		return invalid, nil
+	}
+	signed := data[:index+1]
+	line := strings.TrimSpace(string(data[index+1+len(signatureMarker):]))
+	signature, err := base64.StdEncoding.DecodeString(line)
+	if err != nil {
+		return invalid, nil
+	}
+
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(key.Public().(ed25519.PublicKey), signed, signature) {
+		return invalid, nil
+	}
+
+	index = bytes.LastIndex(signed, []byte("\n"+certificateMarker))
+	if index < 0 {
+		return invalid, nil
+	}
+	line = strings.TrimSpace(string(signed[index+1+len(certificateMarker):]))
+	payload, err := base64.StdEncoding.DecodeString(line)
+	if err != nil {
+		return invalid, nil
+	}
+	var cert dto.Certificate
+	if err := json.Unmarshal(payload, &cert); err != nil {
+		return invalid, nil
+	}
+	return &dto.VerifyCertificateResponse{Valid: true, Certificate: &cert}, nil
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"errors"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type CollaboratorService struct{}
+
+func NewCollaboratorService() *CollaboratorService {
+	return &CollaboratorService{}
+}
+
+// isAdmin 判断用户是否为管理员
+func isAdmin(userID uint) bool {
+	var count int64
+	database.GetDB().Model(&model.User{}).Where("id = ? AND role = ?", userID, model.RoleAdmin).Count(&count)
+	return count > 0
+}
+
+// hasPermission 判断用户对投票是否拥有指定权限，创建者拥有全部权限
+func hasPermission(vote *model.Vote, userID uint, permission string) bool {
+	if vote.CreatorID == userID {
+		return true
+	}
+
+	var collaborator model.VoteCollaborator
+	if err := database.GetDB().Where("vote_id = ? AND user_id = ?", vote.ID, userID).First(&collaborator).Error; err != nil {
+		return false
+	}
+	return collaborator.Has(permission)
+}
+
+// userPermissions 返回用户对投票拥有的权限列表
+func userPermissions(vote *model.Vote, userID uint) []string {
+	all := []string{model.PermissionEdit, model.PermissionClose, model.PermissionViewVoters, model.PermissionExport}
+	if vote.CreatorID == userID {
+		return all
+	}
+
+	permissions := []string{}
+	var collaborator model.VoteCollaborator
+	if err := database.GetDB().Where("vote_id = ? AND user_id = ?", vote.ID, userID).First(&collaborator).Error; err != nil {
+		return permissions
+	}
+	for _, p := range all {
+		if collaborator.Has(p) {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
+
+func findVote(id uint) (*model.Vote, error) {
+	var vote model.Vote
+	if err := database.GetDB().First(&vote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投票不存在")
+		}
+		return nil, err
+	}
+	return &vote, nil
+}
+
+func findUserByUsername(username string) (*model.User, error) {
+	var user model.User
+	if err := database.GetDB().Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func usernamesByID(ids []uint) (map[uint]string, error) {
+	names := make(map[uint]string)
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	var users []model.User
+	if err := database.GetDB().Select("id", "username").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names, nil
+}
+
+func (s *CollaboratorService) GetCollaborators(voteID uint) ([]dto.CollaboratorResponse, error) {
+	var collaborators []model.VoteCollaborator
+	if err := database.GetDB().Where("vote_id = ?", voteID).Order("id").Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(collaborators))
+	for _, c := range collaborators {
+		ids = append(ids, c.UserID)
+	}
+	names, err := usernamesByID(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.CollaboratorResponse, 0, len(collaborators))
+	for _, c := range collaborators {
+		result = append(result, dto.CollaboratorResponse{
+			UserID:        c.UserID,
+			Username:      names[c.UserID],
+			CanEdit:       c.CanEdit,
+			CanClose:      c.CanClose,
+			CanViewVoters: c.CanViewVoters,
+			CanExport:     c.CanExport,
+		})
+	}
+	return result, nil
+}
+
+// SetCollaborator 添加或更新协作者权限，仅创建者可操作
+func (s *CollaboratorService) SetCollaborator(voteID uint, req *dto.CollaboratorRequest, userID uint) error {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return err
+	}
+
+	if vote.CreatorID != userID {
+		return errors.New("只有创建者可以管理协作者")
+	}
+
+	user, err := findUserByUsername(req.Username)
+	if err != nil {
+		return err
+	}
+	if user.ID == vote.CreatorID {
+		return errors.New("不能将创建者设为协作者")
+	}
+
+	var collaborator model.VoteCollaborator
+	err = database.GetDB().Where("vote_id = ? AND user_id = ?", vote.ID, user.ID).First(&collaborator).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	collaborator.VoteID = vote.ID
+	collaborator.UserID = user.ID
+	collaborator.CanEdit = req.CanEdit
+	collaborator.CanClose = req.CanClose
+	collaborator.CanViewVoters = req.CanViewVoters
+	collaborator.CanExport = req.CanExport
+
+	if err := database.GetDB().Save(&collaborator).Error; err != nil {
+		return err
+	}
+	// 权限影响结果可见性，通知实时连接重新判断
+	publishState(vote.ID, StateUpdated)
+	return nil
+}
+
+func (s *CollaboratorService) RemoveCollaborator(voteID uint, collaboratorID uint, userID uint) error {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return err
+	}
+
+	// 协作者可以自行退出
+	if vote.CreatorID != userID && collaboratorID != userID {
+		return errors.New("只有创建者可以管理协作者")
+	}
+
+	result := database.GetDB().Where("vote_id = ? AND user_id = ?", vote.ID, collaboratorID).Delete(&model.VoteCollaborator{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("协作者不存在")
+	}
+	publishState(vote.ID, StateUpdated)
+	return nil
+}
+
+// GetPendingTransfer 返回投票当前待处理的所有权转移
+func (s *CollaboratorService) GetPendingTransfer(voteID uint) (*dto.TransferResponse, error) {
+	var transfer model.VoteTransfer
+	if err := database.GetDB().Where("vote_id = ? AND status = ?", voteID, model.TransferPending).First(&transfer).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	responses, err := s.toTransferResponses([]model.VoteTransfer{transfer})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// CreateTransfer 发起所有权转移，需要接收者确认后生效
+func (s *CollaboratorService) CreateTransfer(voteID uint, req *dto.TransferRequest, userID uint) (*model.VoteTransfer, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if vote.CreatorID != userID {
+		return nil, errors.New("只有创建者可以转移所有权")
+	}
+
+	user, err := findUserByUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == userID {
+		return nil, errors.New("不能转移给自己")
+	}
+
+	tx := database.GetDB().Begin()
+
+	// 同一时间只保留一个待处理的转移
+	if err := tx.Model(&model.VoteTransfer{}).
+		Where("vote_id = ? AND status = ?", vote.ID, model.TransferPending).
+		Update("status", model.TransferCancelled).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	transfer := model.VoteTransfer{
+		VoteID:     vote.ID,
+		FromUserID: userID,
+		ToUserID:   user.ID,
+		Status:     model.TransferPending,
+	}
+	if err := tx.Create(&transfer).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (s *CollaboratorService) CancelTransfer(voteID uint, userID uint) error {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return err
+	}
+
+	if vote.CreatorID != userID {
+		return errors.New("只有创建者可以取消转移")
+	}
+
+	result := database.GetDB().Model(&model.VoteTransfer{}).
+		Where("vote_id = ? AND status = ?", vote.ID, model.TransferPending).
+		Update("status", model.TransferCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("没有待处理的转移")
+	}
+	return nil
+}
+
+func (s *CollaboratorService) findPendingTransfer(tx *gorm.DB, transferID uint, userID uint) (*model.VoteTransfer, error) {
+	var transfer model.VoteTransfer
+	if err := tx.First(&transfer, transferID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("转移请求不存在")
+		}
+		return nil, err
+	}
+
+	if transfer.ToUserID != userID {
+		return nil, errors.New("没有权限处理此转移")
+	}
+	if transfer.Status != model.TransferPending {
+		return nil, errors.New("转移请求已处理")
+	}
+	return &transfer, nil
+}
+
+// AcceptTransfer 接收者确认转移，原创建者保留全部权限的协作者身份
+func (s *CollaboratorService) AcceptTransfer(transferID uint, userID uint) error {
+	tx := database.GetDB().Begin()
+
+	transfer, err := s.findPendingTransfer(tx, transferID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var vote model.Vote
+	if err := tx.First(&vote, transfer.VoteID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("投票不存在")
+		}
+		return err
+	}
+
+	// 发起后创建者已变更，转移失效
+	if vote.CreatorID != transfer.FromUserID {
+		tx.Rollback()
+		return errors.New("转移请求已失效")
+	}
+
+	if err := tx.Model(&vote).Update("creator_id", userID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 新创建者不再需要协作者记录
+	if err := tx.Where("vote_id = ? AND user_id = ?", vote.ID, userID).Delete(&model.VoteCollaborator{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	previous := model.VoteCollaborator{
+		VoteID:        vote.ID,
+		UserID:        transfer.FromUserID,
+		CanEdit:       true,
+		CanClose:      true,
+		CanViewVoters: true,
+		CanExport:     true,
+	}
+	if err := tx.Create(&previous).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(transfer).Update("status", model.TransferAccepted).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	publishState(vote.ID, StateUpdated)
+	return nil
+}
+
+func (s *CollaboratorService) RejectTransfer(transferID uint, userID uint) error {
+	transfer, err := s.findPendingTransfer(database.GetDB(), transferID, userID)
+	if err != nil {
+		return err
+	}
+
+	return database.GetDB().Model(transfer).Update("status", model.TransferRejected).Error
+}
+
+// GetIncomingTransfers 返回发给当前用户的待处理转移
+func (s *CollaboratorService) GetIncomingTransfers(userID uint) ([]dto.TransferResponse, error) {
+	var transfers []model.VoteTransfer
+	if err := database.GetDB().Where("to_user_id = ? AND status = ?", userID, model.TransferPending).Order("id DESC").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return s.toTransferResponses(transfers)
+}
+
+func (s *CollaboratorService) toTransferResponses(transfers []model.VoteTransfer) ([]dto.TransferResponse, error) {
+	userIDs := make([]uint, 0, len(transfers)*2)
+	voteIDs := make([]uint, 0, len(transfers))
+	for _, t := range transfers {
+		userIDs = append(userIDs, t.FromUserID, t.ToUserID)
+		voteIDs = append(voteIDs, t.VoteID)
+	}
+
+	names, err := usernamesByID(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[uint]string)
+	if len(voteIDs) > 0 {
+		var votes []model.Vote
+		if err := database.GetDB().Select("id", "title").Where("id IN ?", voteIDs).Find(&votes).Error; err != nil {
+			return nil, err
+		}
+		for _, v := range votes {
+			titles[v.ID] = v.Title
+		}
+	}
+
+	result := make([]dto.TransferResponse, 0, len(transfers))
+	for _, t := range transfers {
+		result = append(result, dto.TransferResponse{
+			ID:           t.ID,
+			VoteID:       t.VoteID,
+			VoteTitle:    titles[t.VoteID],
+			FromUserID:   t.FromUserID,
+			FromUsername: names[t.FromUserID],
+			ToUserID:     t.ToUserID,
+			ToUsername:   names[t.ToUserID],
+			Status:       t.Status,
+			CreatedAt:    t.CreatedAt,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,470 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type CommentService struct{}
+
+func NewCommentService() *CommentService {
+	return &CommentService{}
+}
+
+// mentionPattern 匹配评论中的 @用户名，用户名只能包含字母、数字、下划线、点和连字符
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_.\-]+)`)
+
+// maxMentionNotifications 每条评论（包括修改）最多发出的提及通知数，超出的提及不再通知
+const maxMentionNotifications = 10
+
+func findComment(id uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := database.GetDB().First(&comment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("评论不存在")
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// mentionedUsers 返回内容中提到的用户 ID，不存在的用户名忽略
+func mentionedUsers(content string) ([]uint, error) {
+	var names []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		// 句末的点不属于用户名
+		if name := strings.TrimRight(match[1], ".-"); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var ids []uint
+	if err := database.GetDB().Model(&model.User{}).Where("username IN ?", names).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// notifyMentions 通知评论中新提到的用户。修改评论时 previous 为修改前的内容，已经提到过的用户不再通知。
+// 只通知注册用户（访客不能查看投票），每条评论的通知数不超过 maxMentionNotifications
+func notifyMentions(tx *gorm.DB, comment *model.Comment, previous string) error {
+	mentioned, err := mentionedUsers(comment.Content)
+	if err != nil {
+		return err
+	}
+	if mentioned, err = registeredUsers(mentioned); err != nil {
+		return err
+	}
+	notified, err := mentionedUsers(previous)
+	if err != nil {
+		return err
+	}
+	skip := map[uint]bool{comment.UserID: true}
+	for _, id := range notified {
+		skip[id] = true
+	}
+
+	var sent int64
+	if err := tx.Model(&model.Notification{}).Where("comment_id = ? AND type = ?", comment.ID, model.NotificationMention).Count(&sent).Error; err != nil {
+		return err
+	}
+
+	for _, id := range mentioned {
+		if sent >= maxMentionNotifications {
+			break
+		}
+		if skip[id] {
+			continue
+		}
+		skip[id] = true
+		sent++
+		notification := model.Notification{
+			UserID:    id,
+			Type:      model.NotificationMention,
+			ActorID:   comment.UserID,
+			VoteID:    comment.VoteID,
+			CommentID: comment.ID,
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commentBadges 返回公开了投票的评论作者的选择。当前用户不能查看该投票的结果时不显示，
+// 以免通过评论推断出尚未公开的结果
+func commentBadges(vote *model.Vote, comments []model.Comment, userID uint) (map[uint]*dto.CommentBadge, error) {
+	badges := make(map[uint]*dto.CommentBadge)
+	var authors []uint
+	for _, comment := range comments {
+		if comment.RevealVote && comment.Visible() {
+			authors = append(authors, comment.UserID)
+		}
+	}
+	if len(authors) == 0 || !canViewResults(vote, userID) {
+		return badges, nil
+	}
+
+	var options []model.VoteOption
+	if err := database.GetDB().Where("vote_id = ?", vote.ID).Find(&options).Error; err != nil {
+		return nil, err
+	}
+	contents := make(map[uint]string, len(options))
+	for _, option := range options {
+		contents[option.ID] = option.Content
+	}
+
+	var userVotes []model.UserVote
+	if err := database.GetDB().Where("vote_id = ? AND user_id IN ?", vote.ID, authors).Order("id").Find(&userVotes).Error; err != nil {
+		return nil, err
+	}
+	for _, uv := range userVotes {
+		badge, ok := badges[uv.UserID]
+		if !ok {
+			badge = &dto.CommentBadge{OptionIDs: []uint{}, Options: []string{}}
+			badges[uv.UserID] = badge
+		}
+		badge.OptionIDs = append(badge.OptionIDs, uv.OptionID)
+		badge.Options = append(badge.Options, contents[uv.OptionID])
+	}
+	return badges, nil
+}
+
+// buildThreads 把评论组装成讨论串，返回 roots 对应的回复树。
+// 已删除或移除且没有回复的评论不显示，有回复时保留占位
+func buildThreads(vote *model.Vote, roots []model.Comment, replies []model.Comment, userID uint) ([]dto.CommentResponse, error) {
+	all := append(append([]model.Comment{}, roots...), replies...)
+	ids := make([]uint, 0, len(all))
+	for _, comment := range all {
+		ids = append(ids, comment.UserID)
+	}
+	names, err := usernamesByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	badges, err := commentBadges(vote, all, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]model.Comment)
+	for _, reply := range replies {
+		if reply.ParentID != nil {
+			children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+		}
+	}
+
+	var build func(comment *model.Comment) *dto.CommentResponse
+	build = func(comment *model.Comment) *dto.CommentResponse {
+		resp := &dto.CommentResponse{
+			ID:        comment.ID,
+			VoteID:    comment.VoteID,
+			ParentID:  comment.ParentID,
+			UserID:    comment.UserID,
+			Username:  names[comment.UserID],
+			Pinned:    comment.Pinned,
+			Deleted:   comment.Deleted,
+			Removed:   comment.RemovedBy != nil,
+			EditedAt:  comment.EditedAt,
+			CreatedAt: comment.CreatedAt,
+			Replies:   []dto.CommentResponse{},
+		}
+		if comment.Visible() {
+			resp.Content = comment.Content
+			if comment.RevealVote {
+				resp.Badge = badges[comment.UserID]
+			}
+		}
+		for i := range children[comment.ID] {
+			if reply := build(&children[comment.ID][i]); reply != nil {
+				resp.Replies = append(resp.Replies, *reply)
+			}
+		}
+		if !comment.Visible() && len(resp.Replies) == 0 {
+			return nil
+		}
+		return resp
+	}
+
+	threads := []dto.CommentResponse{}
+	for i := range roots {
+		if thread := build(&roots[i]); thread != nil {
+			threads = append(threads, *thread)
+		}
+	}
+	return threads, nil
+}
+
+// threadReplies 返回讨论串中的全部回复
+func threadReplies(roots []model.Comment) ([]model.Comment, error) {
+	replies := []model.Comment{}
+	if len(roots) == 0 {
+		return replies, nil
+	}
+	ids := make([]uint, 0, len(roots))
+	for _, root := range roots {
+		ids = append(ids, root.ID)
+	}
+	if err := database.GetDB().Where("root_id IN ?", ids).Order("id").Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	return replies, nil
+}
+
+// GetComments 分页返回投票的讨论串，最早的在前。置顶的讨论串按置顶时间倒序在第一页单独返回
+func (s *CommentService) GetComments(voteID uint, query *dto.PageQuery, userID uint) (*dto.CommentList, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !isRegisteredUser(userID) {
+		return nil, errors.New("没有权限查看此投票")
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	// 已删除的顶层评论只在还有未删除的回复时显示
+	roots := func() *gorm.DB {
+		return database.GetDB().Model(&model.Comment{}).
+			Where("vote_id = ? AND parent_id IS NULL", vote.ID).
+			Where("(deleted = ? AND removed_by IS NULL) OR id IN (?)", false,
+				database.GetDB().Model(&model.Comment{}).Select("root_id").Where("vote_id = ? AND root_id IS NOT NULL AND deleted = ? AND removed_by IS NULL", vote.ID, false))
+	}
+
+	list := &dto.CommentList{Pinned: []dto.CommentResponse{}, Items: []dto.CommentResponse{}}
+	if err := roots().Where("pinned = ?", false).Count(&list.Total).Error; err != nil {
+		return nil, err
+	}
+
+	if query.Cursor == "" {
+		var pinned []model.Comment
+		if err := roots().Where("pinned = ?", true).Order("pinned_at DESC").Find(&pinned).Error; err != nil {
+			return nil, err
+		}
+		replies, err := threadReplies(pinned)
+		if err != nil {
+			return nil, err
+		}
+		if list.Pinned, err = buildThreads(vote, pinned, replies, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	db := roots().Where("pinned = ?", false)
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("id > ?", cursor.ID)
+	}
+	var page []model.Comment
+	if err := db.Order("id").Limit(limit + 1).Find(&page).Error; err != nil {
+		return nil, err
+	}
+	if len(page) > limit {
+		page = page[:limit]
+		list.HasMore = true
+		list.NextCursor = listCursor{ID: page[limit-1].ID}.encode()
+	}
+	replies, err := threadReplies(page)
+	if err != nil {
+		return nil, err
+	}
+	if list.Items, err = buildThreads(vote, page, replies, userID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// CreateComment 发表评论或回复，并通知评论中提到的用户
+func (s *CommentService) CreateComment(voteID uint, req *dto.CreateCommentRequest, userID uint) (*dto.CommentResponse, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !isRegisteredUser(userID) {
+		return nil, errors.New("没有权限查看此投票")
+	}
+
+	comment := model.Comment{
+		VoteID:     vote.ID,
+		UserID:     userID,
+		Content:    strings.TrimSpace(req.Content),
+		RevealVote: req.RevealVote,
+	}
+	if comment.Content == "" {
+		return nil, errors.New("评论内容不能为空")
+	}
+	if req.ParentID != nil {
+		parent, err := findComment(*req.ParentID)
+		if err != nil || parent.VoteID != vote.ID {
+			return nil, errors.New("回复的评论不存在")
+		}
+		if !parent.Visible() {
+			return nil, errors.New("不能回复已删除的评论")
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = &parent.ID
+		}
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Create(&comment).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(&model.Vote{}).Where("id = ?", vote.ID).UpdateColumn("comment_count", gorm.Expr("comment_count + 1")).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := notifyMentions(tx, &comment, ""); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	threads, err := buildThreads(vote, []model.Comment{comment}, nil, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &threads[0], nil
+}
+
+// UpdateComment 修改自己的评论，只通知新提到的用户
+func (s *CommentService) UpdateComment(id uint, req *dto.UpdateCommentRequest, userID uint) error {
+	comment, err := findComment(id)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID {
+		return errors.New("只能修改自己的评论")
+	}
+	if !comment.Visible() {
+		return errors.New("评论已删除")
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return errors.New("评论内容不能为空")
+	}
+	previous := comment.Content
+	now := time.Now()
+	comment.Content = content
+	comment.RevealVote = req.RevealVote
+	comment.EditedAt = &now
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Model(comment).Updates(map[string]interface{}{
+		"content":     comment.Content,
+		"reveal_vote": comment.RevealVote,
+		"edited_at":   comment.EditedAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := notifyMentions(tx, comment, previous); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteComment 作者删除自己的评论，或由可编辑投票的创建者和协作者移除他人的评论。
+// 有回复的评论在讨论串中保留占位
+func (s *CommentService) DeleteComment(id uint, userID uint) error {
+	comment, err := findComment(id)
+	if err != nil {
+		return err
+	}
+	if !comment.Visible() {
+		return errors.New("评论已删除")
+	}
+
+	updates := map[string]interface{}{"pinned": false, "pinned_at": nil}
+	if comment.UserID == userID {
+		updates["deleted"] = true
+		updates["content"] = ""
+	} else {
+		vote, err := findVote(comment.VoteID)
+		if err != nil {
+			return err
+		}
+		if !hasPermission(vote, userID, model.PermissionEdit) {
+			return errors.New("没有权限删除此评论")
+		}
+		// 移除的内容保留在数据库中，便于追溯
+		updates["removed_by"] = userID
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	// 只有一个请求能删除成功，评论数不会重复扣减
+	result := tx.Model(&model.Comment{}).Where("id = ? AND deleted = ? AND removed_by IS NULL", comment.ID, false).Updates(updates)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("评论已删除")
+	}
+	if err := tx.Model(&model.Vote{}).Where("id = ?", comment.VoteID).UpdateColumn("comment_count", gorm.Expr("comment_count - 1")).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// PinComment 置顶或取消置顶顶层评论，需要投票的编辑权限
+func (s *CommentService) PinComment(id uint, pinned bool, userID uint) error {
+	comment, err := findComment(id)
+	if err != nil {
+		return err
+	}
+	vote, err := findVote(comment.VoteID)
+	if err != nil {
+		return err
+	}
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		return errors.New("没有权限置顶评论")
+	}
+	if comment.ParentID != nil {
+		return errors.New("只能置顶顶层评论")
+	}
+	if pinned && !comment.Visible() {
+		return errors.New("评论已删除")
+	}
+
+	var pinnedAt *time.Time
+	if pinned {
+		now := time.Now()
+		pinnedAt = &now
+	}
+	return database.GetDB().Model(comment).Updates(map[string]interface{}{"pinned": pinned, "pinned_at": pinnedAt}).Error
+}
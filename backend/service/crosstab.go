@@ -0,0 +1,237 @@
+package service
+
+import (
+	"sort"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// crosstabMinCell 交叉统计的最小单元格人数，小于该值的单元格和分组会被抑制，防止识别出个人
+const crosstabMinCell = 5
+
+type crosstabRow struct {
+	Value    *string
+	OptionID uint
+	Count    int
+}
+
+type crosstabVoterRow struct {
+	Value  *string
+	Voters int
+}
+
+type crosstabGroup struct {
+	value  string
+	other  bool
+	merged int
+	voters int
+	counts []int
+}
+
+// poolSmallGroups 把人数不足的分组合并为“其他”。合并后仍不足时继续并入最小的分组，
+// 保证每个展示的分组都不少于 minCell 人，用公开的总票数相减也无法得到小分组的结果
+func poolSmallGroups(groups []*crosstabGroup, options int, minCell int) []*crosstabGroup {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].voters < groups[j].voters })
+
+	var small, large []*crosstabGroup
+	for _, g := range groups {
+		if g.voters < minCell {
+			small = append(small, g)
+		} else {
+			large = append(large, g)
+		}
+	}
+	if len(small) == 0 {
+		return groups
+	}
+
+	other := &crosstabGroup{other: true, counts: make([]int, options)}
+	merge := func(g *crosstabGroup) {
+		other.merged++
+		other.voters += g.voters
+		for i, c := range g.counts {
+			other.counts[i] += c
+		}
+	}
+	for _, g := range small {
+		merge(g)
+	}
+	for other.voters < minCell && len(large) > 0 {
+		merge(large[0])
+		large = large[1:]
+	}
+	return append(large, other)
+}
+
+// suppressCells 抑制人数不足的单元格，并在行或列中只有一个被抑制的单元格时补充抑制
+// 最小的另一个单元格，避免通过分组人数或公开的选项总票数反推
+func suppressCells(groups []*crosstabGroup, options int, minCell int) [][]bool {
+	suppressed := make([][]bool, len(groups))
+	for r, g := range groups {
+		suppressed[r] = make([]bool, options)
+		for j, c := range g.counts {
+			suppressed[r][j] = c > 0 && c < minCell
+		}
+	}
+
+	// complement 在给定的单元格中只有一个被抑制时，补充抑制其余单元格中最小的一个
+	complement := func(cells [][2]int) bool {
+		count := 0
+		best := -1
+		for i, cell := range cells {
+			if suppressed[cell[0]][cell[1]] {
+				count++
+				continue
+			}
+			if best < 0 || groups[cell[0]].counts[cell[1]] < groups[cells[best][0]].counts[cells[best][1]] {
+				best = i
+			}
+		}
+		if count != 1 || best < 0 {
+			return false
+		}
+		suppressed[cells[best][0]][cells[best][1]] = true
+		return true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for r := range groups {
+			cells := make([][2]int, options)
+			for j := 0; j < options; j++ {
+				cells[j] = [2]int{r, j}
+			}
+			changed = complement(cells) || changed
+		}
+		for j := 0; j < options; j++ {
+			cells := make([][2]int, len(groups))
+			for r := range groups {
+				cells[r] = [2]int{r, j}
+			}
+			changed = complement(cells) || changed
+		}
+	}
+	return suppressed
+}
+
+// crosstabCounts 按属性值统计每个分组的投票人数和各选项票数，未填写属性的投票人归入空字符串分组
+func crosstabCounts(vote *model.Vote, attribute string) (map[string]*crosstabGroup, error) {
+	db := database.GetDB()
+	base := func() *gorm.DB {
+		return db.Table("user_votes AS uv").
+			Joins("LEFT JOIN ballot_attributes ba ON ba.vote_id = uv.vote_id AND ba.user_id = uv.user_id AND ba.name = ?", attribute).
+			Where("uv.vote_id = ?", vote.ID)
+	}
+
+	var rows []crosstabRow
+	if err := base().Select("ba.value, uv.option_id, COUNT(*) AS count").Group("ba.value, uv.option_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	var voterRows []crosstabVoterRow
+	if err := base().Select("ba.value, COUNT(DISTINCT uv.user_id) AS voters").Group("ba.value").Scan(&voterRows).Error; err != nil {
+		return nil, err
+	}
+
+	optionIndex := make(map[uint]int, len(vote.Options))
+	for i, option := range vote.Options {
+		optionIndex[option.ID] = i
+	}
+
+	byValue := make(map[string]*crosstabGroup)
+	group := func(value *string) *crosstabGroup {
+		v := ""
+		if value != nil {
+			v = *value
+		}
+		g, ok := byValue[v]
+		if !ok {
+			g = &crosstabGroup{value: v, merged: 1, counts: make([]int, len(vote.Options))}
+			byValue[v] = g
+		}
+		return g
+	}
+	for _, row := range voterRows {
+		group(row.Value).voters += row.Voters
+	}
+	for _, row := range rows {
+		if i, ok := optionIndex[row.OptionID]; ok {
+			group(row.Value).counts[i] += row.Count
+		}
+	}
+	return byValue, nil
+}
+
+// GetCrosstab 按投票时记录的投票人属性交叉统计各选项票数，只统计直接投票
+func (s *VoteService) GetCrosstab(id uint, attribute string, userID uint) (*dto.CrosstabResponse, error) {
+	vote, err := loadVisibleVote(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := findAttribute(attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	byValue, err := crosstabCounts(vote, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.CrosstabResponse{
+		VoteID:      vote.ID,
+		Attribute:   def.Name,
+		Label:       def.Label,
+		MinCellSize: crosstabMinCell,
+		Options:     make([]dto.CrosstabOption, 0, len(vote.Options)),
+		Groups:      []dto.CrosstabGroup{},
+	}
+	for _, option := range vote.Options {
+		resp.Options = append(resp.Options, dto.CrosstabOption{OptionID: option.ID, Content: option.Content})
+	}
+
+	groups := make([]*crosstabGroup, 0, len(byValue))
+	for _, g := range byValue {
+		groups = append(groups, g)
+	}
+	groups = poolSmallGroups(groups, len(vote.Options), crosstabMinCell)
+
+	// 按属性值排序，未填写和合并的分组排在最后
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.other != b.other {
+			return b.other
+		}
+		if (a.value == "") != (b.value == "") {
+			return b.value == ""
+		}
+		return a.value < b.value
+	})
+
+	suppressed := suppressCells(groups, len(vote.Options), crosstabMinCell)
+	for r, g := range groups {
+		out := dto.CrosstabGroup{
+			Value:  g.value,
+			Other:  g.other,
+			Merged: g.merged,
+			Voters: g.voters,
+			Counts: make([]*int, len(vote.Options)),
+		}
+		if g.other {
+			out.Value = ""
+		}
+		for j := range g.counts {
+			if suppressed[r][j] {
+				resp.Suppressed++
+				continue
+			}
+			count := g.counts[j]
+			out.Counts[j] = &count
+		}
+		resp.Groups = append(resp.Groups, out)
+	}
+	return resp, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSuppressCells(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts [][]int
+		want   [][]bool
+	}{
+		{
+			name:   "nothing small",
+			counts: [][]int{{10, 12}, {8, 9}},
+			want:   [][]bool{{false, false}, {false, false}},
+		},
+		{
+			name:   "zero cells are not suppressed",
+			counts: [][]int{{0, 10}, {6, 0}},
+			want:   [][]bool{{false, false}, {false, false}},
+		},
+		{
+			name:   "single small cell gets complements in its row and column",
+			counts: [][]int{{10, 3, 8}, {7, 9, 6}},
+			want:   [][]bool{{false, true, true}, {false, true, true}},
+		},
+		{
+			name:   "two small cells in a row need no complement there",
+			counts: [][]int{{2, 3, 20}, {2, 3, 20}},
+			want:   [][]bool{{true, true, false}, {true, true, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := make([]*crosstabGroup, len(tt.counts))
+			for i, counts := range tt.counts {
+				groups[i] = &crosstabGroup{counts: counts}
+			}
+			got := suppressCells(groups, len(tt.counts[0]), crosstabMinCell)
+			for r := range got {
+				if !slices.Equal(got[r], tt.want[r]) {
+					t.Errorf("row %d = %v, want %v", r, got[r], tt.want[r])
+				}
+			}
+
+			// 任何行或列都不能只有一个被抑制的单元格
+			for r := range got {
+				if n := countTrue(got[r]); n == 1 {
+					t.Errorf("row %d has a single suppressed cell", r)
+				}
+			}
+			for j := range got[0] {
+				column := make([]bool, len(got))
+				for r := range got {
+					column[r] = got[r][j]
+				}
+				if n := countTrue(column); n == 1 {
+					t.Errorf("column %d has a single suppressed cell", j)
+				}
+			}
+		})
+	}
+}
+
+func countTrue(values []bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPoolSmallGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		voters []int
+		want   []int // 合并后各分组的人数，最后一个为“其他”时标记为负数
+	}{
+		{"no small groups", []int{5, 8}, []int{5, 8}},
+		{"small groups pooled", []int{2, 3, 10}, []int{10, -5}},
+		{"pool still small takes the smallest large group", []int{1, 6, 9}, []int{9, -7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := make([]*crosstabGroup, len(tt.voters))
+			for i, v := range tt.voters {
+				groups[i] = &crosstabGroup{voters: v, counts: []int{v}}
+			}
+			pooled := poolSmallGroups(groups, 1, crosstabMinCell)
+			got := make([]int, len(pooled))
+			for i, g := range pooled {
+				got[i] = g.voters
+				if g.other {
+					got[i] = -g.voters
+				}
+				if g.voters < crosstabMinCell {
+					t.Errorf("group with %d voters is below the minimum", g.voters)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("poolSmallGroups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
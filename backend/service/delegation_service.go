@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type DelegationService struct{}
+
+func NewDelegationService() *DelegationService {
+	return &DelegationService{}
+}
+
+// SetDelegation 设置委托，同一标签下重复设置会覆盖之前的受托人
+func (s *DelegationService) SetDelegation(req *dto.DelegationRequest, userID uint) (*model.Delegation, error) {
+	delegate, err := findUserByUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if delegate.ID == userID {
+		return nil, errors.New("不能委托给自己")
+	}
+
+	tag := strings.TrimSpace(req.Tag)
+
+	var delegation model.Delegation
+	err = database.GetDB().Where("delegator_id = ? AND tag = ?", userID, tag).First(&delegation).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	delegation.DelegatorID = userID
+	delegation.DelegateID = delegate.ID
+	delegation.Tag = tag
+
+	if err := database.GetDB().Save(&delegation).Error; err != nil {
+		return nil, err
+	}
+	return &delegation, nil
+}
+
+func (s *DelegationService) DeleteDelegation(id uint, userID uint) error {
+	var delegation model.Delegation
+	if err := database.GetDB().First(&delegation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("委托不存在")
+		}
+		return err
+	}
+
+	if delegation.DelegatorID != userID {
+		return errors.New("没有权限删除此委托")
+	}
+
+	return database.GetDB().Delete(&delegation).Error
+}
+
+// GetMyDelegations 返回我委托出去的和委托给我的记录
+func (s *DelegationService) GetMyDelegations(userID uint) (*dto.MyDelegationsResponse, error) {
+	var outgoing, incoming []model.Delegation
+	if err := database.GetDB().Where("delegator_id = ?", userID).Order("tag").Find(&outgoing).Error; err != nil {
+		return nil, err
+	}
+	if err := database.GetDB().Where("delegate_id = ?", userID).Order("id").Find(&incoming).Error; err != nil {
+		return nil, err
+	}
+
+	ids := []uint{}
+	for _, d := range append(outgoing, incoming...) {
+		ids = append(ids, d.DelegatorID, d.DelegateID)
+	}
+	names, err := usernamesByID(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	convert := func(delegations []model.Delegation) []dto.DelegationResponse {
+		result := make([]dto.DelegationResponse, 0, len(delegations))
+		for _, d := range delegations {
+			result = append(result, dto.DelegationResponse{
+				ID:                d.ID,
+				DelegatorID:       d.DelegatorID,
+				DelegatorUsername: names[d.DelegatorID],
+				DelegateID:        d.DelegateID,
+				DelegateUsername:  names[d.DelegateID],
+				Tag:               d.Tag,
+				CreatedAt:         d.CreatedAt,
+			})
+		}
+		return result
+	}
+
+	return &dto.MyDelegationsResponse{
+		Outgoing: convert(outgoing),
+		Incoming: convert(incoming),
+	}, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	discoverRefreshInterval = time.Minute
+	discoverCacheSize       = 200            // 每种推荐缓存的投票数
+	trendingWindow          = 48 * time.Hour // 只统计该时间内的投票
+	trendingHalfLife        = 6 * time.Hour  // 一张选票对热度的贡献每经过半衰期减半
+)
+
+// DiscoverResult 发现页的一页推荐
+type DiscoverResult struct {
+	Feed        string       `json:"feed"`
+	Items       []model.Vote `json:"items"`
+	Total       int64        `json:"total"`
+	NextCursor  string       `json:"next_cursor"`
+	HasMore     bool         `json:"has_more"`
+	RefreshedAt time.Time    `json:"refreshed_at"` // 排名的计算时间
+}
+
+// discoverCache 定期计算的推荐排名，请求时只按用户过滤，不重新排名
+type discoverCache struct {
+	mu          sync.RWMutex
+	feeds       map[string][]uint
+	refreshedAt time.Time
+}
+
+var discover = &discoverCache{}
+
+// StartDiscoverRefresher 启动后台协程定期刷新发现页排名
+func StartDiscoverRefresher() {
+	go func() {
+		if err := refreshDiscover(); err != nil {
+			log.Println("发现页排名刷新失败:", err)
+		}
+
+		ticker := time.NewTicker(discoverRefreshInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := refreshDiscover(); err != nil {
+				log.Println("发现页排名刷新失败:", err)
+			}
+		}
+	}()
+}
+
+// publicOpenVotes 进行中的公开投票。关联了权重名单的投票只对名单中的用户开放，问卷中的选择题只在问卷中出现，都不参与推荐
+func publicOpenVotes(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Where("votes.deleted_at IS NULL AND votes.weight_set_id IS NULL AND votes.survey_id IS NULL").
+		Where("votes.closed = ? AND (votes.deadline = 0 OR votes.deadline >= ?)", false, now.Unix())
+}
+
+// refreshDiscover 重新计算各推荐的排名
+func refreshDiscover() error {
+	now := time.Now()
+	feeds := make(map[string][]uint, 3)
+
+	// 热度为窗口内每位投票人的贡献之和，贡献随投票时间按半衰期指数衰减。
+	// 启用差分隐私的投票不公开参与人数，不参与热度排名
+	ballots := database.GetDB().Model(&model.UserVote{}).
+		Select("vote_id, user_id, MIN(created_at) AS voted_at").
+		Where("created_at >= ?", now.Add(-trendingWindow)).
+		Group("vote_id, user_id")
+	var trending []struct {
+		VoteID uint
+	}
+	err := publicOpenVotes(database.GetDB().Table("(?) AS ballots", ballots), now).
+		Select("ballots.vote_id, SUM(POW(0.5, TIMESTAMPDIFF(SECOND, ballots.voted_at, ?) / ?)) AS score", now, trendingHalfLife.Seconds()).
+		Joins("JOIN votes ON votes.id = ballots.vote_id").
+		Where("votes.id NOT IN (?)", database.GetDB().Model(&model.PrivacyBudget{}).Select("vote_id")).
+		Group("ballots.vote_id").
+		Order("score DESC, ballots.vote_id DESC").
+		Limit(discoverCacheSize).
+		Scan(&trending).Error
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(trending))
+	for _, row := range trending {
+		ids = append(ids, row.VoteID)
+	}
+	feeds[dto.FeedTrending] = ids
+
+	var ending []uint
+	if err := publicOpenVotes(database.GetDB().Model(&model.Vote{}), now).
+		Where("votes.deadline > 0").
+		Order("votes.deadline ASC, votes.id ASC").
+		Limit(discoverCacheSize).
+		Pluck("votes.id", &ending).Error; err != nil {
+		return err
+	}
+	feeds[dto.FeedEnding] = ending
+
+	var newest []uint
+	if err := publicOpenVotes(database.GetDB().Model(&model.Vote{}), now).
+		Order("votes.id DESC").
+		Limit(discoverCacheSize).
+		Pluck("votes.id", &newest).Error; err != nil {
+		return err
+	}
+	feeds[dto.FeedNew] = newest
+
+	discover.mu.Lock()
+	discover.feeds = feeds
+	discover.refreshedAt = now
+	discover.mu.Unlock()
+	return nil
+}
+
+// discoverFeed 返回缓存的排名，后台协程还未完成首次刷新时当场计算
+func discoverFeed(feed string) ([]uint, time.Time, error) {
+	discover.mu.RLock()
+	ids, refreshedAt := discover.feeds[feed], discover.refreshedAt
+	discover.mu.RUnlock()
+	if !refreshedAt.IsZero() {
+		return ids, refreshedAt, nil
+	}
+
+	if err := refreshDiscover(); err != nil {
+		return nil, time.Time{}, err
+	}
+	discover.mu.RLock()
+	defer discover.mu.RUnlock()
+	return discover.feeds[feed], discover.refreshedAt, nil
+}
+
+// Discover 分页返回发现页推荐，排除自己已投过票和排名计算后已结束的投票。
+// 翻页期间排名刷新时，相邻两页可能出现重复或遗漏
+func (s *VoteService) Discover(query *dto.DiscoverQuery, userID uint) (*DiscoverResult, error) {
+	feed := query.Feed
+	if feed == "" {
+		feed = dto.FeedTrending
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	offset := 0
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = int(cursor.Key)
+	}
+
+	ranked, refreshedAt, err := discoverFeed(feed)
+	if err != nil {
+		return nil, err
+	}
+	result := &DiscoverResult{Feed: feed, Items: []model.Vote{}, RefreshedAt: refreshedAt}
+	if len(ranked) == 0 {
+		return result, nil
+	}
+
+	var remaining []uint
+	err = publicOpenVotes(database.GetDB().Model(&model.Vote{}), time.Now()).
+		Where("votes.id IN ?", ranked).
+		Where("votes.id NOT IN (?)", database.GetDB().Model(&model.UserVote{}).Select("vote_id").Where("user_id = ?", userID)).
+		Pluck("votes.id", &remaining).Error
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[uint]bool, len(remaining))
+	for _, id := range remaining {
+		keep[id] = true
+	}
+	ids := make([]uint, 0, len(remaining))
+	for _, id := range ranked {
+		if keep[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	result.Total = int64(len(ids))
+	if offset >= len(ids) {
+		return result, nil
+	}
+	ids = ids[offset:]
+	if len(ids) > limit {
+		ids = ids[:limit]
+		result.HasMore = true
+		result.NextCursor = listCursor{Key: int64(offset + limit)}.encode()
+	}
+
+	var votes []model.Vote
+	if err := database.GetDB().Preload("Options").Preload("Tags").Where("id IN ?", ids).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Vote, len(votes))
+	for _, vote := range votes {
+		byID[vote.ID] = vote
+	}
+	for _, id := range ids {
+		if vote, ok := byID[id]; ok {
+			result.Items = append(result.Items, vote)
+		}
+	}
+
+	if err := maskResults(result.Items, userID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/pubsub"
+
+	"gorm.io/gorm"
+)
+
+// 投票状态
+const (
+	StateOpen    = "open"
+	StateClosed  = "closed"
+	StateUpdated = "updated"
+	StateDeleted = "deleted"
+)
+
+// voteTurnout 返回投票的去重投票人数
+func voteTurnout(voteID uint) (int64, error) {
+	var turnout int64
+	err := database.GetDB().Model(&model.UserVote{}).Where("vote_id = ?", voteID).Distinct("user_id").Count(&turnout).Error
+	return turnout, err
+}
+
+// publishBallot 在选票提交后推送计票增量
+func publishBallot(voteID, voterID uint, optionIDs []uint, weight float64) {
+	turnout, err := voteTurnout(voteID)
+	if err != nil {
+		return
+	}
+
+	event := dto.BallotEvent{
+		VoteID:  voteID,
+		VoterID: voterID,
+		Turnout: turnout,
+		Deltas:  make([]dto.OptionDelta, 0, len(optionIDs)),
+	}
+	for _, optionID := range optionIDs {
+		event.Deltas = append(event.Deltas, dto.OptionDelta{
+			OptionID: optionID,
+			Count:    1,
+			Weight:   weight,
+		})
+	}
+
+	pubsub.GetBroker().Publish(pubsub.VoteTopic(voteID), pubsub.Event{
+		Type: pubsub.EventBallot,
+		Data: event,
+	})
+}
+
+// publishState 推送投票状态变化
+func publishState(voteID uint, state string) {
+	pubsub.GetBroker().Publish(pubsub.VoteTopic(voteID), pubsub.Event{
+		Type: pubsub.EventState,
+		Data: dto.StateEvent{VoteID: voteID, State: state},
+	})
+}
+
+// GetLiveSnapshot 返回实时连接建立时的当前结果，不可见时不包含票数和参与人数
+func (s *VoteService) GetLiveSnapshot(id uint, userID uint) (*dto.LiveSnapshot, error) {
+	var vote model.Vote
+	if err := database.GetDB().Preload("Options").First(&vote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投票不存在")
+		}
+		return nil, err
+	}
+
+	turnout, err := voteTurnout(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := canViewResults(&vote, userID)
+	if !visible {
+		hideResults(&vote)
+		turnout = 0
+	}
+
+	snapshot := &dto.LiveSnapshot{
+		VoteID:         vote.ID,
+		Title:          vote.Title,
+		Deadline:       vote.Deadline,
+		Closed:         voteEnded(&vote),
+		Turnout:        turnout,
+		ResultsVisible: visible,
+		Options:        make([]dto.LiveSnapshotOption, 0, len(vote.Options)),
+	}
+	for _, option := range vote.Options {
+		snapshot.Options = append(snapshot.Options, dto.LiveSnapshotOption{
+			OptionID:      option.ID,
+			Content:       option.Content,
+			Count:         option.Count,
+			WeightedCount: option.WeightedCount,
+		})
+	}
+	return snapshot, nil
+}
+
+// GetDeadline 返回投票是否已关闭及截止时间，实时连接收到状态变化后据此重新计时
+func (s *VoteService) GetDeadline(id uint) (bool, int64, error) {
+	vote, err := findVote(id)
+	if err != nil {
+		return false, 0, err
+	}
+	return vote.Closed, vote.Deadline, nil
+}
+
+// CanViewResults 判断用户当前能否查看投票结果
+func (s *VoteService) CanViewResults(id uint, userID uint) bool {
+	vote, err := findVote(id)
+	if err != nil {
+		return false
+	}
+	return canViewResults(vote, userID)
+}
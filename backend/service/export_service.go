@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/utils"
+)
+
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl"
+	ExportXLSX  = "xlsx"
+
+	ExportResults = "results"
+	ExportBallots = "ballots"
+)
+
+// exportFlushEvery 每写出多少行刷新一次输出
+const exportFlushEvery = 500
+
+type ExportService struct{}
+
+func NewExportService() *ExportService {
+	return &ExportService{}
+}
+
+// exportWriter 按行写出导出数据，不同格式实现各自的编码
+type exportWriter interface {
+	Header(sheet string, columns ...string) error
+	Row(values ...interface{}) error
+	Flush() error
+	Close() error
+}
+
+// escapeFormula 在以公式字符开头的文本前加单引号，避免表格软件把用户输入的
+// 选项内容、用户名等当作公式执行
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// formatCell 把单元格格式化为文本
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escapeFormula(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) Header(sheet string, columns ...string) error {
+	return e.w.Write(columns)
+}
+
+func (e *csvExport) Row(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatCell(v)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExport) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExport) Close() error {
+	return e.Flush()
+}
+
+// jsonlExport 每行一个 JSON 对象，字段顺序与列顺序一致
+type jsonlExport struct {
+	w       io.Writer
+	columns []string
+}
+
+func (e *jsonlExport) Header(sheet string, columns ...string) error {
+	e.columns = columns
+	return nil
+}
+
+func (e *jsonlExport) Row(values ...interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.columns[i])
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func (e *jsonlExport) Flush() error {
+	return nil
+}
+
+func (e *jsonlExport) Close() error {
+	return nil
+}
+
+type xlsxExport struct {
+	w *utils.XLSXWriter
+}
+
+func (e *xlsxExport) Header(sheet string, columns ...string) error {
+	if err := e.w.NewSheet(sheet); err != nil {
+		return err
+	}
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = c
+	}
+	return e.w.WriteRow(values...)
+}
+
+func (e *xlsxExport) Row(values ...interface{}) error {
+	for i, v := range values {
+		if text, ok := v.(string); ok {
+			values[i] = escapeFormula(text)
+		}
+	}
+	return e.w.WriteRow(values...)
+}
+
+func (e *xlsxExport) Flush() error {
+	return nil
+}
+
+func (e *xlsxExport) Close() error {
+	return e.w.Close()
+}
+
+func newExportWriter(format string, w io.Writer) exportWriter {
+	switch format {
+	case ExportJSONL:
+		return &jsonlExport{w: w}
+	case ExportXLSX:
+		return &xlsxExport{w: utils.NewXLSXWriter(w)}
+	default:
+		return &csvExport{w: csv.NewWriter(w)}
+	}
+}
+
+// normalizeExportQuery 填充默认的格式和数据类型
+func normalizeExportQuery(query *dto.ExportQuery) {
+	if query.Format == "" {
+		query.Format = ExportCSV
+	}
+	if query.Data == "" {
+		query.Data = ExportResults
+	}
+}
+
+// PrepareExport 检查导出权限并返回要导出的投票。导出选票会暴露每个人的选择，
+// 因此还需要查看投票人的权限
+func (s *ExportService) PrepareExport(id uint, query *dto.ExportQuery, userID uint) (*model.Vote, error) {
+	normalizeExportQuery(query)
+
+	var vote model.Vote
+	if err := database.GetDB().Preload("Options").First(&vote, id).Error; err != nil {
+		return nil, errors.New("投票不存在")
+	}
+
+	// 管理员可以导出所有投票
+	if !hasPermission(&vote, userID, model.PermissionExport) && !isAdmin(userID) {
+		return nil, errors.New("没有导出该投票的权限")
+	}
+	if privacyEnabled(vote.ID) && !isAdmin(userID) {
+		return nil, errors.New("投票已启用差分隐私，只有管理员可以导出")
+	}
+	if query.Data == ExportBallots && !hasPermission(&vote, userID, model.PermissionViewVoters) && !isAdmin(userID) {
+		return nil, errors.New("没有导出选票的权限")
+	}
+	return &vote, nil
+}
+
+// WriteExport 把导出数据流式写入 w，flush 在每批数据写出后调用
+func (s *ExportService) WriteExport(vote *model.Vote, query *dto.ExportQuery, w io.Writer, flush func()) error {
+	normalizeExportQuery(query)
+
+	out := newExportWriter(query.Format, w)
+	var err error
+	if query.Data == ExportBallots {
+		err = exportBallots(vote, out, flush)
+	} else {
+		err = exportResults(vote, out)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func exportResults(vote *model.Vote, out exportWriter) error {
+	results, err := currentResults(vote)
+	if err != nil {
+		return err
+	}
+
+	generatedAt := time.Now()
+	if results.FinalizedAt != nil {
+		generatedAt = time.Unix(*results.FinalizedAt, 0)
+	}
+
+	if err := out.Header("Results", "option_id", "content", "count", "weight",
+		"percent_of_voters", "percent_of_selections", "percent_of_weight",
+		"rank", "voters", "final", "generated_at"); err != nil {
+		return err
+	}
+	for _, option := range results.Options {
+		if err := out.Row(option.OptionID, option.Content, option.Count, option.Weight,
+			option.PercentOfVoters, option.PercentOfSelections, option.PercentOfWeight,
+			option.Rank, results.Turnout.Voters, results.Final, generatedAt.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ballotExportRow struct {
+	ID            uint
+	UserID        uint
+	Username      *string
+	OptionID      uint
+	OptionContent *string
+	Weight        float64
+	ProxyID       *uint
+	ProxyUsername *string
+	CreatedAt     time.Time
+}
+
+// exportBallots 按记录顺序逐行读取选票，不把整个投票的选票加载到内存
+func exportBallots(vote *model.Vote, out exportWriter, flush func()) error {
+	db := database.GetDB()
+	rows, err := db.Table("user_votes AS uv").
+		Select("uv.id, uv.user_id, u.username, uv.option_id, o.content AS option_content, uv.weight, uv.proxy_id, p.username AS proxy_username, uv.created_at").
+		Joins("LEFT JOIN users u ON u.id = uv.user_id").
+		Joins("LEFT JOIN vote_options o ON o.id = uv.option_id").
+		Joins("LEFT JOIN users p ON p.id = uv.proxy_id").
+		Where("uv.vote_id = ?", vote.ID).
+		Order("uv.id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if err := out.Header("Ballots", "ballot_id", "user_id", "username", "option_id", "option",
+		"weight", "proxy_id", "proxy_username", "voted_at"); err != nil {
+		return err
+	}
+
+	count := 0
+	for rows.Next() {
+		var row ballotExportRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+
+		var proxyID, proxyUsername interface{}
+		if row.ProxyID != nil {
+			proxyID = *row.ProxyID
+		}
+		if row.ProxyUsername != nil {
+			proxyUsername = *row.ProxyUsername
+		}
+		if err := out.Row(row.ID, row.UserID, stringValue(row.Username), row.OptionID, stringValue(row.OptionContent),
+			row.Weight, proxyID, proxyUsername, row.CreatedAt.Format(time.RFC3339)); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			if flush != nil {
+				flush()
+			}
+		}
+	}
+	return rows.Err()
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
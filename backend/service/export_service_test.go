@@ -0,0 +1,42 @@
+package service
+
+import "testing"
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"选项A", "选项A"},
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@cmd", "'@cmd"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=b", "a=b"},
+	}
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, ""},
+		{-1.5, "-1.5"},
+		{uint(3), "3"},
+		{"=1+1", "'=1+1"},
+	}
+	for _, tt := range tests {
+		if got := formatCell(tt.in); got != tt.want {
+			t.Errorf("formatCell(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// GetHistory 分页返回用户投过票的投票，按投票时间倒序，包含自己的选择和投票当前的状态。
+// 由代理人代投的选票也计入委托人的记录
+func (s *VoteService) GetHistory(query *dto.PageQuery, userID uint) (*dto.HistoryResponse, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	participated := func() *gorm.DB {
+		return database.GetDB().Model(&model.UserVote{}).
+			Joins("JOIN votes ON votes.id = user_votes.vote_id AND votes.deleted_at IS NULL AND votes.survey_id IS NULL").
+			Where("user_votes.user_id = ?", userID)
+	}
+
+	resp := &dto.HistoryResponse{Items: []dto.HistoryItem{}}
+	if err := participated().Distinct("user_votes.vote_id").Count(&resp.Total).Error; err != nil {
+		return nil, err
+	}
+
+	// 每个投票以最早一条投票记录的 ID 作为分页位置
+	db := participated().
+		Select("user_votes.vote_id, MIN(user_votes.id) AS ballot_id, MIN(user_votes.created_at) AS voted_at").
+		Group("user_votes.vote_id")
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Having("MIN(user_votes.id) < ?", cursor.ID)
+	}
+	var rows []struct {
+		VoteID   uint
+		BallotID uint
+		VotedAt  time.Time
+	}
+	if err := db.Order("ballot_id DESC").Limit(limit + 1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		resp.HasMore = true
+		resp.NextCursor = listCursor{ID: rows[limit-1].BallotID}.encode()
+	}
+	if len(rows) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.VoteID)
+	}
+	var votes []model.Vote
+	if err := database.GetDB().Preload("Options").Preload("Tags").Where("id IN ?", ids).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	var userVotes []model.UserVote
+	if err := database.GetDB().Where("user_id = ? AND vote_id IN ?", userID, ids).Order("id").Find(&userVotes).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*model.Vote, len(votes))
+	for i := range votes {
+		byID[votes[i].ID] = &votes[i]
+	}
+	ballots := make(map[uint][]model.UserVote)
+	for _, uv := range userVotes {
+		ballots[uv.VoteID] = append(ballots[uv.VoteID], uv)
+	}
+
+	for _, row := range rows {
+		vote, ok := byID[row.VoteID]
+		if !ok {
+			continue
+		}
+		item := dto.HistoryItem{
+			VoteID:         vote.ID,
+			Title:          vote.Title,
+			Multi:          vote.Multi,
+			Deadline:       vote.Deadline,
+			State:          "open",
+			CategoryID:     vote.CategoryID,
+			Tags:           make([]string, 0, len(vote.Tags)),
+			OptionIDs:      []uint{},
+			Options:        []string{},
+			VotedAt:        row.VotedAt,
+			ResultsVisible: canViewResults(vote, userID),
+		}
+		if voteEnded(vote) {
+			item.State = "closed"
+		}
+		for _, tag := range vote.Tags {
+			item.Tags = append(item.Tags, tag.Name)
+		}
+		contents := make(map[uint]string, len(vote.Options))
+		for _, option := range vote.Options {
+			contents[option.ID] = option.Content
+		}
+		for _, uv := range ballots[vote.ID] {
+			item.OptionIDs = append(item.OptionIDs, uv.OptionID)
+			item.Options = append(item.Options, contents[uv.OptionID])
+			item.Weight = uv.Weight
+			item.ProxyID = uv.ProxyID
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+// GetInbox 分页返回仍在进行、自己还没投票且有投票资格的投票，截止时间最早的在前
+func (s *VoteService) GetInbox(query *dto.PageQuery, userID uint) (*VoteList, error) {
+	voted := false
+	inbox := &dto.VoteListQuery{
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+		Sort:   dto.SortEnding,
+		State:  "open",
+		Voted:  &voted,
+	}
+	// 关联了权重名单的投票只对名单中的用户显示
+	eligible := func(db *gorm.DB) *gorm.DB {
+		return db.Where("weight_set_id IS NULL OR weight_set_id IN (?)",
+			database.GetDB().Model(&model.WeightEntry{}).Select("set_id").Where("user_id = ? AND weight > 0", userID))
+	}
+	return listVotes(inbox, userID, eligible)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+	"vote-system-backend/config"
+	"vote-system-backend/database"
+	"vote-system-backend/model"
+	"vote-system-backend/storage"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+const (
+	maxOptionImages   = 4    // 每个选项最多的图片数
+	maxImageDimension = 8000 // 图片宽高的上限，避免解码超大图片占用过多内存
+	thumbnailSize     = 320  // 缩略图最长边
+	thumbnailQuality  = 80
+)
+
+// imageExtensions 允许上传的图片类型及保存时的扩展名
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+type ImageService struct{}
+
+func NewImageService() *ImageService {
+	return &ImageService{}
+}
+
+// optionDescription 返回第 i 个选项的说明，说明可以比选项少
+func optionDescription(descriptions []string, i int) string {
+	if i < len(descriptions) {
+		return descriptions[i]
+	}
+	return ""
+}
+
+// checkOptionDescriptions 检查选项说明与选项的数量是否匹配
+func checkOptionDescriptions(options, descriptions []string) error {
+	if len(descriptions) > len(options) {
+		return errors.New("选项说明的数量不能超过选项数量")
+	}
+	return nil
+}
+
+// fillImageURLs 按当前存储生成选项图片的访问地址
+func fillImageURLs(options []model.VoteOption) {
+	s := storage.GetStorage()
+	for i := range options {
+		for j := range options[i].Images {
+			img := &options[i].Images[j]
+			img.URL = s.URL(img.Key)
+			img.ThumbURL = s.URL(img.ThumbKey)
+		}
+	}
+}
+
+// deleteImageFiles 删除图片和缩略图文件，在数据库事务提交后调用，失败只记录日志
+func deleteImageFiles(images []model.OptionImage) {
+	s := storage.GetStorage()
+	for _, img := range images {
+		for _, key := range []string{img.Key, img.ThumbKey} {
+			if err := s.Delete(key); err != nil {
+				log.Printf("删除图片文件 %s 失败: %v", key, err)
+			}
+		}
+	}
+}
+
+// makeThumbnail 把图片缩小到最长边不超过 thumbnailSize，透明部分填充白色后编码为 JPEG
+func makeThumbnail(src image.Image) ([]byte, error) {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailSize || height > thumbnailSize {
+		if width >= height {
+			width, height = thumbnailSize, max(1, height*thumbnailSize/width)
+		} else {
+			width, height = max(1, width*thumbnailSize/height), thumbnailSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// imageKey 生成图片在存储中的路径前缀，随机部分避免被猜到和缓存冲突
+func imageKey(voteID, optionID uint) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("votes/%d/options/%d/%s", voteID, optionID, hex.EncodeToString(b)), nil
+}
+
+// UploadImage 为选项上传图片，需要编辑权限。只接受 JPEG、PNG、GIF 和 WebP，
+// 按文件内容而不是文件名判断类型，同时生成缩略图
+func (s *ImageService) UploadImage(voteID, optionID uint, data []byte, userID uint) (*model.OptionImage, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		return nil, errors.New("没有权限修改此投票")
+	}
+	if err := checkNotSurveyVote(vote); err != nil {
+		return nil, err
+	}
+
+	var option model.VoteOption
+	if err := database.GetDB().Where("id = ? AND vote_id = ?", optionID, voteID).First(&option).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("选项不存在")
+		}
+		return nil, err
+	}
+	var count int64
+	if err := database.GetDB().Model(&model.OptionImage{}).Where("option_id = ?", optionID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxOptionImages {
+		return nil, fmt.Errorf("每个选项最多上传%d张图片", maxOptionImages)
+	}
+
+	maxSize := config.Get().Storage.MaxSize
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("图片不能超过%gMB", float64(maxSize)/(1<<20))
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, errors.New("只支持 JPEG、PNG、GIF 和 WebP 图片")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("无法识别的图片")
+	}
+	if cfg.Width > maxImageDimension || cfg.Height > maxImageDimension {
+		return nil, fmt.Errorf("图片宽高不能超过%d像素", maxImageDimension)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("无法识别的图片")
+	}
+	thumb, err := makeThumbnail(src)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := imageKey(voteID, optionID)
+	if err != nil {
+		return nil, err
+	}
+	img := model.OptionImage{
+		VoteID:      voteID,
+		OptionID:    optionID,
+		Key:         key + "." + ext,
+		ThumbKey:    key + "_thumb.jpg",
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		UploaderID:  userID,
+	}
+
+	store := storage.GetStorage()
+	if err := store.Put(img.Key, data, contentType); err != nil {
+		return nil, err
+	}
+	if err := store.Put(img.ThumbKey, thumb, "image/jpeg"); err != nil {
+		deleteImageFiles([]model.OptionImage{img})
+		return nil, err
+	}
+	if err := database.GetDB().Create(&img).Error; err != nil {
+		deleteImageFiles([]model.OptionImage{img})
+		return nil, err
+	}
+
+	img.URL = store.URL(img.Key)
+	img.ThumbURL = store.URL(img.ThumbKey)
+	publishState(vote.ID, StateUpdated)
+	return &img, nil
+}
+
+// DeleteImage 删除选项图片，需要编辑权限
+func (s *ImageService) DeleteImage(voteID, imageID, userID uint) error {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return err
+	}
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		return errors.New("没有权限修改此投票")
+	}
+	if err := checkNotSurveyVote(vote); err != nil {
+		return err
+	}
+
+	var img model.OptionImage
+	if err := database.GetDB().Where("id = ? AND vote_id = ?", imageID, voteID).First(&img).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("图片不存在")
+		}
+		return err
+	}
+	// 同时删除时只由一个请求删除文件
+	result := database.GetDB().Delete(&img)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("图片不存在")
+	}
+
+	deleteImageFiles([]model.OptionImage{img})
+	publishState(vote.ID, StateUpdated)
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+)
+
+// notificationExcerptLength 通知中评论摘要的最大字数
+const notificationExcerptLength = 50
+
+type NotificationService struct{}
+
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// excerpt 截取评论内容的开头
+func excerpt(content string) string {
+	runes := []rune(content)
+	if len(runes) <= notificationExcerptLength {
+		return content
+	}
+	return string(runes[:notificationExcerptLength]) + "…"
+}
+
+// GetNotifications 分页返回通知，最新的在前。评论删除后摘要为空
+func (s *NotificationService) GetNotifications(query *dto.NotificationQuery, userID uint) (*dto.NotificationList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	list := &dto.NotificationList{Items: []dto.NotificationResponse{}}
+	if err := database.GetDB().Model(&model.Notification{}).Where("user_id = ? AND `read` = ?", userID, false).Count(&list.Unread).Error; err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB().Where("user_id = ?", userID)
+	if query.Unread {
+		db = db.Where("`read` = ?", false)
+	}
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("id < ?", cursor.ID)
+	}
+	var notifications []model.Notification
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		list.HasMore = true
+		list.NextCursor = listCursor{ID: notifications[limit-1].ID}.encode()
+	}
+	if len(notifications) == 0 {
+		return list, nil
+	}
+
+	actorIDs := make([]uint, 0, len(notifications))
+	voteIDs := make([]uint, 0, len(notifications))
+	commentIDs := make([]uint, 0, len(notifications))
+	for _, n := range notifications {
+		actorIDs = append(actorIDs, n.ActorID)
+		voteIDs = append(voteIDs, n.VoteID)
+		commentIDs = append(commentIDs, n.CommentID)
+	}
+	names, err := usernamesByID(actorIDs)
+	if err != nil {
+		return nil, err
+	}
+	var votes []model.Vote
+	if err := database.GetDB().Select("id", "title").Where("id IN ?", voteIDs).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	titles := make(map[uint]string, len(votes))
+	for _, vote := range votes {
+		titles[vote.ID] = vote.Title
+	}
+	var comments []model.Comment
+	if err := database.GetDB().Where("id IN ?", commentIDs).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	excerpts := make(map[uint]string, len(comments))
+	for _, comment := range comments {
+		if comment.Visible() {
+			excerpts[comment.ID] = excerpt(comment.Content)
+		}
+	}
+
+	for _, n := range notifications {
+		list.Items = append(list.Items, dto.NotificationResponse{
+			ID:        n.ID,
+			Type:      n.Type,
+			ActorID:   n.ActorID,
+			ActorName: names[n.ActorID],
+			VoteID:    n.VoteID,
+			VoteTitle: titles[n.VoteID],
+			CommentID: n.CommentID,
+			Excerpt:   excerpts[n.CommentID],
+			Read:      n.Read,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return list, nil
+}
+
+// MarkRead 把指定通知标记为已读，未指定时全部标记为已读
+func (s *NotificationService) MarkRead(req *dto.MarkReadRequest, userID uint) error {
+	db := database.GetDB().Model(&model.Notification{}).Where("user_id = ? AND `read` = ?", userID, false)
+	if len(req.IDs) > 0 {
+		db = db.Where("id IN ?", req.IDs)
+	}
+	return db.Update("read", true).Error
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/pubsub"
+
+	"gorm.io/gorm"
+)
+
+// 会话码字符集，去掉了容易混淆的 0/O、1/I
+const presentationCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const presentationCodeLength = 6
+
+type PresentationService struct{}
+
+func NewPresentationService() *PresentationService {
+	return &PresentationService{}
+}
+
+const (
+	// PresenceTimeout 观众连接超过该时间没有心跳视为离线，连接需要在此之前调用 Touch
+	PresenceTimeout = time.Minute
+	// 清理过期访客和离线观众的间隔
+	guestReapInterval = time.Hour
+	guestReapBatch    = 500
+)
+
+func generatePresentationCode() (string, error) {
+	code := make([]byte, presentationCodeLength)
+	max := big.NewInt(int64(len(presentationCodeChars)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = presentationCodeChars[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func (s *PresentationService) findPresentation(id uint) (*model.Presentation, error) {
+	var presentation model.Presentation
+	err := database.GetDB().Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&presentation, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("演示会话不存在")
+		}
+		return nil, err
+	}
+	return &presentation, nil
+}
+
+func (s *PresentationService) findOwnedPresentation(id uint, userID uint) (*model.Presentation, error) {
+	presentation, err := s.findPresentation(id)
+	if err != nil {
+		return nil, err
+	}
+	if presentation.PresenterID != userID {
+		return nil, errors.New("没有权限操作此演示会话")
+	}
+	return presentation, nil
+}
+
+// currentVoteID 返回当前展示的投票ID，未开始或已结束时返回0
+func currentVoteID(presentation *model.Presentation) uint {
+	if !presentation.Active || presentation.CurrentIndex < 0 || presentation.CurrentIndex >= len(presentation.Items) {
+		return 0
+	}
+	return presentation.Items[presentation.CurrentIndex].VoteID
+}
+
+// publishNowShowing 通知所有观众刷新当前展示，具体内容由各连接按观众身份生成
+func publishNowShowing(presentationID uint) {
+	pubsub.GetBroker().Publish(pubsub.PresentationTopic(presentationID), pubsub.Event{
+		Type: pubsub.EventNowShowing,
+		Data: dto.NowShowing{PresentationID: presentationID},
+	})
+}
+
+// checkGuestVote 访客只能参与其加入的演示会话中正在展示的投票
+func checkGuestVote(userID uint, voteID uint) error {
+	var guest model.PresentationGuest
+	if err := database.GetDB().Where("user_id = ?", userID).First(&guest).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	presentation, err := NewPresentationService().findPresentation(guest.PresentationID)
+	if err != nil {
+		return err
+	}
+	if currentVoteID(presentation) != voteID {
+		return errors.New("访客只能参与当前展示的投票")
+	}
+	return nil
+}
+
+// CreatePresentation 创建演示会话，演示者需要对每个投票拥有关闭权限以便现场开启和关闭
+func (s *PresentationService) CreatePresentation(req *dto.CreatePresentationRequest, userID uint) (*model.Presentation, error) {
+	presentation := model.Presentation{
+		Title:        req.Title,
+		PresenterID:  userID,
+		CurrentIndex: -1,
+		Active:       true,
+	}
+
+	for i, voteID := range req.VoteIDs {
+		vote, err := findVote(voteID)
+		if err != nil {
+			return nil, err
+		}
+		if !hasPermission(vote, userID, model.PermissionClose) {
+			return nil, errors.New("没有权限展示此投票")
+		}
+		presentation.Items = append(presentation.Items, model.PresentationItem{
+			VoteID:   vote.ID,
+			Position: i,
+		})
+	}
+
+	// 会话码冲突时重试
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generatePresentationCode()
+		if err != nil {
+			return nil, err
+		}
+
+		var exists int64
+		if err := database.GetDB().Model(&model.Presentation{}).Where("code = ?", code).Count(&exists).Error; err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			presentation.Code = code
+			break
+		}
+	}
+	if presentation.Code == "" {
+		return nil, errors.New("会话码生成失败")
+	}
+
+	if err := database.GetDB().Create(&presentation).Error; err != nil {
+		return nil, err
+	}
+	return &presentation, nil
+}
+
+func (s *PresentationService) GetMyPresentations(userID uint) ([]model.Presentation, error) {
+	var presentations []model.Presentation
+	err := database.GetDB().Where("presenter_id = ?", userID).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Order("id DESC").Find(&presentations).Error
+	if err != nil {
+		return nil, err
+	}
+	return presentations, nil
+}
+
+func (s *PresentationService) GetPresentation(id uint, userID uint) (*model.Presentation, error) {
+	return s.findOwnedPresentation(id, userID)
+}
+
+// Goto 切换到指定位置的投票并推送给所有观众
+func (s *PresentationService) Goto(id uint, index int, userID uint) error {
+	presentation, err := s.findOwnedPresentation(id, userID)
+	if err != nil {
+		return err
+	}
+	if !presentation.Active {
+		return errors.New("演示会话已结束")
+	}
+	if index < 0 || index >= len(presentation.Items) {
+		return errors.New("没有更多投票")
+	}
+
+	if err := database.GetDB().Model(presentation).Update("current_index", index).Error; err != nil {
+		return err
+	}
+
+	publishNowShowing(presentation.ID)
+	return nil
+}
+
+func (s *PresentationService) Next(id uint, userID uint) error {
+	presentation, err := s.findOwnedPresentation(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.Goto(id, presentation.CurrentIndex+1, userID)
+}
+
+func (s *PresentationService) Prev(id uint, userID uint) error {
+	presentation, err := s.findOwnedPresentation(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.Goto(id, presentation.CurrentIndex-1, userID)
+}
+
+// OpenCurrent 现场开启当前展示的投票
+func (s *PresentationService) OpenCurrent(id uint, userID uint) error {
+	presentation, err := s.findOwnedPresentation(id, userID)
+	if err != nil {
+		return err
+	}
+
+	voteID := currentVoteID(presentation)
+	if voteID == 0 {
+		return errors.New("当前没有展示的投票")
+	}
+
+	if err := NewVoteService().OpenVote(voteID, userID); err != nil {
+		return err
+	}
+
+	publishNowShowing(presentation.ID)
+	return nil
+}
+
+// CloseCurrent 现场关闭当前展示的投票
+func (s *PresentationService) CloseCurrent(id uint, userID uint) error {
+	presentation, err := s.findOwnedPresentation(id, userID)
+	if err != nil {
+		return err
+	}
+
+	voteID := currentVoteID(presentation)
+	if voteID == 0 {
+		return errors.New("当前没有展示的投票")
+	}
+
+	if err := NewVoteService().CloseVote(voteID, userID); err != nil {
+		return err
+	}
+
+	publishNowShowing(presentation.ID)
+	return nil
+}
+
+// End 结束演示会话，之后访客不能再加入或投票
+func (s *PresentationService) End(id uint, userID uint) error {
+	presentation, err := s.findOwnedPresentation(id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := database.GetDB().Model(presentation).Update("active", false).Error; err != nil {
+		return err
+	}
+
+	publishNowShowing(presentation.ID)
+	return nil
+}
+
+// FindByCode 按会话码查找演示会话
+func (s *PresentationService) FindByCode(code string) (*model.Presentation, error) {
+	var presentation model.Presentation
+	if err := database.GetDB().Where("code = ?", code).First(&presentation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("会话码无效")
+		}
+		return nil, err
+	}
+	return &presentation, nil
+}
+
+// JoinAsGuest 通过会话码以访客身份加入，返回新建的访客用户
+func (s *PresentationService) JoinAsGuest(code string) (*model.Presentation, *model.User, error) {
+	presentation, err := s.FindByCode(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !presentation.Active {
+		return nil, nil, errors.New("演示会话已结束")
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, nil, err
+	}
+
+	// 访客没有可用的密码，无法通过登录接口登录
+	user := model.User{
+		Username:     "guest-" + hex.EncodeToString(suffix),
+		PasswordHash: "!",
+		Guest:        true,
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Create(&user).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	guest := model.PresentationGuest{
+		PresentationID: presentation.ID,
+		UserID:         user.ID,
+	}
+	if err := tx.Create(&guest).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, nil, err
+	}
+	return presentation, &user, nil
+}
+
+// NowShowing 按观众身份生成当前展示内容
+func (s *PresentationService) NowShowing(id uint, viewerID uint) (*dto.NowShowing, error) {
+	presentation, err := s.findPresentation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	showing := &dto.NowShowing{
+		PresentationID: presentation.ID,
+		Title:          presentation.Title,
+		Active:         presentation.Active,
+		Index:          presentation.CurrentIndex,
+		Total:          len(presentation.Items),
+	}
+
+	if voteID := currentVoteID(presentation); voteID != 0 {
+		snapshot, err := NewVoteService().GetLiveSnapshot(voteID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		showing.Vote = snapshot
+	}
+	return showing, nil
+}
+
+// Join 记录观众上线并推送在线人数，返回连接的ID
+func (s *PresentationService) Join(id uint) (uint, error) {
+	viewer := model.PresentationViewer{PresentationID: id, SeenAt: time.Now()}
+	if err := database.GetDB().Create(&viewer).Error; err != nil {
+		return 0, err
+	}
+	s.publishParticipants(id)
+	return viewer.ID, nil
+}
+
+// Touch 更新观众连接的心跳时间，失败只记录日志，连接在下次心跳时重试
+func (s *PresentationService) Touch(viewerID uint) {
+	if err := database.GetDB().Model(&model.PresentationViewer{}).Where("id = ?", viewerID).Update("seen_at", time.Now()).Error; err != nil {
+		log.Println("更新观众心跳失败:", err)
+	}
+}
+
+// Leave 记录观众下线并推送在线人数
+func (s *PresentationService) Leave(id uint, viewerID uint) {
+	if err := database.GetDB().Delete(&model.PresentationViewer{}, viewerID).Error; err != nil {
+		log.Println("删除观众连接失败:", err)
+	}
+	s.publishParticipants(id)
+}
+
+// publishParticipants 推送所有副本合计的在线人数
+func (s *PresentationService) publishParticipants(id uint) {
+	var count int64
+	err := database.GetDB().Model(&model.PresentationViewer{}).
+		Where("presentation_id = ? AND seen_at >= ?", id, time.Now().Add(-PresenceTimeout)).
+		Count(&count).Error
+	if err != nil {
+		log.Println("统计在线人数失败:", err)
+		return
+	}
+
+	pubsub.GetBroker().Publish(pubsub.PresentationTopic(id), pubsub.Event{
+		Type: pubsub.EventParticipants,
+		Data: dto.ParticipantsEvent{PresentationID: id, Count: int(count)},
+	})
+}
+
+// StartGuestReaper 启动后台协程，定期删除过期且没有投过票的访客账号，以及异常退出后残留的观众连接
+func StartGuestReaper() {
+	go func() {
+		ticker := time.NewTicker(guestReapInterval)
+		defer ticker.Stop()
+
+		for ; ; <-ticker.C {
+			if err := reapGuests(); err != nil {
+				log.Println("清理访客失败:", err)
+			}
+			if err := database.GetDB().Where("seen_at < ?", time.Now().Add(-PresenceTimeout)).Delete(&model.PresentationViewer{}).Error; err != nil {
+				log.Println("清理观众连接失败:", err)
+			}
+		}
+	}()
+}
+
+// reapGuests 删除过期的访客账号。投过票的访客保留，其选票仍然计入结果，但令牌已经过期
+func reapGuests() error {
+	for {
+		var ids []uint
+		err := database.GetDB().Model(&model.User{}).
+			Where("guest = ? AND created_at < ?", true, time.Now().Add(-model.GuestLifetime)).
+			Where("NOT EXISTS (SELECT 1 FROM user_votes WHERE user_votes.user_id = users.id)").
+			Limit(guestReapBatch).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("user_id IN ?", ids).Delete(&model.PresentationGuest{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN ?", ids).Delete(&model.User{}).Error
+		})
+		if err != nil {
+			return err
+		}
+		if len(ids) < guestReapBatch {
+			return nil
+		}
+	}
+}
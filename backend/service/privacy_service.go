@@ -0,0 +1,401 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// defaultPrivacyDelta 高斯机制的默认 δ
+const defaultPrivacyDelta = 1e-6
+
+type PrivacyService struct{}
+
+func NewPrivacyService() *PrivacyService {
+	return &PrivacyService{}
+}
+
+// privacyEnabled 判断投票是否启用了差分隐私
+func privacyEnabled(voteID uint) bool {
+	var count int64
+	database.GetDB().Model(&model.PrivacyBudget{}).Where("vote_id = ?", voteID).Count(&count)
+	return count > 0
+}
+
+// privateVoteIDs 返回列表中启用了差分隐私的投票
+func privateVoteIDs(voteIDs []uint) (map[uint]bool, error) {
+	private := make(map[uint]bool)
+	if len(voteIDs) == 0 {
+		return private, nil
+	}
+
+	var ids []uint
+	if err := database.GetDB().Model(&model.PrivacyBudget{}).Where("vote_id IN ?", voteIDs).Pluck("vote_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		private[id] = true
+	}
+	return private, nil
+}
+
+func findPrivacyBudget(voteID uint) (*model.PrivacyBudget, error) {
+	var budget model.PrivacyBudget
+	if err := database.GetDB().Where("vote_id = ?", voteID).First(&budget).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// uniform 返回 (0, 1) 区间内的均匀分布随机数，使用密码学安全的随机源
+func uniform() float64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return (float64(binary.BigEndian.Uint64(b[:])>>11) + 0.5) / (1 << 53)
+}
+
+func laplaceNoise(scale float64) float64 {
+	u := uniform() - 0.5
+	if u < 0 {
+		return scale * math.Log(1+2*u)
+	}
+	return -scale * math.Log(1-2*u)
+}
+
+func gaussianNoise(sigma float64) float64 {
+	return sigma * math.Sqrt(-2*math.Log(uniform())) * math.Cos(2*math.Pi*uniform())
+}
+
+// noiseScale 返回噪声参数和敏感度。每个投票人最多使投票人数加1，并使 k 个选项各加1，
+// 单选时 k 为1，多选时为选项数
+func noiseScale(vote *model.Vote, budget *model.PrivacyBudget, epsilon float64) (float64, float64) {
+	k := 1.0
+	if vote.Multi {
+		k = float64(len(vote.Options))
+	}
+	if budget.Mechanism == model.MechanismGaussian {
+		sensitivity := math.Sqrt(k + 1)
+		return math.Sqrt(2*math.Log(1.25/budget.Delta)) * sensitivity / epsilon, sensitivity
+	}
+	sensitivity := k + 1
+	return sensitivity / epsilon, sensitivity
+}
+
+// noisyCount 加噪后取整，负数截断为0，不影响隐私保证
+func noisyCount(count int, mechanism string, scale float64) int {
+	var noise float64
+	if mechanism == model.MechanismGaussian {
+		noise = gaussianNoise(scale)
+	} else {
+		noise = laplaceNoise(scale)
+	}
+	noisy := int(math.Round(float64(count) + noise))
+	if noisy < 0 {
+		return 0
+	}
+	return noisy
+}
+
+func toBudgetResponse(voteID uint, budget *model.PrivacyBudget) (*dto.PrivacyBudgetResponse, error) {
+	resp := &dto.PrivacyBudgetResponse{VoteID: voteID}
+	if budget == nil {
+		return resp, nil
+	}
+
+	var releases int64
+	if err := database.GetDB().Model(&model.PrivacyRelease{}).Where("vote_id = ?", voteID).Count(&releases).Error; err != nil {
+		return nil, err
+	}
+	resp.Enabled = true
+	resp.Mechanism = budget.Mechanism
+	resp.Epsilon = budget.Epsilon
+	resp.Delta = budget.Delta
+	resp.Spent = budget.Spent
+	resp.Remaining = math.Max(budget.Epsilon-budget.Spent, 0)
+	resp.Releases = releases
+	return resp, nil
+}
+
+// GetBudget 返回投票的隐私预算及消耗情况
+func (s *PrivacyService) GetBudget(id uint) (*dto.PrivacyBudgetResponse, error) {
+	vote, err := findVote(id)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := findPrivacyBudget(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toBudgetResponse(vote.ID, budget)
+}
+
+// SetBudget 启用差分隐私或修改预算。启用后只有管理员可以修改，防止通过关闭差分隐私查看精确票数
+func (s *PrivacyService) SetBudget(id uint, req *dto.PrivacySettingsRequest, userID uint) (*dto.PrivacyBudgetResponse, error) {
+	vote, err := findVote(id)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		return nil, errors.New("没有权限修改此投票")
+	}
+
+	budget, err := findPrivacyBudget(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	if budget != nil && !isAdmin(userID) {
+		return nil, errors.New("已启用差分隐私，只有管理员可以修改")
+	}
+	if budget == nil {
+		budget = &model.PrivacyBudget{VoteID: vote.ID}
+	}
+	if req.Epsilon < budget.Spent {
+		return nil, errors.New("总预算不能小于已消耗的预算")
+	}
+
+	budget.Mechanism = req.Mechanism
+	budget.Epsilon = req.Epsilon
+	budget.Delta = 0
+	if req.Mechanism == model.MechanismGaussian {
+		budget.Delta = req.Delta
+		if budget.Delta == 0 {
+			budget.Delta = defaultPrivacyDelta
+		}
+	}
+	if err := database.GetDB().Save(budget).Error; err != nil {
+		return nil, err
+	}
+	// 启用后实时连接不能再推送精确票数
+	publishState(vote.ID, StateUpdated)
+	return toBudgetResponse(vote.ID, budget)
+}
+
+// DisableBudget 关闭差分隐私，恢复按结果可见性展示精确票数，仅管理员可用
+func (s *PrivacyService) DisableBudget(id uint, userID uint) error {
+	if !isAdmin(userID) {
+		return errors.New("只有管理员可以关闭差分隐私")
+	}
+
+	result := database.GetDB().Where("vote_id = ?", id).Delete(&model.PrivacyBudget{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该投票未启用差分隐私")
+	}
+	publishState(id, StateUpdated)
+	return nil
+}
+
+// noisyRelease 计算一次加噪发布的结果
+func noisyRelease(vote *model.Vote, budget *model.PrivacyBudget, epsilon float64, attribute string) (*dto.NoisyResultsResponse, error) {
+	scale, sensitivity := noiseScale(vote, budget, epsilon)
+	resp := &dto.NoisyResultsResponse{
+		VoteID:      vote.ID,
+		Mechanism:   budget.Mechanism,
+		Epsilon:     epsilon,
+		Delta:       budget.Delta,
+		Sensitivity: sensitivity,
+		NoiseScale:  scale,
+		Options:     make([]dto.NoisyOption, 0, len(vote.Options)),
+		ReleasedAt:  time.Now().Unix(),
+	}
+
+	if attribute == "" {
+		ballots, err := directBallots(vote.ID)
+		if err != nil {
+			return nil, err
+		}
+		counts, _ := countBallots(ballots)
+
+		resp.Voters = noisyCount(len(ballots), budget.Mechanism, scale)
+		for _, option := range vote.Options {
+			count := noisyCount(counts[option.ID], budget.Mechanism, scale)
+			resp.Options = append(resp.Options, dto.NoisyOption{
+				OptionID: option.ID,
+				Content:  option.Content,
+				Count:    count,
+				Percent:  percent(float64(count), float64(resp.Voters)),
+			})
+		}
+		return resp, nil
+	}
+
+	def, err := findAttribute(attribute)
+	if err != nil {
+		return nil, err
+	}
+	byValue, err := crosstabCounts(vote, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	// 有可选值的属性发布全部分组，避免分组是否存在本身泄露信息
+	if def.Choices != "" {
+		for _, choice := range append(strings.Split(def.Choices, ","), "") {
+			if _, ok := byValue[choice]; !ok {
+				byValue[choice] = &crosstabGroup{value: choice, counts: make([]int, len(vote.Options))}
+			}
+		}
+	}
+	values := make([]string, 0, len(byValue))
+	for v := range byValue {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	// 每个投票人只属于一个分组，各分组的统计可以共用同一份预算
+	resp.Attribute = attribute
+	totals := make([]int, len(vote.Options))
+	for _, v := range values {
+		g := byValue[v]
+		group := dto.NoisyGroup{
+			Value:  v,
+			Voters: noisyCount(g.voters, budget.Mechanism, scale),
+			Counts: make([]int, len(vote.Options)),
+		}
+		for i, c := range g.counts {
+			group.Counts[i] = noisyCount(c, budget.Mechanism, scale)
+			totals[i] += group.Counts[i]
+		}
+		resp.Voters += group.Voters
+		resp.Groups = append(resp.Groups, group)
+	}
+	for i, option := range vote.Options {
+		resp.Options = append(resp.Options, dto.NoisyOption{
+			OptionID: option.ID,
+			Content:  option.Content,
+			Count:    totals[i],
+			Percent:  percent(float64(totals[i]), float64(resp.Voters)),
+		})
+	}
+	return resp, nil
+}
+
+func decodeRelease(release *model.PrivacyRelease, budget *model.PrivacyBudget) (*dto.NoisyResultsResponse, error) {
+	var resp dto.NoisyResultsResponse
+	if err := json.Unmarshal([]byte(release.Payload), &resp); err != nil {
+		return nil, err
+	}
+	resp.ReleaseID = release.ID
+	resp.Cached = true
+	resp.Remaining = math.Max(budget.Epsilon-budget.Spent, 0)
+	return &resp, nil
+}
+
+// GetNoisyResults 返回加噪的投票结果或交叉统计。query.Epsilon 为0时返回最近一次发布的结果；
+// 指定 epsilon 时，选票未变化且有相同参数的发布则直接返回，否则消耗预算发布新的结果。
+// 发布新结果需要查看投票人的权限
+func (s *PrivacyService) GetNoisyResults(id uint, query *dto.NoisyQuery, userID uint) (*dto.NoisyResultsResponse, error) {
+	var vote model.Vote
+	if err := database.GetDB().Preload("Options").First(&vote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投票不存在")
+		}
+		return nil, err
+	}
+	if !visibilityAllows(&vote, userID) {
+		return nil, errors.New("投票结果暂不可见")
+	}
+
+	budget, err := findPrivacyBudget(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	if budget == nil {
+		return nil, errors.New("该投票未启用差分隐私")
+	}
+
+	kind := model.ReleaseResults
+	if query.Attribute != "" {
+		kind = model.ReleaseCrosstab
+	}
+	releases := database.GetDB().Where("vote_id = ? AND kind = ? AND attribute = ?", vote.ID, kind, query.Attribute).Order("id DESC")
+
+	if query.Epsilon == 0 {
+		var latest model.PrivacyRelease
+		if err := releases.First(&latest).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("暂无已发布的结果")
+			}
+			return nil, err
+		}
+		return decodeRelease(&latest, budget)
+	}
+
+	var ballotCount int64
+	if err := database.GetDB().Model(&model.UserVote{}).Where("vote_id = ?", vote.ID).Count(&ballotCount).Error; err != nil {
+		return nil, err
+	}
+	var cached model.PrivacyRelease
+	err = releases.Where("epsilon = ? AND ballot_count = ?", query.Epsilon, ballotCount).First(&cached).Error
+	if err == nil {
+		return decodeRelease(&cached, budget)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if !hasPermission(&vote, userID, model.PermissionViewVoters) {
+		return nil, errors.New("没有权限发布新的结果")
+	}
+	if budget.Mechanism == model.MechanismGaussian && query.Epsilon >= 1 {
+		return nil, errors.New("高斯机制单次查询的 epsilon 需小于1")
+	}
+
+	resp, err := noisyRelease(&vote, budget, query.Epsilon, query.Attribute)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	release := model.PrivacyRelease{
+		VoteID:      vote.ID,
+		Kind:        kind,
+		Attribute:   query.Attribute,
+		Epsilon:     query.Epsilon,
+		BallotCount: ballotCount,
+		RequestedBy: userID,
+		Payload:     string(payload),
+	}
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// 预算检查和扣减在同一条语句中完成，并发查询不会超出预算
+		result := tx.Model(&model.PrivacyBudget{}).
+			Where("vote_id = ? AND spent + ? <= epsilon + 1e-9", vote.ID, query.Epsilon).
+			UpdateColumn("spent", gorm.Expr("spent + ?", query.Epsilon))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("隐私预算不足")
+		}
+		return tx.Create(&release).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp.ReleaseID = release.ID
+	if budget, err = findPrivacyBudget(vote.ID); err == nil && budget != nil {
+		resp.Remaining = math.Max(budget.Epsilon-budget.Spent, 0)
+	}
+	return resp, nil
+}
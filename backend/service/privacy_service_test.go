@@ -0,0 +1,85 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"vote-system-backend/model"
+)
+
+func TestNoiseScale(t *testing.T) {
+	options := []model.VoteOption{{ID: 1}, {ID: 2}, {ID: 3}}
+	tests := []struct {
+		name        string
+		multi       bool
+		mechanism   string
+		delta       float64
+		epsilon     float64
+		scale       float64
+		sensitivity float64
+	}{
+		{"laplace single choice", false, model.MechanismLaplace, 0, 1, 2, 2},
+		{"laplace multi choice", true, model.MechanismLaplace, 0, 0.5, 8, 4},
+		{"gaussian single choice", false, model.MechanismGaussian, 1e-5, 1,
+			math.Sqrt(2*math.Log(1.25/1e-5)) * math.Sqrt2, math.Sqrt2},
+		{"gaussian multi choice", true, model.MechanismGaussian, 1e-5, 2,
+			math.Sqrt(2*math.Log(1.25/1e-5)) * 2 / 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vote := &model.Vote{Multi: tt.multi, Options: options}
+			budget := &model.PrivacyBudget{Mechanism: tt.mechanism, Delta: tt.delta}
+			scale, sensitivity := noiseScale(vote, budget, tt.epsilon)
+			if math.Abs(scale-tt.scale) > 1e-9 {
+				t.Errorf("scale = %v, want %v", scale, tt.scale)
+			}
+			if math.Abs(sensitivity-tt.sensitivity) > 1e-9 {
+				t.Errorf("sensitivity = %v, want %v", sensitivity, tt.sensitivity)
+			}
+		})
+	}
+}
+
+// 按样本的均值和离散程度粗略检查噪声分布，容差远大于抽样误差，不会偶然失败
+func TestNoiseDistribution(t *testing.T) {
+	const samples = 20000
+	tests := []struct {
+		name   string
+		noise  func() float64
+		spread func(float64) float64 // 每个样本对应的离散程度统计量
+		want   float64
+	}{
+		// 拉普拉斯分布 E|X| = b
+		{"laplace", func() float64 { return laplaceNoise(3) }, math.Abs, 3},
+		// 正态分布 E[X²] = σ²
+		{"gaussian", func() float64 { return gaussianNoise(3) }, func(x float64) float64 { return x * x }, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sum, spread float64
+			for i := 0; i < samples; i++ {
+				x := tt.noise()
+				if math.IsNaN(x) || math.IsInf(x, 0) {
+					t.Fatalf("noise = %v", x)
+				}
+				sum += x
+				spread += tt.spread(x)
+			}
+			if mean := sum / samples; math.Abs(mean) > 0.3 {
+				t.Errorf("mean = %v, want about 0", mean)
+			}
+			if got := spread / samples; math.Abs(got-tt.want) > tt.want*0.1 {
+				t.Errorf("spread = %v, want about %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoisyCountNeverNegative(t *testing.T) {
+	for _, mechanism := range []string{model.MechanismLaplace, model.MechanismGaussian} {
+		for i := 0; i < 1000; i++ {
+			if got := noisyCount(0, mechanism, 10); got < 0 {
+				t.Fatalf("noisyCount(0, %s) = %d", mechanism, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"vote-system-backend/config"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type ProxyService struct{}
+
+func NewProxyService() *ProxyService {
+	return &ProxyService{}
+}
+
+// voteEnded 判断投票是否已关闭或已过截止时间
+func voteEnded(vote *model.Vote) bool {
+	return vote.Closed || (vote.Deadline > 0 && time.Now().Unix() > vote.Deadline)
+}
+
+// proxyGrantKey 从服务端密钥派生出专用于代理授权的签名密钥，与签发令牌的密钥互不通用
+func proxyGrantKey() ([]byte, error) {
+	return hkdf.Key(sha256.New, []byte(config.Get().JWT.Secret), nil, "vote-system proxy grant", sha256.Size)
+}
+
+// grantTime 返回签名中使用的时间，未设置时为 0
+func grantTime(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// signProxyGrant 对授权的全部字段签名，防止授权记录被篡改。状态和时间变化后需要重新签名
+func signProxyGrant(grant *model.ProxyGrant) (string, error) {
+	key, err := proxyGrantKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "proxy:%d:%d:%d:%d:%s:%d:%d:%d",
+		grant.ID, grant.VoteID, grant.PrincipalID, grant.ProxyID, grant.Status,
+		grant.CreatedAt.Unix(), grantTime(grant.AcceptedAt), grantTime(grant.RevokedAt))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verifyProxyGrant 校验授权记录的签名
+func verifyProxyGrant(grant *model.ProxyGrant) error {
+	signature, err := signProxyGrant(grant)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(grant.Signature), []byte(signature)) {
+		return errors.New("代理授权签名无效")
+	}
+	return nil
+}
+
+// updateProxyGrant 修改授权的状态和时间并重新签名
+func updateProxyGrant(grant *model.ProxyGrant, status string) error {
+	now := time.Now().Truncate(time.Second) // 签名按秒计算，截断后与从数据库读回的时间一致
+	updates := map[string]interface{}{"status": status}
+	grant.Status = status
+	switch status {
+	case model.ProxyAccepted:
+		grant.AcceptedAt = &now
+		updates["accepted_at"] = grant.AcceptedAt
+	case model.ProxyRevoked:
+		grant.RevokedAt = &now
+		updates["revoked_at"] = grant.RevokedAt
+	}
+
+	signature, err := signProxyGrant(grant)
+	if err != nil {
+		return err
+	}
+	updates["signature"] = signature
+	return database.GetDB().Model(grant).Updates(updates).Error
+}
+
+// checkProxyGrant 检查代理人是否持有委托人在该投票中有效的授权
+func checkProxyGrant(voteID uint, principalID uint, proxyID uint) error {
+	var grant model.ProxyGrant
+	err := database.GetDB().
+		Where("vote_id = ? AND principal_id = ? AND proxy_id = ? AND status = ?", voteID, principalID, proxyID, model.ProxyAccepted).
+		First(&grant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("没有有效的代理授权")
+		}
+		return err
+	}
+
+	return verifyProxyGrant(&grant)
+}
+
+// GrantProxy 委托人授权他人代为投票，需代理人接受后生效
+func (s *ProxyService) GrantProxy(voteID uint, req *dto.ProxyGrantRequest, principalID uint) (*model.ProxyGrant, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if voteEnded(vote) {
+		return nil, errors.New("投票已结束")
+	}
+
+	proxy, err := findUserByUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if proxy.ID == principalID {
+		return nil, errors.New("不能授权给自己")
+	}
+
+	if !validSubmit(principalID, vote.ID) {
+		return nil, errors.New("已投过票")
+	}
+
+	var active int64
+	if err := database.GetDB().Model(&model.ProxyGrant{}).
+		Where("vote_id = ? AND principal_id = ? AND status IN ?", vote.ID, principalID, []string{model.ProxyPending, model.ProxyAccepted}).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, errors.New("已存在有效的代理授权")
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	grant := model.ProxyGrant{
+		VoteID:      vote.ID,
+		PrincipalID: principalID,
+		ProxyID:     proxy.ID,
+		Status:      model.ProxyPending,
+		CreatedAt:   time.Now().Truncate(time.Second),
+	}
+	if err := tx.Create(&grant).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	grant.Signature, err = signProxyGrant(&grant)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(&grant).Update("signature", grant.Signature).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (s *ProxyService) findGrant(id uint) (*model.ProxyGrant, error) {
+	var grant model.ProxyGrant
+	if err := database.GetDB().First(&grant, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("代理授权不存在")
+		}
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (s *ProxyService) AcceptProxy(id uint, userID uint) error {
+	grant, err := s.findGrant(id)
+	if err != nil {
+		return err
+	}
+
+	if grant.ProxyID != userID {
+		return errors.New("没有权限处理此授权")
+	}
+	if grant.Status != model.ProxyPending {
+		return errors.New("代理授权已处理")
+	}
+
+	vote, err := findVote(grant.VoteID)
+	if err != nil {
+		return err
+	}
+	if voteEnded(vote) {
+		return errors.New("投票已结束")
+	}
+	// 只接受未被篡改的授权，接受后按新的状态重新签名
+	if err := verifyProxyGrant(grant); err != nil {
+		return err
+	}
+
+	return updateProxyGrant(grant, model.ProxyAccepted)
+}
+
+func (s *ProxyService) DeclineProxy(id uint, userID uint) error {
+	grant, err := s.findGrant(id)
+	if err != nil {
+		return err
+	}
+
+	if grant.ProxyID != userID {
+		return errors.New("没有权限处理此授权")
+	}
+	if grant.Status != model.ProxyPending {
+		return errors.New("代理授权已处理")
+	}
+
+	return updateProxyGrant(grant, model.ProxyDeclined)
+}
+
+// RevokeProxy 委托人在投票结束前撤销授权，已代投的选票不受影响
+func (s *ProxyService) RevokeProxy(id uint, userID uint) error {
+	grant, err := s.findGrant(id)
+	if err != nil {
+		return err
+	}
+
+	if grant.PrincipalID != userID {
+		return errors.New("没有权限撤销此授权")
+	}
+	if grant.Status != model.ProxyPending && grant.Status != model.ProxyAccepted {
+		return errors.New("代理授权已失效")
+	}
+
+	vote, err := findVote(grant.VoteID)
+	if err != nil {
+		return err
+	}
+	if voteEnded(vote) {
+		return errors.New("投票已结束，不能撤销授权")
+	}
+
+	return updateProxyGrant(grant, model.ProxyRevoked)
+}
+
+// GetMyProxies 返回我授权出去的和他人授权给我的代理
+func (s *ProxyService) GetMyProxies(userID uint) (*dto.MyProxiesResponse, error) {
+	var granted, received []model.ProxyGrant
+	if err := database.GetDB().Where("principal_id = ?", userID).Order("id DESC").Find(&granted).Error; err != nil {
+		return nil, err
+	}
+	if err := database.GetDB().Where("proxy_id = ?", userID).Order("id DESC").Find(&received).Error; err != nil {
+		return nil, err
+	}
+
+	grantedResponses, err := toProxyResponses(granted)
+	if err != nil {
+		return nil, err
+	}
+	receivedResponses, err := toProxyResponses(received)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MyProxiesResponse{
+		Granted:  grantedResponses,
+		Received: receivedResponses,
+	}, nil
+}
+
+// GetVoteProxies 返回投票的全部代理授权记录，用于审计
+func (s *ProxyService) GetVoteProxies(voteID uint, userID uint) ([]dto.ProxyGrantResponse, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasPermission(vote, userID, model.PermissionViewVoters) {
+		return nil, errors.New("没有权限查看投票人")
+	}
+
+	var grants []model.ProxyGrant
+	if err := database.GetDB().Where("vote_id = ?", vote.ID).Order("id").Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	return toProxyResponses(grants)
+}
+
+func toProxyResponses(grants []model.ProxyGrant) ([]dto.ProxyGrantResponse, error) {
+	userIDs := make([]uint, 0, len(grants)*2)
+	for _, g := range grants {
+		userIDs = append(userIDs, g.PrincipalID, g.ProxyID)
+	}
+	names, err := usernamesByID(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已代投的授权
+	used := make(map[uint]bool)
+	for _, g := range grants {
+		if g.Status != model.ProxyAccepted && g.Status != model.ProxyRevoked {
+			continue
+		}
+		var count int64
+		if err := database.GetDB().Model(&model.UserVote{}).
+			Where("vote_id = ? AND user_id = ? AND proxy_id = ?", g.VoteID, g.PrincipalID, g.ProxyID).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		used[g.ID] = count > 0
+	}
+
+	result := make([]dto.ProxyGrantResponse, 0, len(grants))
+	for _, g := range grants {
+		result = append(result, dto.ProxyGrantResponse{
+			ID:                g.ID,
+			VoteID:            g.VoteID,
+			PrincipalID:       g.PrincipalID,
+			PrincipalUsername: names[g.PrincipalID],
+			ProxyID:           g.ProxyID,
+			ProxyUsername:     names[g.ProxyID],
+			Status:            g.Status,
+			Used:              used[g.ID],
+			Signature:         g.Signature,
+			AcceptedAt:        g.AcceptedAt,
+			RevokedAt:         g.RevokedAt,
+			CreatedAt:         g.CreatedAt,
+		})
+	}
+	return result, nil
+}
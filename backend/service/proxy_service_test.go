@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+)
+
+func TestProxyGrantSignature(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 4)
+	principal, proxy := users[1], users[2]
+	s := NewProxyService()
+
+	vote, err := NewVoteService().CreateVote(&dto.CreateVoteRequest{Title: "董事会决议", Options: []string{"同意", "反对"}}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grant, err := s.GrantProxy(vote.ID, &dto.ProxyGrantRequest{Username: proxy.Username}, principal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AcceptProxy(grant.ID, proxy.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkProxyGrant(vote.ID, principal.ID, proxy.ID); err != nil {
+		t.Fatalf("accepted grant rejected: %v", err)
+	}
+
+	// 直接修改数据库中的任一字段后签名失效
+	tests := []struct {
+		name   string
+		column string
+		value  interface{}
+	}{
+		{"proxy changed", "proxy_id", users[3].ID},
+		{"accepted time changed", "accepted_at", time.Now().Add(-time.Hour)},
+		{"created time changed", "created_at", time.Now().Add(-time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var original model.ProxyGrant
+			if err := database.GetDB().First(&original, grant.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := database.GetDB().Model(&model.ProxyGrant{}).Where("id = ?", grant.ID).Update(tt.column, tt.value).Error; err != nil {
+				t.Fatal(err)
+			}
+			defer database.GetDB().Save(&original)
+
+			var tampered model.ProxyGrant
+			if err := database.GetDB().First(&tampered, grant.ID).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := verifyProxyGrant(&tampered); err == nil {
+				t.Error("tampered grant still verifies")
+			}
+		})
+	}
+
+	// 撤销的授权不能再用于代投，改回已接受也不行
+	if err := s.RevokeProxy(grant.ID, principal.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkProxyGrant(vote.ID, principal.ID, proxy.ID); err == nil {
+		t.Error("revoked grant still usable")
+	}
+	if err := database.GetDB().Model(&model.ProxyGrant{}).Where("id = ?", grant.ID).Update("status", model.ProxyAccepted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := checkProxyGrant(vote.ID, principal.ID, proxy.ID); err == nil {
+		t.Error("revoked grant usable after resetting its status")
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// percent 返回保留两位小数的百分比
+func percent(part float64, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(part/total*10000) / 100
+}
+
+// buildResults 根据统计结果计算百分比、排名和获胜选项，加权投票按权重排名
+func buildResults(vote *model.Vote, tally *dto.TallyResponse) *dto.ResultsResponse {
+	voters := tally.DirectVoters + tally.DelegatedVoters
+	selections := 0
+	for _, option := range tally.Options {
+		selections += option.Total
+	}
+
+	results := &dto.ResultsResponse{
+		VoteID:   vote.ID,
+		Title:    vote.Title,
+		Multi:    vote.Multi,
+		Weighted: tally.Weighted,
+		Turnout: dto.ResultTurnout{
+			Voters:          voters,
+			DirectVoters:    tally.DirectVoters,
+			DelegatedVoters: tally.DelegatedVoters,
+			Selections:      selections,
+			VoterWeight:     tally.VoterWeight,
+		},
+		Options: make([]dto.ResultOption, 0, len(tally.Options)),
+		Winners: []uint{},
+	}
+
+	score := func(option dto.TallyOption) float64 {
+		if tally.Weighted {
+			return option.TotalWeight
+		}
+		return float64(option.Total)
+	}
+
+	for _, option := range tally.Options {
+		results.Options = append(results.Options, dto.ResultOption{
+			OptionID:            option.OptionID,
+			Content:             option.Content,
+			Count:               option.Total,
+			Weight:              option.TotalWeight,
+			PercentOfVoters:     percent(float64(option.Total), float64(voters)),
+			PercentOfSelections: percent(float64(option.Total), float64(selections)),
+			PercentOfWeight:     percent(option.TotalWeight, tally.VoterWeight),
+		})
+	}
+
+	// 按得分降序计算并列排名
+	scores := make([]float64, 0, len(tally.Options))
+	for _, option := range tally.Options {
+		scores = append(scores, score(option))
+	}
+	sorted := append([]float64(nil), scores...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	for i := range results.Options {
+		results.Options[i].Rank = sort.Search(len(sorted), func(j int) bool { return sorted[j] <= scores[i] }) + 1
+	}
+
+	if len(sorted) > 0 && sorted[0] > 0 {
+		for i, option := range results.Options {
+			if scores[i] == sorted[0] {
+				results.Winners = append(results.Winners, option.OptionID)
+			}
+		}
+	}
+	results.Tie = len(results.Winners) > 1
+
+	return results
+}
+
+// finalResults 返回已结束投票的冻结结果，首次查询时生成并保存
+func finalResults(vote *model.Vote) (*dto.ResultsResponse, error) {
+	var snapshot model.ResultSnapshot
+	err := database.GetDB().Where("vote_id = ?", vote.ID).First(&snapshot).Error
+	if err == nil {
+		var results dto.ResultsResponse
+		if err := json.Unmarshal([]byte(snapshot.Payload), &results); err != nil {
+			return nil, err
+		}
+		return &results, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	tally, err := computeTally(vote)
+	if err != nil {
+		return nil, err
+	}
+
+	results := buildResults(vote, tally)
+	results.Final = true
+	finalizedAt := vote.Deadline
+	if vote.Closed || finalizedAt == 0 {
+		finalizedAt = vote.UpdatedAt.Unix()
+	}
+	results.FinalizedAt = &finalizedAt
+
+	payload, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+
+	// 并发生成时以先写入的为准
+	snapshot = model.ResultSnapshot{VoteID: vote.ID, Payload: string(payload)}
+	if err := database.GetDB().Create(&snapshot).Error; err != nil {
+		var existing model.ResultSnapshot
+		if database.GetDB().Where("vote_id = ?", vote.ID).First(&existing).Error == nil {
+			if err := json.Unmarshal([]byte(existing.Payload), results); err != nil {
+				return nil, err
+			}
+			return results, nil
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetResults 返回投票结果，投票结束后结果被冻结并标记为最终结果
+func (s *VoteService) GetResults(id uint, userID uint) (*dto.ResultsResponse, error) {
+	vote, err := loadVisibleVote(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return currentResults(vote)
+}
+
+// currentResults 返回投票当前的结果，已结束的投票返回冻结结果
+func currentResults(vote *model.Vote) (*dto.ResultsResponse, error) {
+	if voteEnded(vote) {
+		return finalResults(vote)
+	}
+
+	tally, err := computeTally(vote)
+	if err != nil {
+		return nil, err
+	}
+	return buildResults(vote, tally), nil
+}
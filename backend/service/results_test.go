@@ -0,0 +1,87 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+)
+
+func TestBuildResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		weighted bool
+		options  []dto.TallyOption
+		ranks    []int
+		winners  []uint
+		tie      bool
+	}{
+		{
+			name:    "single winner",
+			options: []dto.TallyOption{{OptionID: 1, Total: 5}, {OptionID: 2, Total: 3}, {OptionID: 3, Total: 1}},
+			ranks:   []int{1, 2, 3},
+			winners: []uint{1},
+		},
+		{
+			name:    "tie for first",
+			options: []dto.TallyOption{{OptionID: 1, Total: 4}, {OptionID: 2, Total: 4}, {OptionID: 3, Total: 1}},
+			ranks:   []int{1, 1, 3},
+			winners: []uint{1, 2},
+			tie:     true,
+		},
+		{
+			name:    "tie below first",
+			options: []dto.TallyOption{{OptionID: 1, Total: 2}, {OptionID: 2, Total: 5}, {OptionID: 3, Total: 2}},
+			ranks:   []int{2, 1, 2},
+			winners: []uint{2},
+		},
+		{
+			name:    "no ballots",
+			options: []dto.TallyOption{{OptionID: 1}, {OptionID: 2}},
+			ranks:   []int{1, 1},
+			winners: []uint{},
+		},
+		{
+			name:     "weighted ranks by weight",
+			weighted: true,
+			options:  []dto.TallyOption{{OptionID: 1, Total: 3, TotalWeight: 3}, {OptionID: 2, Total: 1, TotalWeight: 5}},
+			ranks:    []int{2, 1},
+			winners:  []uint{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := &dto.TallyResponse{Weighted: tt.weighted, Options: tt.options}
+			results := buildResults(&model.Vote{}, tally)
+			ranks := make([]int, 0, len(results.Options))
+			for _, option := range results.Options {
+				ranks = append(ranks, option.Rank)
+			}
+			if !slices.Equal(ranks, tt.ranks) {
+				t.Errorf("ranks = %v, want %v", ranks, tt.ranks)
+			}
+			if !slices.Equal(results.Winners, tt.winners) {
+				t.Errorf("winners = %v, want %v", results.Winners, tt.winners)
+			}
+			if results.Tie != tt.tie {
+				t.Errorf("tie = %v, want %v", results.Tie, tt.tie)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		part, total, want float64
+	}{
+		{1, 3, 33.33},
+		{2, 3, 66.67},
+		{5, 5, 100},
+		{1, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := percent(tt.part, tt.total); got != tt.want {
+			t.Errorf("percent(%v, %v) = %v, want %v", tt.part, tt.total, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// maxSearchTerms 搜索词最多保留的关键词数
+const maxSearchTerms = 10
+
+// SearchHit 搜索结果中的一个投票
+type SearchHit struct {
+	model.Vote
+	Score          float64 `json:"score"`
+	MatchedOptions []uint  `json:"matched_options"` // 命中关键词的选项
+}
+
+type SearchResult struct {
+	Items      []SearchHit `json:"items"`
+	Total      int64       `json:"total"`
+	NextCursor string      `json:"next_cursor"`
+	HasMore    bool        `json:"has_more"`
+}
+
+// booleanQuery 把用户输入转换为 FULLTEXT 布尔模式的查询。标题、描述和选项使用 ngram 分词，
+// 中文按相邻两个字切分，长度不少于2的关键词按短语匹配，自然包含前缀匹配；
+// 单个字的关键词用 * 匹配以该字开头的词元。关键词之间为“或”，按相关度排序
+func booleanQuery(q string) string {
+	terms := strings.FieldsFunc(q, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+
+	var parts []string
+	for _, term := range terms {
+		if len(parts) == maxSearchTerms {
+			break
+		}
+		if utf8.RuneCountInString(term) < 2 {
+			parts = append(parts, term+"*")
+		} else {
+			parts = append(parts, `"`+term+`"`)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchVotes 按相关度搜索投票的标题、描述和选项，标题和描述的匹配权重为选项的两倍。
+// 全文索引由 MySQL 在增删改时自动维护
+func (s *VoteService) SearchVotes(query *dto.SearchQuery, userID uint) (*SearchResult, error) {
+	q := booleanQuery(query.Q)
+	if q == "" {
+		return nil, errors.New("请输入搜索关键词")
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	offset := 0
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		offset = int(cursor.Key)
+	}
+
+	matchedOptions := database.GetDB().Model(&model.VoteOption{}).
+		Select("vote_id, MAX(MATCH(content) AGAINST(? IN BOOLEAN MODE)) AS score", q).
+		Where("MATCH(content) AGAINST(? IN BOOLEAN MODE)", q).
+		Group("vote_id")
+	search := func() *gorm.DB {
+		db := filterVotes(database.GetDB().Model(&model.Vote{}), &dto.VoteListQuery{State: query.State, Tag: query.Tag, CategoryID: query.CategoryID}, userID)
+		return db.Joins("LEFT JOIN (?) AS matched ON matched.vote_id = votes.id", matchedOptions).
+			Where("MATCH(votes.title, votes.description) AGAINST(? IN BOOLEAN MODE) OR matched.vote_id IS NOT NULL", q)
+	}
+
+	result := &SearchResult{Items: []SearchHit{}}
+	if err := search().Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	var hits []struct {
+		ID    uint
+		Score float64
+	}
+	err := search().
+		Select("votes.id, MATCH(votes.title, votes.description) AGAINST(? IN BOOLEAN MODE) * 2 + IFNULL(matched.score, 0) AS score", q).
+		Order("score DESC, votes.id DESC").
+		Offset(offset).Limit(limit + 1).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) > limit {
+		hits = hits[:limit]
+		result.HasMore = true
+		result.NextCursor = listCursor{Key: int64(offset + limit)}.encode()
+	}
+	if len(hits) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	var votes []model.Vote
+	if err := database.GetDB().Preload("Options").Preload("Tags").Where("id IN ?", ids).Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	if err := maskResults(votes, userID); err != nil {
+		return nil, err
+	}
+	var options []model.VoteOption
+	if err := database.GetDB().Select("id", "vote_id").
+		Where("vote_id IN ? AND MATCH(content) AGAINST(? IN BOOLEAN MODE)", ids, q).
+		Order("id").Find(&options).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*model.Vote, len(votes))
+	for i := range votes {
+		byID[votes[i].ID] = &votes[i]
+	}
+	matched := make(map[uint][]uint)
+	for _, option := range options {
+		matched[option.VoteID] = append(matched[option.VoteID], option.ID)
+	}
+	for _, hit := range hits {
+		vote, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		item := SearchHit{Vote: *vote, Score: hit.Score, MatchedOptions: matched[hit.ID]}
+		if item.MatchedOptions == nil {
+			item.MatchedOptions = []uint{}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
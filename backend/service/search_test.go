@@ -0,0 +1,36 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBooleanQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"only spaces and punctuation", "  ，。！ ", ""},
+		{"phrase", "编程语言", `"编程语言"`},
+		{"single character is a prefix", "猫", "猫*"},
+		{"several terms", "Go 语言 吗", `"Go" "语言" 吗*`},
+		{"boolean operators are stripped", `+go -rust "java" (c++) ~php @x <y>`, `"go" "rust" "java" c* "php" x* y*`},
+		{"full width punctuation splits", "前端，后端", `"前端" "后端"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := booleanQuery(tt.in); got != tt.want {
+				t.Errorf("booleanQuery(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBooleanQueryLimitsTerms(t *testing.T) {
+	got := booleanQuery(strings.Repeat("ab ", maxSearchTerms+5))
+	if n := len(strings.Fields(got)); n != maxSearchTerms {
+		t.Errorf("terms = %d, want %d", n, maxSearchTerms)
+	}
+}
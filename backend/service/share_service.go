@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"vote-system-backend/config"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ChartWidth = 600
+
+	OGImageWidth  = 1200
+	OGImageHeight = 630
+	ogImageBars   = 4 // 预览图中最多显示的选项数
+)
+
+type ShareService struct{}
+
+func NewShareService() *ShareService {
+	return &ShareService{}
+}
+
+// shareResults 加载分享用的投票和结果。分享链接无需登录，按未登录用户判断结果是否可见，
+// 不可见时 results 为 nil
+func shareResults(id uint) (*model.Vote, *dto.ResultsResponse, error) {
+	var vote model.Vote
+	if err := database.GetDB().Preload("Options").First(&vote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("投票不存在")
+		}
+		return nil, nil, err
+	}
+	if !canViewResults(&vote, 0) {
+		return &vote, nil, nil
+	}
+
+	results, err := currentResults(&vote)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &vote, results, nil
+}
+
+// optionPercent 横条使用的占比，加权投票按权重计算
+func optionPercent(results *dto.ResultsResponse, option dto.ResultOption) float64 {
+	if results.Weighted {
+		return option.PercentOfWeight
+	}
+	return option.PercentOfVoters
+}
+
+// leadingOption 返回唯一领先的选项，平票或无人投票时返回 nil
+func leadingOption(results *dto.ResultsResponse) *dto.ShareLeadingOption {
+	if results == nil || len(results.Winners) != 1 {
+		return nil
+	}
+	for _, option := range results.Options {
+		if option.OptionID == results.Winners[0] {
+			return &dto.ShareLeadingOption{
+				OptionID: option.OptionID,
+				Content:  option.Content,
+				Percent:  optionPercent(results, option),
+			}
+		}
+	}
+	return nil
+}
+
+// shareSummary 生成一句话摘要，如“共 10 人参与 · 已结束 · 领先：Go 50%”
+func shareSummary(vote *model.Vote, results *dto.ResultsResponse) string {
+	state := "进行中"
+	if voteEnded(vote) {
+		state = "已结束"
+	}
+	if results == nil {
+		return state + " · 结果暂不公开"
+	}
+
+	parts := []string{fmt.Sprintf("共 %d 人参与", results.Turnout.Voters), state}
+	if leading := leadingOption(results); leading != nil {
+		parts = append(parts, fmt.Sprintf("领先：%s %g%%", leading.Content, leading.Percent))
+	} else if results.Tie {
+		parts = append(parts, "平票")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// buildChart 生成横条图，limit 大于 0 时只保留排名靠前的选项
+func buildChart(vote *model.Vote, results *dto.ResultsResponse, limit int) *utils.Chart {
+	chart := &utils.Chart{
+		Title:    vote.Title,
+		Subtitle: shareSummary(vote, results),
+	}
+	if results == nil {
+		chart.Note = "投票结果将在投票后或投票结束后公布"
+		return chart
+	}
+
+	options := append([]dto.ResultOption(nil), results.Options...)
+	if limit > 0 && len(options) > limit {
+		sort.SliceStable(options, func(i, j int) bool { return options[i].Rank < options[j].Rank })
+		options = options[:limit]
+	}
+	for _, option := range options {
+		pct := optionPercent(results, option)
+		chart.Bars = append(chart.Bars, utils.ChartBar{
+			Label: option.Content,
+			Ratio: pct / 100,
+			Value: fmt.Sprintf("%d 票 · %g%%", option.Count, pct),
+		})
+	}
+	if len(chart.Bars) == 0 {
+		chart.Note = "暂无选项"
+	}
+	return chart
+}
+
+// ChartSVG 渲染投票当前结果的 SVG 横条图
+func (s *ShareService) ChartSVG(id uint) ([]byte, error) {
+	vote, results, err := shareResults(id)
+	if err != nil {
+		return nil, err
+	}
+	return buildChart(vote, results, 0).RenderSVG(ChartWidth), nil
+}
+
+// ChartPNG 渲染投票当前结果的 PNG 横条图，按两倍分辨率输出
+func (s *ShareService) ChartPNG(id uint) ([]byte, error) {
+	vote, results, err := shareResults(id)
+	if err != nil {
+		return nil, err
+	}
+	return buildChart(vote, results, 0).RenderPNG(ChartWidth*2, 0, 2)
+}
+
+// OGImage 渲染 1200x630 的 Open Graph 预览图
+func (s *ShareService) OGImage(id uint) ([]byte, error) {
+	vote, results, err := shareResults(id)
+	if err != nil {
+		return nil, err
+	}
+	return buildChart(vote, results, ogImageBars).RenderPNG(OGImageWidth, OGImageHeight, 2)
+}
+
+// GetMeta 返回链接预览的元数据。链接使用配置的站点地址而不是请求的 Host，
+// 元数据允许公共缓存，伪造的 Host 会污染缓存并把访问者跳转到其他站点
+func (s *ShareService) GetMeta(id uint) (*dto.ShareMeta, error) {
+	vote, results, err := shareResults(id)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := config.Get().PublicURL
+
+	prefix := fmt.Sprintf("%s/api/share/%d", baseURL, vote.ID)
+	meta := &dto.ShareMeta{
+		VoteID:         vote.ID,
+		Title:          vote.Title,
+		Description:    shareSummary(vote, results),
+		URL:            fmt.Sprintf("%s/vote/%d", baseURL, vote.ID),
+		ImageURL:       prefix + "/og.png",
+		ImageWidth:     OGImageWidth,
+		ImageHeight:    OGImageHeight,
+		ChartSVGURL:    prefix + "/chart.svg",
+		ChartPNGURL:    prefix + "/chart.png",
+		Ended:          voteEnded(vote),
+		ResultsVisible: results != nil,
+		LeadingOption:  leadingOption(results),
+	}
+	if results != nil {
+		meta.Voters = results.Turnout.Voters
+		meta.Tie = results.Tie
+	}
+	return meta, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"vote-system-backend/config"
+	"vote-system-backend/dto"
+)
+
+func TestGetMetaUsesPublicURL(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 1)
+
+	cfg := config.Get()
+	previous := cfg.PublicURL
+	cfg.PublicURL = "https://vote.example.com"
+	defer func() { cfg.PublicURL = previous }()
+
+	vote, err := NewVoteService().CreateVote(&dto.CreateVoteRequest{Title: "团建地点", Options: []string{"海边", "山里"}}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := NewShareService().GetMeta(vote.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"url", meta.URL, "https://vote.example.com/vote/1"},
+		{"image_url", meta.ImageURL, "https://vote.example.com/api/share/1/og.png"},
+		{"chart_svg_url", meta.ChartSVGURL, "https://vote.example.com/api/share/1/chart.svg"},
+		{"chart_png_url", meta.ChartPNGURL, "https://vote.example.com/api/share/1/chart.png"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
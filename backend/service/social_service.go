@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SocialService struct{}
+
+func NewSocialService() *SocialService {
+	return &SocialService{}
+}
+
+// BookmarkedVote 收藏列表中的一个投票
+type BookmarkedVote struct {
+	model.Vote
+	BookmarkedAt time.Time `json:"bookmarked_at"`
+}
+
+type BookmarkList struct {
+	Items      []BookmarkedVote `json:"items"`
+	Total      int64            `json:"total"`
+	NextCursor string           `json:"next_cursor"`
+	HasMore    bool             `json:"has_more"`
+}
+
+// isBookmarked 判断用户是否收藏了投票
+func isBookmarked(voteID, userID uint) bool {
+	var count int64
+	database.GetDB().Model(&model.Bookmark{}).Where("user_id = ? AND vote_id = ?", userID, voteID).Count(&count)
+	return count > 0
+}
+
+// Follow 关注用户，重复关注不报错
+func (s *SocialService) Follow(followeeID, userID uint) error {
+	if followeeID == userID {
+		return errors.New("不能关注自己")
+	}
+	var user model.User
+	if err := database.GetDB().Select("id").First(&user, followeeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+
+	follow := model.Follow{FollowerID: userID, FolloweeID: followeeID}
+	return database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error
+}
+
+func (s *SocialService) Unfollow(followeeID, userID uint) error {
+	return database.GetDB().Where("follower_id = ? AND followee_id = ?", userID, followeeID).Delete(&model.Follow{}).Error
+}
+
+// GetFollowing 分页返回我关注的用户，最近关注的在前
+func (s *SocialService) GetFollowing(query *dto.PageQuery, userID uint) (*dto.FollowList, error) {
+	return followList(query, "follower_id", "followee_id", userID)
+}
+
+// GetFollowers 分页返回关注我的用户，最近关注的在前
+func (s *SocialService) GetFollowers(query *dto.PageQuery, userID uint) (*dto.FollowList, error) {
+	return followList(query, "followee_id", "follower_id", userID)
+}
+
+// followList 按关注记录倒序分页，column 为当前用户所在的列，other 为列表中用户所在的列。
+// 已注销的用户不显示
+func followList(query *dto.PageQuery, column, other string, userID uint) (*dto.FollowList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	follows := func() *gorm.DB {
+		return database.GetDB().Model(&model.Follow{}).
+			Joins("JOIN users ON users.id = follows."+other+" AND users.deleted_at IS NULL").
+			Where("follows."+column+" = ?", userID)
+	}
+
+	list := &dto.FollowList{Items: []dto.FollowUser{}}
+	if err := follows().Count(&list.Total).Error; err != nil {
+		return nil, err
+	}
+
+	db := follows()
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("follows.id < ?", cursor.ID)
+	}
+	var rows []struct {
+		ID        uint
+		UserID    uint
+		Username  string
+		CreatedAt time.Time
+	}
+	err := db.Select("follows.id, follows." + other + " AS user_id, users.username, follows.created_at").
+		Order("follows.id DESC").
+		Limit(limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		list.HasMore = true
+		list.NextCursor = listCursor{ID: rows[limit-1].ID}.encode()
+	}
+
+	for _, row := range rows {
+		list.Items = append(list.Items, dto.FollowUser{
+			UserID:     row.UserID,
+			Username:   row.Username,
+			FollowedAt: row.CreatedAt,
+		})
+	}
+	return list, nil
+}
+
+// GetFeed 分页返回我关注的用户创建的投票，最新创建的在前
+func (s *SocialService) GetFeed(query *dto.PageQuery, userID uint) (*VoteList, error) {
+	followed := func(db *gorm.DB) *gorm.DB {
+		return db.Where("creator_id IN (?)",
+			database.GetDB().Model(&model.Follow{}).Select("followee_id").Where("follower_id = ?", userID))
+	}
+	return listVotes(&dto.VoteListQuery{Cursor: query.Cursor, Limit: query.Limit}, userID, followed)
+}
+
+// Bookmark 收藏投票，重复收藏不报错
+func (s *SocialService) Bookmark(voteID, userID uint) error {
+	if _, err := findVote(voteID); err != nil {
+		return err
+	}
+
+	bookmark := model.Bookmark{UserID: userID, VoteID: voteID}
+	return database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&bookmark).Error
+}
+
+func (s *SocialService) RemoveBookmark(voteID, userID uint) error {
+	return database.GetDB().Where("user_id = ? AND vote_id = ?", userID, voteID).Delete(&model.Bookmark{}).Error
+}
+
+// GetBookmarks 分页返回收藏的投票，最近收藏的在前。已删除的投票不显示
+func (s *SocialService) GetBookmarks(query *dto.PageQuery, userID uint) (*BookmarkList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	bookmarks := func() *gorm.DB {
+		return database.GetDB().Model(&model.Bookmark{}).
+			Joins("JOIN votes ON votes.id = bookmarks.vote_id AND votes.deleted_at IS NULL").
+			Where("bookmarks.user_id = ?", userID)
+	}
+
+	list := &BookmarkList{Items: []BookmarkedVote{}}
+	if err := bookmarks().Count(&list.Total).Error; err != nil {
+		return nil, err
+	}
+
+	db := bookmarks()
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("bookmarks.id < ?", cursor.ID)
+	}
+	var rows []model.Bookmark
+	if err := db.Select("bookmarks.*").Order("bookmarks.id DESC").Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		list.HasMore = true
+		list.NextCursor = listCursor{ID: rows[limit-1].ID}.encode()
+	}
+	if len(rows) == 0 {
+		return list, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.VoteID)
+	}
+	var found []model.Vote
+	if err := database.GetDB().Preload("Options").Preload("Tags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Vote, len(found))
+	for _, vote := range found {
+		byID[vote.ID] = vote
+	}
+
+	// 按收藏顺序排列后再隐藏不可见的结果
+	votes := make([]model.Vote, 0, len(rows))
+	bookmarkedAt := make([]time.Time, 0, len(rows))
+	for _, row := range rows {
+		if vote, ok := byID[row.VoteID]; ok {
+			votes = append(votes, vote)
+			bookmarkedAt = append(bookmarkedAt, row.CreatedAt)
+		}
+	}
+	if err := maskResults(votes, userID); err != nil {
+		return nil, err
+	}
+	for i, vote := range votes {
+		list.Items = append(list.Items, BookmarkedVote{Vote: vote, BookmarkedAt: bookmarkedAt[i]})
+	}
+	return list, nil
+}
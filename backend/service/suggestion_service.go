@@ -0,0 +1,482 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// writeInOptionContent “其他”选项的内容
+const writeInOptionContent = "其他"
+
+// maxPendingSuggestions 每位投票人在一个投票中最多同时待审核的建议数
+const maxPendingSuggestions = 3
+
+type SuggestionService struct{}
+
+func NewSuggestionService() *SuggestionService {
+	return &SuggestionService{}
+}
+
+func suggestionMode(mode string) string {
+	if mode == "" {
+		return model.SuggestionsOff
+	}
+	return mode
+}
+
+func writeInThreshold(threshold int) int {
+	if threshold <= 0 {
+		return 1
+	}
+	return threshold
+}
+
+// normalizeOption 归一化选项内容：统一全角半角和大小写，合并空白，去掉首尾的标点，
+// 归一化后相同的内容视为同一选项
+func normalizeOption(content string) string {
+	content = strings.ToLower(norm.NFKC.String(content))
+	content = strings.Join(strings.Fields(content), " ")
+	return strings.TrimFunc(content, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// checkNewOption 检查新选项是否与已有选项重复
+func checkNewOption(db *gorm.DB, voteID uint, content string) error {
+	if normalizeOption(content) == "" {
+		return errors.New("选项内容不能为空")
+	}
+	var options []model.VoteOption
+	if err := db.Where("vote_id = ?", voteID).Find(&options).Error; err != nil {
+		return err
+	}
+	for _, option := range options {
+		if !option.WriteIn && normalizeOption(option.Content) == normalizeOption(content) {
+			return errors.New("选项已存在")
+		}
+	}
+	return nil
+}
+
+// addSuggestedOption 把建议加入为正式选项
+func addSuggestedOption(tx *gorm.DB, suggestion *model.OptionSuggestion, reviewerID *uint) error {
+	if err := checkNewOption(tx, suggestion.VoteID, suggestion.Content); err != nil {
+		return err
+	}
+	option := model.VoteOption{VoteID: suggestion.VoteID, Content: suggestion.Content}
+	if err := tx.Create(&option).Error; err != nil {
+		return err
+	}
+	suggestion.Status = model.SuggestionApproved
+	suggestion.OptionID = &option.ID
+	suggestion.ReviewedBy = reviewerID
+
+	// 同时审核时只有一个请求能采纳
+	result := tx.Model(&model.OptionSuggestion{}).Where("id = ? AND status = ?", suggestion.ID, model.SuggestionPending).Updates(map[string]interface{}{
+		"status":      suggestion.Status,
+		"option_id":   suggestion.OptionID,
+		"reviewed_by": suggestion.ReviewedBy,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("建议已审核")
+	}
+	return nil
+}
+
+func suggestionResponses(suggestions []model.OptionSuggestion) ([]dto.SuggestionResponse, error) {
+	ids := make([]uint, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		ids = append(ids, suggestion.UserID)
+	}
+	names, err := usernamesByID(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.SuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		responses = append(responses, dto.SuggestionResponse{
+			ID:        suggestion.ID,
+			VoteID:    suggestion.VoteID,
+			UserID:    suggestion.UserID,
+			Username:  names[suggestion.UserID],
+			Content:   suggestion.Content,
+			Status:    suggestion.Status,
+			OptionID:  suggestion.OptionID,
+			CreatedAt: suggestion.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// SuggestOption 建议新选项。投票设置为立即加入时直接成为正式选项，否则等待审核
+func (s *SuggestionService) SuggestOption(voteID uint, req *dto.SuggestOptionRequest, userID uint) (*dto.SuggestionResponse, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if vote.Suggestions != model.SuggestionsImmediate && vote.Suggestions != model.SuggestionsApproval {
+		return nil, errors.New("该投票不接受选项建议")
+	}
+	if voteEnded(vote) {
+		return nil, errors.New("投票已结束")
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if err := checkNewOption(database.GetDB(), vote.ID, content); err != nil {
+		return nil, err
+	}
+
+	var pending []model.OptionSuggestion
+	if err := database.GetDB().Where("vote_id = ? AND status = ?", vote.ID, model.SuggestionPending).Find(&pending).Error; err != nil {
+		return nil, err
+	}
+	mine := 0
+	for _, suggestion := range pending {
+		if normalizeOption(suggestion.Content) == normalizeOption(content) {
+			return nil, errors.New("已有相同的建议等待审核")
+		}
+		if suggestion.UserID == userID {
+			mine++
+		}
+	}
+	if mine >= maxPendingSuggestions {
+		return nil, errors.New("待审核的建议过多")
+	}
+
+	suggestion := model.OptionSuggestion{
+		VoteID:  vote.ID,
+		UserID:  userID,
+		Content: content,
+		Status:  model.SuggestionPending,
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Create(&suggestion).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if vote.Suggestions == model.SuggestionsImmediate {
+		if err := addSuggestedOption(tx, &suggestion, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if suggestion.Status == model.SuggestionApproved {
+		publishState(vote.ID, StateUpdated)
+	}
+	responses, err := suggestionResponses([]model.OptionSuggestion{suggestion})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// GetSuggestions 返回投票的选项建议。拥有编辑权限的用户可以看到全部建议，其他人只能看到自己的
+func (s *SuggestionService) GetSuggestions(voteID uint, query *dto.SuggestionQuery, userID uint) ([]dto.SuggestionResponse, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB().Where("vote_id = ?", vote.ID)
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		db = db.Where("user_id = ?", userID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	var suggestions []model.OptionSuggestion
+	if err := db.Order("id").Find(&suggestions).Error; err != nil {
+		return nil, err
+	}
+	return suggestionResponses(suggestions)
+}
+
+// ReviewSuggestion 采纳或拒绝待审核的建议，需要投票的编辑权限
+func (s *SuggestionService) ReviewSuggestion(id uint, approve bool, userID uint) error {
+	var suggestion model.OptionSuggestion
+	if err := database.GetDB().First(&suggestion, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("建议不存在")
+		}
+		return err
+	}
+	vote, err := findVote(suggestion.VoteID)
+	if err != nil {
+		return err
+	}
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		return errors.New("没有权限审核建议")
+	}
+	if suggestion.Status != model.SuggestionPending {
+		return errors.New("建议已审核")
+	}
+
+	if !approve {
+		return database.GetDB().Model(&suggestion).Updates(map[string]interface{}{
+			"status":      model.SuggestionRejected,
+			"reviewed_by": userID,
+		}).Error
+	}
+	if voteEnded(vote) {
+		return errors.New("投票已结束")
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := addSuggestedOption(tx, &suggestion, &userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	publishState(vote.ID, StateUpdated)
+	return nil
+}
+
+// writeInRow 按原始写法统计的填写内容
+type writeInRow struct {
+	Normalized string
+	Content    string
+	Count      int
+	Weight     float64
+}
+
+// writeInGroups 按归一化内容合并尚未提升的填写内容，票数多的在前
+func writeInGroups(vote *model.Vote) ([]dto.WriteInGroup, error) {
+	var rows []writeInRow
+	err := database.GetDB().Model(&model.WriteInAnswer{}).
+		Select("write_in_answers.normalized, write_in_answers.content, COUNT(*) AS count, SUM(user_votes.weight) AS weight").
+		Joins("JOIN user_votes ON user_votes.id = write_in_answers.user_vote_id").
+		Where("write_in_answers.vote_id = ? AND write_in_answers.promoted_option_id IS NULL", vote.ID).
+		Group("write_in_answers.normalized, write_in_answers.content").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []dto.WriteInGroup{}
+	index := make(map[string]int)
+	best := make(map[string]int) // 每组最常见写法的票数
+	for _, row := range rows {
+		i, ok := index[row.Normalized]
+		if !ok {
+			i = len(groups)
+			index[row.Normalized] = i
+			groups = append(groups, dto.WriteInGroup{Normalized: row.Normalized})
+		}
+		group := &groups[i]
+		group.Count += row.Count
+		group.Weight += row.Weight
+		if row.Count > best[row.Normalized] || (row.Count == best[row.Normalized] && row.Content < group.Content) {
+			best[row.Normalized] = row.Count
+			group.Content = row.Content
+		}
+	}
+	for i := range groups {
+		groups[i].Promotable = groups[i].Count >= vote.WriteInThreshold
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Normalized < groups[j].Normalized
+	})
+	return groups, nil
+}
+
+// GetWriteIns 返回“其他”选项中填写的内容，按归一化内容合并。内容属于计票结果，遵循结果可见性
+func (s *SuggestionService) GetWriteIns(voteID uint, userID uint) (*dto.WriteInResponse, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !vote.WriteIn {
+		return nil, errors.New("该投票未开启填写选项")
+	}
+	if !canViewResults(vote, userID) {
+		return nil, errors.New("投票结果暂不可见")
+	}
+
+	groups, err := writeInGroups(vote)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.WriteInResponse{VoteID: vote.ID, Threshold: vote.WriteInThreshold, Groups: groups}, nil
+}
+
+// PromoteWriteIn 把票数达到阈值的填写内容提升为正式选项。原有投票记录改为指向新选项，
+// 投票时间和权重不变；内容与已有选项相同时并入该选项，已经选择了该选项的投票人不重复计票
+func (s *SuggestionService) PromoteWriteIn(voteID uint, req *dto.PromoteWriteInRequest, userID uint) (*model.VoteOption, error) {
+	vote, err := findVote(voteID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(vote, userID, model.PermissionEdit) {
+		return nil, errors.New("没有权限修改此投票")
+	}
+	if !vote.WriteIn {
+		return nil, errors.New("该投票未开启填写选项")
+	}
+	// 结束后的结果已经冻结，证书和审计都以此为准
+	if voteEnded(vote) {
+		return nil, errors.New("投票已结束，不能提升填写的内容")
+	}
+
+	// 审计依据的是创建审计时的计票结果
+	var audits int64
+	if err := database.GetDB().Model(&model.Audit{}).Where("vote_id = ? AND status = ?", vote.ID, model.AuditInProgress).Count(&audits).Error; err != nil {
+		return nil, err
+	}
+	if audits > 0 {
+		return nil, errors.New("投票正在审计，不能修改选项")
+	}
+
+	groups, err := writeInGroups(vote)
+	if err != nil {
+		return nil, err
+	}
+	var group *dto.WriteInGroup
+	for i := range groups {
+		if groups[i].Normalized == req.Normalized {
+			group = &groups[i]
+		}
+	}
+	if group == nil {
+		return nil, errors.New("填写的内容不存在")
+	}
+	if !group.Promotable {
+		return nil, errors.New("未达到提升所需的票数")
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		content = group.Content
+	}
+	if normalizeOption(content) != group.Normalized {
+		return nil, errors.New("选项内容与填写的内容不一致")
+	}
+
+	var options []model.VoteOption
+	if err := database.GetDB().Where("vote_id = ?", vote.ID).Find(&options).Error; err != nil {
+		return nil, err
+	}
+	var target *model.VoteOption
+	var writeInOption *model.VoteOption
+	for i := range options {
+		if options[i].WriteIn {
+			writeInOption = &options[i]
+		} else if normalizeOption(options[i].Content) == group.Normalized {
+			target = &options[i]
+		}
+	}
+	if writeInOption == nil {
+		return nil, errors.New("该投票未开启填写选项")
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if target == nil {
+		target = &model.VoteOption{VoteID: vote.ID, Content: content}
+		if err := tx.Create(target).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	var answers []model.WriteInAnswer
+	if err := tx.Preload("UserVote").
+		Where("vote_id = ? AND normalized = ? AND promoted_option_id IS NULL", vote.ID, group.Normalized).
+		Find(&answers).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var removed, moved int
+	var removedWeight, movedWeight float64
+	for _, answer := range answers {
+		var existing int64
+		if err := tx.Model(&model.UserVote{}).Where("vote_id = ? AND user_id = ? AND option_id = ?", vote.ID, answer.UserID, target.ID).Count(&existing).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		removed++
+		removedWeight += answer.UserVote.Weight
+		if existing > 0 {
+			// 多选时已经选择了该选项，只去掉“其他”的记录
+			if err := tx.Delete(&model.UserVote{}, answer.UserVoteID).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			continue
+		}
+		if err := tx.Model(&model.UserVote{}).Where("id = ?", answer.UserVoteID).Update("option_id", target.ID).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		moved++
+		movedWeight += answer.UserVote.Weight
+	}
+
+	if err := tx.Model(&model.WriteInAnswer{}).
+		Where("vote_id = ? AND normalized = ? AND promoted_option_id IS NULL", vote.ID, group.Normalized).
+		Update("promoted_option_id", target.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(writeInOption).UpdateColumns(map[string]interface{}{
+		"count":          gorm.Expr("count - ?", removed),
+		"weighted_count": gorm.Expr("weighted_count - ?", removedWeight),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Model(target).UpdateColumns(map[string]interface{}{
+		"count":          gorm.Expr("count + ?", moved),
+		"weighted_count": gorm.Expr("weighted_count + ?", movedWeight),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 修改后需要重新生成最终结果
+	if err := tx.Where("vote_id = ?", vote.ID).Delete(&model.ResultSnapshot{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	publishState(vote.ID, StateUpdated)
+	if err := database.GetDB().First(target, target.ID).Error; err != nil {
+		return nil, err
+	}
+	return target, nil
+}
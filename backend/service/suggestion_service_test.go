@@ -0,0 +1,182 @@
+package service
+
+import (
+	"testing"
+
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+)
+
+func TestPromoteWriteIn(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 5)
+	s := NewSuggestionService()
+	votes := NewVoteService()
+
+	vote, err := votes.CreateVote(&dto.CreateVoteRequest{
+		Title:            "最喜欢的水果",
+		Options:          []string{"苹果", "香蕉"},
+		Multi:            true,
+		WriteIn:          true,
+		WriteInThreshold: 2,
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := optionsByContent(t, vote.ID)
+	writeIn := options[writeInOptionContent]
+
+	// user2、user3 填写“ 芒果 ”和“芒果”，user4 填写“芒果”的同时选择了“苹果”，user5 填写“榴莲”
+	ballots := []struct {
+		userID    uint
+		optionIDs []uint
+		writeIn   string
+	}{
+		{users[1].ID, []uint{writeIn.ID}, " 芒果 "},
+		{users[2].ID, []uint{writeIn.ID}, "芒果"},
+		{users[3].ID, []uint{options["苹果"].ID, writeIn.ID}, "芒果"},
+		{users[4].ID, []uint{writeIn.ID}, "榴莲"},
+	}
+	for _, b := range ballots {
+		if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: b.optionIDs, WriteIn: b.writeIn}, b.userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.PromoteWriteIn(vote.ID, &dto.PromoteWriteInRequest{Normalized: normalizeOption("芒果")}, users[1].ID); err == nil {
+		t.Error("promoted by a user without edit permission")
+	}
+	if _, err := s.PromoteWriteIn(vote.ID, &dto.PromoteWriteInRequest{Normalized: normalizeOption("榴莲")}, users[0].ID); err == nil {
+		t.Error("promoted a write-in below the threshold")
+	}
+
+	option, err := s.PromoteWriteIn(vote.ID, &dto.PromoteWriteInRequest{Normalized: normalizeOption("芒果")}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if option.Content != "芒果" || option.Count != 3 || option.WeightedCount != 3 {
+		t.Errorf("promoted option = %q count %d weighted %v, want 芒果 3 3", option.Content, option.Count, option.WeightedCount)
+	}
+
+	updated := optionsByContent(t, vote.ID)
+	if got := updated[writeInOptionContent]; got.Count != 1 || got.WeightedCount != 1 {
+		t.Errorf("write-in option = count %d weighted %v, want 1 1", got.Count, got.WeightedCount)
+	}
+	if got := updated["苹果"]; got.Count != 1 {
+		t.Errorf("option 苹果 count = %d, want 1", got.Count)
+	}
+
+	// 选票改为指向新选项，投票时间不变
+	var moved []model.UserVote
+	if err := database.GetDB().Where("option_id = ?", option.ID).Order("user_id").Find(&moved).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(moved) != 3 || moved[0].UserID != users[1].ID || moved[2].UserID != users[3].ID {
+		t.Errorf("ballots on promoted option = %+v, want users 2 to 4", moved)
+	}
+
+	var answers []model.WriteInAnswer
+	if err := database.GetDB().Where("vote_id = ? AND promoted_option_id IS NULL", vote.ID).Find(&answers).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 1 || answers[0].Content != "榴莲" {
+		t.Errorf("unpromoted answers = %+v, want only 榴莲", answers)
+	}
+
+	// 已经提升的内容不能再次提升
+	if _, err := s.PromoteWriteIn(vote.ID, &dto.PromoteWriteInRequest{Normalized: normalizeOption("芒果")}, users[0].ID); err == nil {
+		t.Error("promoted the same write-in twice")
+	}
+}
+
+func TestPromoteWriteInMergesIntoExistingOption(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 3)
+	s := NewSuggestionService()
+	votes := NewVoteService()
+
+	vote, err := votes.CreateVote(&dto.CreateVoteRequest{
+		Title:            "会议地点",
+		Options:          []string{"一楼", "二楼"},
+		Multi:            true,
+		WriteIn:          true,
+		WriteInThreshold: 1,
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := optionsByContent(t, vote.ID)
+	writeIn := options[writeInOptionContent]
+
+	// user2 只填写了“二楼”，user3 选择了“二楼”又填写了“二楼”
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{writeIn.ID}, WriteIn: "二楼"}, users[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{options["二楼"].ID, writeIn.ID}, WriteIn: "二楼"}, users[2].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	option, err := s.PromoteWriteIn(vote.ID, &dto.PromoteWriteInRequest{Normalized: normalizeOption("二楼")}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if option.ID != options["二楼"].ID {
+		t.Fatalf("promoted into option %d, want existing option %d", option.ID, options["二楼"].ID)
+	}
+	if option.Count != 2 {
+		t.Errorf("merged option count = %d, want 2", option.Count)
+	}
+
+	var ballots int64
+	if err := database.GetDB().Model(&model.UserVote{}).Where("vote_id = ? AND user_id = ?", vote.ID, users[2].ID).Count(&ballots).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ballots != 1 {
+		t.Errorf("user 3 has %d ballots, want 1", ballots)
+	}
+	if got := optionsByContent(t, vote.ID)[writeInOptionContent]; got.Count != 0 {
+		t.Errorf("write-in option count = %d, want 0", got.Count)
+	}
+}
+
+func TestPromoteWriteInRefusesEndedVote(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 2)
+	s := NewSuggestionService()
+	votes := NewVoteService()
+
+	vote, err := votes.CreateVote(&dto.CreateVoteRequest{
+		Title:            "团建地点",
+		Options:          []string{"海边", "山里"},
+		WriteIn:          true,
+		WriteInThreshold: 1,
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeIn := optionsByContent(t, vote.ID)[writeInOptionContent]
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{writeIn.ID}, WriteIn: "草原"}, users[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.GetDB().Model(&model.Vote{}).Where("id = ?", vote.ID).Update("closed", true).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := database.GetDB().Create(&model.ResultSnapshot{VoteID: vote.ID, Payload: "{}"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.PromoteWriteIn(vote.ID, &dto.PromoteWriteInRequest{Normalized: normalizeOption("草原")}, users[0].ID); err == nil {
+		t.Fatal("promoted a write-in after the vote ended")
+	}
+	var snapshots int64
+	if err := database.GetDB().Model(&model.ResultSnapshot{}).Where("vote_id = ?", vote.ID).Count(&snapshots).Error; err != nil {
+		t.Fatal(err)
+	}
+	if snapshots != 1 {
+		t.Error("final results were discarded")
+	}
+	if got := optionsByContent(t, vote.ID)[writeInOptionContent]; got.Count != 1 {
+		t.Errorf("write-in option count = %d, want 1", got.Count)
+	}
+}
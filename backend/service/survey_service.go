@@ -0,0 +1,793 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultScaleMin = 1
+	defaultScaleMax = 5
+	maxScalePoints  = 11   // 量表最多的分值个数
+	maxSurveyNumber = 1e14 // 数字题回答的绝对值上限，与 decimal(20,6) 对应
+	surveyTextLimit = 50   // 结果中返回的文本回答数
+)
+
+type SurveyService struct{}
+
+func NewSurveyService() *SurveyService {
+	return &SurveyService{}
+}
+
+// SurveyWithStatus 问卷详情及当前用户是否已提交
+type SurveyWithStatus struct {
+	model.Survey
+	HasResponded  bool `json:"has_responded"`
+	ResultsHidden bool `json:"results_hidden"`
+}
+
+type SurveyList struct {
+	Items      []model.Survey `json:"items"`
+	Total      int64          `json:"total"`
+	NextCursor string         `json:"next_cursor"`
+	HasMore    bool           `json:"has_more"`
+}
+
+func isChoiceQuestion(questionType string) bool {
+	return questionType == model.QuestionSingle || questionType == model.QuestionMulti
+}
+
+// checkNotSurveyVote 问卷中的选择题只能通过问卷提交和管理
+func checkNotSurveyVote(vote *model.Vote) error {
+	if vote.SurveyID != nil {
+		return errors.New("该投票属于问卷，请通过问卷操作")
+	}
+	return nil
+}
+
+func findSurvey(id uint) (*model.Survey, error) {
+	var survey model.Survey
+	if err := database.GetDB().First(&survey, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("问卷不存在")
+		}
+		return nil, err
+	}
+	return &survey, nil
+}
+
+// canEditSurvey 只有创建者可以修改问卷
+func canEditSurvey(survey *model.Survey, userID uint) bool {
+	return survey.CreatorID == userID
+}
+
+func surveyEnded(survey *model.Survey) bool {
+	return survey.Closed || (survey.Deadline > 0 && time.Now().Unix() > survey.Deadline)
+}
+
+func hasResponded(surveyID, userID uint) bool {
+	var count int64
+	database.GetDB().Model(&model.SurveyResponse{}).Where("survey_id = ? AND user_id = ?", surveyID, userID).Count(&count)
+	return count > 0
+}
+
+// canViewSurveyResults 按问卷的结果可见性判断，创建者始终可见
+func canViewSurveyResults(survey *model.Survey, userID uint) bool {
+	if canEditSurvey(survey, userID) {
+		return true
+	}
+	switch survey.ResultVisibility {
+	case model.ResultsAfterVote:
+		return surveyEnded(survey) || hasResponded(survey.ID, userID)
+	case model.ResultsAfterClose:
+		return surveyEnded(survey)
+	}
+	return true
+}
+
+// buildQuestions 检查问题设置并补全默认值，选择题的投票在 createQuestions 中创建
+func buildQuestions(reqs []dto.SurveyQuestionRequest) ([]model.SurveyQuestion, [][]string, error) {
+	questions := make([]model.SurveyQuestion, 0, len(reqs))
+	options := make([][]string, 0, len(reqs))
+	for i, req := range reqs {
+		position := i + 1
+		question := model.SurveyQuestion{
+			Position:        position,
+			Type:            req.Type,
+			Title:           strings.TrimSpace(req.Title),
+			Description:     req.Description,
+			DescriptionHTML: utils.RenderMarkdown(req.Description),
+			Required:        req.Required,
+		}
+		if question.Title == "" {
+			return nil, nil, fmt.Errorf("第%d题的标题不能为空", position)
+		}
+
+		var choices []string
+		if len(req.Options) > 0 && !isChoiceQuestion(req.Type) {
+			return nil, nil, fmt.Errorf("第%d题不是选择题，不能设置选项", position)
+		}
+		switch req.Type {
+		case model.QuestionSingle, model.QuestionMulti:
+			for _, option := range req.Options {
+				if option = strings.TrimSpace(option); option != "" {
+					choices = append(choices, option)
+				}
+			}
+			if len(choices) < 2 {
+				return nil, nil, fmt.Errorf("第%d题至少需要2个选项", position)
+			}
+		case model.QuestionLikert:
+			question.ScaleMin, question.ScaleMax = req.ScaleMin, req.ScaleMax
+			if question.ScaleMin == 0 && question.ScaleMax == 0 {
+				question.ScaleMin, question.ScaleMax = defaultScaleMin, defaultScaleMax
+			}
+			if question.ScaleMax <= question.ScaleMin || question.ScaleMax-question.ScaleMin+1 > maxScalePoints {
+				return nil, nil, fmt.Errorf("第%d题的量表范围无效，最多%d个分值", position, maxScalePoints)
+			}
+			question.MinLabel, question.MaxLabel = req.MinLabel, req.MaxLabel
+		case model.QuestionNPS:
+			question.ScaleMin, question.ScaleMax = 0, 10
+			question.MinLabel, question.MaxLabel = req.MinLabel, req.MaxLabel
+		case model.QuestionNumber:
+			if req.NumberMin != nil && req.NumberMax != nil && *req.NumberMin > *req.NumberMax {
+				return nil, nil, fmt.Errorf("第%d题的数字范围无效", position)
+			}
+			question.NumberMin, question.NumberMax = req.NumberMin, req.NumberMax
+		}
+		questions = append(questions, question)
+		options = append(options, choices)
+	}
+	return questions, options, nil
+}
+
+// createQuestions 在事务中创建问题，单选和多选题各创建一个属于问卷的投票
+func createQuestions(tx *gorm.DB, survey *model.Survey, questions []model.SurveyQuestion, options [][]string) error {
+	for i := range questions {
+		question := &questions[i]
+		question.SurveyID = survey.ID
+		if isChoiceQuestion(question.Type) {
+			vote := model.Vote{
+				Title:            question.Title,
+				Description:      question.Description,
+				DescriptionHTML:  question.DescriptionHTML,
+				Multi:            question.Type == model.QuestionMulti,
+				Deadline:         survey.Deadline,
+				Closed:           survey.Closed,
+				ResultVisibility: survey.ResultVisibility,
+				CreatorID:        survey.CreatorID,
+				SurveyID:         &survey.ID,
+			}
+			for _, content := range options[i] {
+				vote.Options = append(vote.Options, model.VoteOption{Content: content})
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return err
+			}
+			question.VoteID = &vote.ID
+		}
+		if err := tx.Create(question).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeQuestions 在事务中删除问卷的全部问题，返回被删除的投票和需要清理文件的选项图片
+func removeQuestions(tx *gorm.DB, surveyID uint) ([]uint, []model.OptionImage, error) {
+	var voteIDs []uint
+	if err := tx.Model(&model.Vote{}).Where("survey_id = ?", surveyID).Pluck("id", &voteIDs).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Where("survey_id = ?", surveyID).Delete(&model.SurveyQuestion{}).Error; err != nil {
+		return nil, nil, err
+	}
+	images, err := removeSurveyVotes(tx, voteIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return voteIDs, images, nil
+}
+
+// removeSurveyVotes 在事务中删除问卷选择题的投票及其选项图片记录。投票是软删除，
+// 返回的图片文件需要在事务提交后清理
+func removeSurveyVotes(tx *gorm.DB, voteIDs []uint) ([]model.OptionImage, error) {
+	if len(voteIDs) == 0 {
+		return nil, nil
+	}
+	var images []model.OptionImage
+	if err := tx.Where("vote_id IN ?", voteIDs).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("vote_id IN ?", voteIDs).Delete(&model.OptionImage{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("id IN ?", voteIDs).Delete(&model.Vote{}).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (s *SurveyService) CreateSurvey(req *dto.CreateSurveyRequest, userID uint) (*model.Survey, error) {
+	questions, options, err := buildQuestions(req.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	survey := model.Survey{
+		Title:            req.Title,
+		Description:      req.Description,
+		DescriptionHTML:  utils.RenderMarkdown(req.Description),
+		Deadline:         req.Deadline,
+		ResultVisibility: resultVisibility(req.ResultVisibility),
+		CreatorID:        userID,
+	}
+
+	tx := database.GetDB().Begin()
+	if err := tx.Create(&survey).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := createQuestions(tx, &survey, questions, options); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	survey.Questions = questions
+	return &survey, nil
+}
+
+// loadQuestions 按顺序加载问题，选择题同时加载投票和选项
+func loadQuestions(db *gorm.DB, surveyID uint) ([]model.SurveyQuestion, error) {
+	var questions []model.SurveyQuestion
+	err := db.Where("survey_id = ?", surveyID).
+		Preload("Vote").
+		Preload("Vote.Options", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Order("position").
+		Find(&questions).Error
+	return questions, err
+}
+
+func (s *SurveyService) GetSurvey(id, userID uint) (*SurveyWithStatus, error) {
+	survey, err := findSurvey(id)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := loadQuestions(database.GetDB(), survey.ID)
+	if err != nil {
+		return nil, err
+	}
+	survey.Questions = questions
+
+	resultsHidden := !canViewSurveyResults(survey, userID)
+	if resultsHidden {
+		for _, question := range survey.Questions {
+			if question.Vote != nil {
+				hideResults(question.Vote)
+			}
+		}
+	}
+
+	return &SurveyWithStatus{
+		Survey:        *survey,
+		HasResponded:  hasResponded(survey.ID, userID),
+		ResultsHidden: resultsHidden,
+	}, nil
+}
+
+// GetMySurveys 分页返回我创建的问卷，最新的在前
+func (s *SurveyService) GetMySurveys(query *dto.PageQuery, userID uint) (*SurveyList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+
+	list := &SurveyList{Items: []model.Survey{}}
+	if err := database.GetDB().Model(&model.Survey{}).Where("creator_id = ?", userID).Count(&list.Total).Error; err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB().Where("creator_id = ?", userID)
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where("id < ?", cursor.ID)
+	}
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&list.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(list.Items) > limit {
+		list.Items = list.Items[:limit]
+		list.HasMore = true
+		list.NextCursor = listCursor{ID: list.Items[limit-1].ID}.encode()
+	}
+	return list, nil
+}
+
+// UpdateSurvey 修改问卷，截止时间和结果可见性同步到选择题的投票。
+// 已有回答时只能修改问卷信息，不能修改问题
+func (s *SurveyService) UpdateSurvey(id uint, req *dto.UpdateSurveyRequest, userID uint) error {
+	survey, err := findSurvey(id)
+	if err != nil {
+		return err
+	}
+	if !canEditSurvey(survey, userID) {
+		return errors.New("没有权限修改此问卷")
+	}
+	questions, options, err := buildQuestions(req.Questions)
+	if err != nil {
+		return err
+	}
+
+	tx := database.GetDB().Begin()
+
+	// 锁定问卷，与提交互斥，避免回答对应到被替换的问题
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(survey, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if len(questions) > 0 && survey.ResponseCount > 0 {
+		tx.Rollback()
+		return errors.New("问卷已有回答，不能修改问题")
+	}
+
+	survey.Title = req.Title
+	survey.Description = req.Description
+	survey.DescriptionHTML = utils.RenderMarkdown(req.Description)
+	survey.Deadline = req.Deadline
+	survey.ResultVisibility = resultVisibility(req.ResultVisibility)
+	// 回答数由提交原子更新，这里不覆盖
+	if err := tx.Omit("ResponseCount", "Questions").Save(survey).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var removed []uint
+	var images []model.OptionImage
+	if len(questions) > 0 {
+		if removed, images, err = removeQuestions(tx, survey.ID); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := createQuestions(tx, survey, questions, options); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Model(&model.Vote{}).Where("survey_id = ?", survey.ID).Updates(map[string]interface{}{
+		"deadline":          survey.Deadline,
+		"result_visibility": survey.ResultVisibility,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	var updated []uint
+	if err := tx.Model(&model.Vote{}).Where("survey_id = ?", survey.ID).Pluck("id", &updated).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	deleteImageFiles(images)
+
+	for _, voteID := range removed {
+		publishState(voteID, StateDeleted)
+	}
+	// 截止时间和结果可见性同步到了选择题的投票，实时连接需要重新计时和判断可见性
+	for _, voteID := range updated {
+		publishState(voteID, StateUpdated)
+	}
+	return nil
+}
+
+// DeleteSurvey 删除问卷及其选择题的投票，只有创建者可以删除
+func (s *SurveyService) DeleteSurvey(id, userID uint) error {
+	survey, err := findSurvey(id)
+	if err != nil {
+		return err
+	}
+	if survey.CreatorID != userID {
+		return errors.New("没有权限删除此问卷")
+	}
+
+	var voteIDs []uint
+	var images []model.OptionImage
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Vote{}).Where("survey_id = ?", survey.ID).Pluck("id", &voteIDs).Error; err != nil {
+			return err
+		}
+		var err error
+		if images, err = removeSurveyVotes(tx, voteIDs); err != nil {
+			return err
+		}
+		return tx.Delete(survey).Error
+	})
+	if err != nil {
+		return err
+	}
+	deleteImageFiles(images)
+
+	for _, voteID := range voteIDs {
+		publishState(voteID, StateDeleted)
+	}
+	return nil
+}
+
+// CloseSurvey 提前结束问卷，选择题的投票一起关闭
+func (s *SurveyService) CloseSurvey(id, userID uint) error {
+	survey, err := findSurvey(id)
+	if err != nil {
+		return err
+	}
+	if !canEditSurvey(survey, userID) {
+		return errors.New("没有权限关闭此问卷")
+	}
+	if survey.Closed {
+		return errors.New("问卷已关闭")
+	}
+
+	var voteIDs []uint
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(survey).Update("closed", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Vote{}).Where("survey_id = ?", survey.ID).Pluck("id", &voteIDs).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Vote{}).Where("survey_id = ?", survey.ID).Update("closed", true).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, voteID := range voteIDs {
+		publishState(voteID, StateClosed)
+	}
+	return nil
+}
+
+// scaleInteger 检查量表和 NPS 题的回答是否为范围内的整数
+func scaleInteger(question *model.SurveyQuestion, value float64) bool {
+	return value == math.Trunc(value) && value >= float64(question.ScaleMin) && value <= float64(question.ScaleMax)
+}
+
+// checkAnswer 按问题类型检查回答，返回回答是否为空
+func checkAnswer(question *model.SurveyQuestion, answer *dto.SurveyAnswerRequest) (bool, error) {
+	switch question.Type {
+	case model.QuestionSingle, model.QuestionMulti:
+		if len(answer.OptionIDs) == 0 {
+			return true, nil
+		}
+		selected, err := checkSelection(question.Vote, answer.OptionIDs)
+		if errors.Is(err, errSingleChoice) {
+			return false, fmt.Errorf("第%d题只能选择一个选项", question.Position)
+		}
+		if err != nil {
+			return false, fmt.Errorf("第%d题的选项无效", question.Position)
+		}
+		answer.OptionIDs = selected
+	case model.QuestionText:
+		answer.Text = strings.TrimSpace(answer.Text)
+		if answer.Text == "" {
+			return true, nil
+		}
+	case model.QuestionLikert, model.QuestionNPS:
+		if answer.Number == nil {
+			return true, nil
+		}
+		if !scaleInteger(question, *answer.Number) {
+			return false, fmt.Errorf("第%d题的分值应为%d到%d的整数", question.Position, question.ScaleMin, question.ScaleMax)
+		}
+	case model.QuestionNumber:
+		if answer.Number == nil {
+			return true, nil
+		}
+		value := *answer.Number
+		if math.Abs(value) >= maxSurveyNumber {
+			return false, fmt.Errorf("第%d题的数字超出范围", question.Position)
+		}
+		if (question.NumberMin != nil && value < *question.NumberMin) || (question.NumberMax != nil && value > *question.NumberMax) {
+			return false, fmt.Errorf("第%d题的数字超出允许的范围", question.Position)
+		}
+	}
+	return false, nil
+}
+
+// SubmitSurvey 在一个事务中提交问卷的全部回答。选择题的回答作为对应投票的选票记录，
+// 其余回答保存在 SurveyAnswer 中
+func (s *SurveyService) SubmitSurvey(id uint, req *dto.SubmitSurveyRequest, userID uint) error {
+	answers := make(map[uint]*dto.SurveyAnswerRequest, len(req.Answers))
+	for i := range req.Answers {
+		answer := &req.Answers[i]
+		if answers[answer.QuestionID] != nil {
+			return errors.New("同一问题只能回答一次")
+		}
+		answers[answer.QuestionID] = answer
+	}
+
+	type ballot struct {
+		vote      *model.Vote
+		optionIDs []uint
+	}
+	var ballots []ballot
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// 锁定问卷，同一用户的并发提交和问题修改依次进行
+		var survey model.Survey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&survey, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("问卷不存在")
+			}
+			return err
+		}
+		if surveyEnded(&survey) {
+			return errors.New("问卷已结束")
+		}
+		var count int64
+		if err := tx.Model(&model.SurveyResponse{}).Where("survey_id = ? AND user_id = ?", survey.ID, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("已提交过问卷")
+		}
+
+		questions, err := loadQuestions(tx, survey.ID)
+		if err != nil {
+			return err
+		}
+		known := make(map[uint]bool, len(questions))
+		for _, question := range questions {
+			known[question.ID] = true
+		}
+		for questionID := range answers {
+			if !known[questionID] {
+				return errors.New("无效的问题")
+			}
+		}
+
+		response := model.SurveyResponse{SurveyID: survey.ID, UserID: userID}
+		for i := range questions {
+			question := &questions[i]
+			answer := answers[question.ID]
+			empty := true
+			if answer != nil {
+				if empty, err = checkAnswer(question, answer); err != nil {
+					return err
+				}
+			}
+			if empty {
+				if question.Required {
+					return fmt.Errorf("第%d题为必答题", question.Position)
+				}
+				continue
+			}
+
+			if isChoiceQuestion(question.Type) {
+				ballots = append(ballots, ballot{vote: question.Vote, optionIDs: answer.OptionIDs})
+				continue
+			}
+			stored := model.SurveyAnswer{QuestionID: question.ID}
+			if question.Type == model.QuestionText {
+				stored.Text = answer.Text
+			} else {
+				stored.Number = answer.Number
+			}
+			response.Answers = append(response.Answers, stored)
+		}
+
+		if err := tx.Create(&response).Error; err != nil {
+			return err
+		}
+		for _, b := range ballots {
+			if _, err := castBallot(tx, b.vote, userID, b.optionIDs, 1, nil); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&survey).UpdateColumn("response_count", gorm.Expr("response_count + 1")).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, b := range ballots {
+		publishBallot(b.vote.ID, userID, b.optionIDs, 1)
+	}
+	return nil
+}
+
+// GetResults 返回每个问题的汇总结果
+func (s *SurveyService) GetResults(id, userID uint) (*dto.SurveyResults, error) {
+	survey, err := findSurvey(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canViewSurveyResults(survey, userID) {
+		return nil, errors.New("问卷结果暂不可见")
+	}
+	questions, err := loadQuestions(database.GetDB(), survey.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &dto.SurveyResults{
+		SurveyID:  survey.ID,
+		Title:     survey.Title,
+		Responses: survey.ResponseCount,
+		Questions: make([]dto.SurveyQuestionResult, 0, len(questions)),
+	}
+	for i := range questions {
+		question := &questions[i]
+		result := dto.SurveyQuestionResult{
+			QuestionID: question.ID,
+			Position:   question.Position,
+			Type:       question.Type,
+			Title:      question.Title,
+			Required:   question.Required,
+		}
+
+		switch question.Type {
+		case model.QuestionSingle, model.QuestionMulti:
+			choiceResults(question.Vote, &result)
+		case model.QuestionText:
+			err = textResults(question.ID, &result)
+		case model.QuestionLikert, model.QuestionNPS:
+			err = scaleResults(question, &result)
+		case model.QuestionNumber:
+			err = numberResults(question.ID, &result)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results.Questions = append(results.Questions, result)
+	}
+	return results, nil
+}
+
+func choiceResults(vote *model.Vote, result *dto.SurveyQuestionResult) {
+	result.Answered = vote.VoterCount
+	result.Options = make([]dto.SurveyOptionResult, 0, len(vote.Options))
+	for _, option := range vote.Options {
+		result.Options = append(result.Options, dto.SurveyOptionResult{
+			OptionID: option.ID,
+			Content:  option.Content,
+			Count:    option.Count,
+			Percent:  percent(float64(option.Count), float64(vote.VoterCount)),
+		})
+	}
+}
+
+func textResults(questionID uint, result *dto.SurveyQuestionResult) error {
+	var count int64
+	if err := database.GetDB().Model(&model.SurveyAnswer{}).Where("question_id = ?", questionID).Count(&count).Error; err != nil {
+		return err
+	}
+	result.Answered = int(count)
+	result.Texts = []string{}
+	return database.GetDB().Model(&model.SurveyAnswer{}).
+		Where("question_id = ?", questionID).
+		Order("id DESC").
+		Limit(surveyTextLimit).
+		Pluck("text", &result.Texts).Error
+}
+
+// roundStat 保留两位小数
+func roundStat(value float64) *float64 {
+	rounded := math.Round(value*100) / 100
+	return &rounded
+}
+
+// scaleResults 统计量表和 NPS 题每个分值的人数、平均值和中位数
+func scaleResults(question *model.SurveyQuestion, result *dto.SurveyQuestionResult) error {
+	var rows []struct {
+		Value float64
+		Count int
+	}
+	err := database.GetDB().Model(&model.SurveyAnswer{}).
+		Select("number AS value, COUNT(*) AS count").
+		Where("question_id = ? AND number IS NOT NULL", question.ID).
+		Group("number").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	counts := make(map[int]int, len(rows))
+	for _, row := range rows {
+		counts[int(row.Value)] += row.Count
+	}
+
+	sum := 0
+	for value := question.ScaleMin; value <= question.ScaleMax; value++ {
+		count := counts[value]
+		result.Distribution = append(result.Distribution, dto.SurveyBucket{Value: value, Count: count})
+		result.Answered += count
+		sum += value * count
+	}
+	if result.Answered == 0 {
+		return nil
+	}
+	result.Average = roundStat(float64(sum) / float64(result.Answered))
+
+	// 中位数：偶数个回答时取中间两个的平均
+	lower, upper := (result.Answered-1)/2, result.Answered/2
+	var lowerValue, upperValue, seen int
+	for _, bucket := range result.Distribution {
+		if seen <= lower && lower < seen+bucket.Count {
+			lowerValue = bucket.Value
+		}
+		if seen <= upper && upper < seen+bucket.Count {
+			upperValue = bucket.Value
+		}
+		seen += bucket.Count
+	}
+	result.Median = roundStat(float64(lowerValue+upperValue) / 2)
+
+	if question.Type == model.QuestionNPS {
+		for _, bucket := range result.Distribution {
+			switch {
+			case bucket.Value >= 9:
+				result.Promoters += bucket.Count
+			case bucket.Value >= 7:
+				result.Passives += bucket.Count
+			default:
+				result.Detractors += bucket.Count
+			}
+		}
+		result.NPS = roundStat(float64(result.Promoters-result.Detractors) * 100 / float64(result.Answered))
+	}
+	return nil
+}
+
+// numberResults 统计数字题的平均值、中位数和范围
+func numberResults(questionID uint, result *dto.SurveyQuestionResult) error {
+	answers := func() *gorm.DB {
+		return database.GetDB().Model(&model.SurveyAnswer{}).Where("question_id = ? AND number IS NOT NULL", questionID)
+	}
+	var stats struct {
+		Count int
+		Avg   float64
+		Min   float64
+		Max   float64
+	}
+	if err := answers().Select("COUNT(*) AS count, COALESCE(AVG(number), 0) AS avg, COALESCE(MIN(number), 0) AS min, COALESCE(MAX(number), 0) AS max").Scan(&stats).Error; err != nil {
+		return err
+	}
+	result.Answered = stats.Count
+	if stats.Count == 0 {
+		return nil
+	}
+	result.Average = roundStat(stats.Avg)
+	result.Min = &stats.Min
+	result.Max = &stats.Max
+
+	// 中位数：偶数个回答时取中间两个的平均
+	var middle []float64
+	offset, limit := (stats.Count-1)/2, 2-stats.Count%2
+	if err := answers().Order("number").Offset(offset).Limit(limit).Pluck("number", &middle).Error; err != nil {
+		return err
+	}
+	if len(middle) > 0 {
+		var sum float64
+		for _, value := range middle {
+			sum += value
+		}
+		result.Median = roundStat(sum / float64(len(middle)))
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+	"vote-system-backend/pubsub"
+	"vote-system-backend/storage"
+)
+
+func TestSubmitSurvey(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 3)
+	s := NewSurveyService()
+
+	survey, err := s.CreateSurvey(&dto.CreateSurveyRequest{
+		Title: "满意度调查",
+		Questions: []dto.SurveyQuestionRequest{
+			{Type: model.QuestionSingle, Title: "是否满意", Required: true, Options: []string{"满意", "不满意"}},
+			{Type: model.QuestionMulti, Title: "喜欢的功能", Options: []string{"投票", "问卷", "评论"}},
+			{Type: model.QuestionText, Title: "建议"},
+			{Type: model.QuestionNPS, Title: "推荐意愿"},
+		},
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	questions, err := loadQuestions(database.GetDB(), survey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	single, multi, text, nps := questions[0], questions[1], questions[2], questions[3]
+	satisfied, unsatisfied := single.Vote.Options[0].ID, single.Vote.Options[1].ID
+	number := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		userID  uint
+		answers []dto.SurveyAnswerRequest
+		wantErr bool
+	}{
+		{"required question missing", users[1].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: text.ID, Text: "很好"},
+		}, true},
+		{"two options for single choice", users[1].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: single.ID, OptionIDs: []uint{satisfied, unsatisfied}},
+		}, true},
+		{"option of another question", users[1].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: single.ID, OptionIDs: []uint{multi.Vote.Options[0].ID}},
+		}, true},
+		{"score out of range", users[1].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: single.ID, OptionIDs: []uint{satisfied}},
+			{QuestionID: nps.ID, Number: number(11)},
+		}, true},
+		{"complete response", users[1].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: single.ID, OptionIDs: []uint{satisfied}},
+			{QuestionID: multi.ID, OptionIDs: []uint{multi.Vote.Options[0].ID, multi.Vote.Options[1].ID, multi.Vote.Options[0].ID}},
+			{QuestionID: text.ID, Text: "  很好  "},
+			{QuestionID: nps.ID, Number: number(9)},
+		}, false},
+		{"second response", users[1].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: single.ID, OptionIDs: []uint{unsatisfied}},
+		}, true},
+		{"optional questions skipped", users[2].ID, []dto.SurveyAnswerRequest{
+			{QuestionID: single.ID, OptionIDs: []uint{unsatisfied}},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.SubmitSurvey(survey.ID, &dto.SubmitSurveyRequest{Answers: tt.answers}, tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 失败的提交不留下任何记录
+	results, err := s.GetResults(survey.ID, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Responses != 2 {
+		t.Errorf("responses = %d, want 2", results.Responses)
+	}
+	want := []struct {
+		answered int
+		counts   []int
+	}{
+		{2, []int{1, 1}},
+		{1, []int{1, 1, 0}},
+		{1, nil},
+		{1, nil},
+	}
+	for i, question := range results.Questions {
+		if question.Answered != want[i].answered {
+			t.Errorf("question %d answered = %d, want %d", i+1, question.Answered, want[i].answered)
+		}
+		for j, option := range question.Options {
+			if option.Count != want[i].counts[j] {
+				t.Errorf("question %d option %d count = %d, want %d", i+1, j+1, option.Count, want[i].counts[j])
+			}
+		}
+	}
+	if texts := results.Questions[2].Texts; len(texts) != 1 || texts[0] != "很好" {
+		t.Errorf("texts = %q, want [很好]", texts)
+	}
+	if nps := results.Questions[3].NPS; nps == nil || *nps != 100 {
+		t.Errorf("nps = %v, want 100", nps)
+	}
+
+	var ballots int64
+	if err := database.GetDB().Model(&model.UserVote{}).Where("vote_id = ?", single.Vote.ID).Count(&ballots).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ballots != 2 {
+		t.Errorf("ballots on the single choice question = %d, want 2", ballots)
+	}
+}
+
+func TestSurveyVoteManagedBySurvey(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 2)
+
+	survey, err := NewSurveyService().CreateSurvey(&dto.CreateSurveyRequest{
+		Title:     "午餐调查",
+		Questions: []dto.SurveyQuestionRequest{{Type: model.QuestionSingle, Title: "吃什么", Options: []string{"面", "饭"}}},
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	questions, err := loadQuestions(database.GetDB(), survey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vote := questions[0].Vote
+
+	votes := NewVoteService()
+	if err := votes.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{vote.Options[0].ID}}, users[1].ID); err == nil {
+		t.Error("voted on a survey question directly")
+	}
+	if err := votes.UpdateVote(&dto.UpdateVoteRequest{ID: vote.ID, Title: "改名", Options: []string{"面", "饭"}}, users[0].ID); err == nil {
+		t.Error("edited a survey question as a poll")
+	}
+	if err := votes.DeleteVote(vote.ID, users[0].ID); err == nil {
+		t.Error("deleted a survey question as a poll")
+	}
+	// 图片操作在检查文件和图片是否存在之前就应拒绝
+	images := NewImageService()
+	if _, err := images.UploadImage(vote.ID, vote.Options[0].ID, nil, users[0].ID); err == nil || err.Error() != "该投票属于问卷，请通过问卷操作" {
+		t.Errorf("UploadImage() error = %v, want survey vote refused", err)
+	}
+	if err := images.DeleteImage(vote.ID, 1, users[0].ID); err == nil || err.Error() != "该投票属于问卷，请通过问卷操作" {
+		t.Errorf("DeleteImage() error = %v, want survey vote refused", err)
+	}
+}
+
+func TestSurveyRemovesQuestionImages(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 1)
+	s := NewSurveyService()
+
+	dir := t.TempDir()
+	previous := storage.GetStorage()
+	storage.SetStorage(storage.NewLocalStorage(dir, "/uploads"))
+	t.Cleanup(func() { storage.SetStorage(previous) })
+
+	question := []dto.SurveyQuestionRequest{{Type: model.QuestionSingle, Title: "选哪个", Options: []string{"甲", "乙"}}}
+	survey, err := s.CreateSurvey(&dto.CreateSurveyRequest{Title: "图片清理", Questions: question}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 问卷的投票不能上传图片，这里直接写入，模拟限制之前留下的图片
+	addImage := func(name string) string {
+		questions, err := loadQuestions(database.GetDB(), survey.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vote := questions[0].Vote
+		img := model.OptionImage{VoteID: vote.ID, OptionID: vote.Options[0].ID, Key: name + ".png", ThumbKey: name + "-thumb.jpg"}
+		for _, key := range []string{img.Key, img.ThumbKey} {
+			if err := storage.GetStorage().Put(key, []byte("x"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := database.GetDB().Create(&img).Error; err != nil {
+			t.Fatal(err)
+		}
+		return img.Key
+	}
+	assertRemoved := func(key string) {
+		t.Helper()
+		var count int64
+		if err := database.GetDB().Model(&model.OptionImage{}).Where("`key` = ?", key).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("image %s still in the database", key)
+		}
+		if _, err := os.Stat(filepath.Join(dir, key)); !os.IsNotExist(err) {
+			t.Errorf("image file %s not deleted", key)
+		}
+	}
+
+	replaced := addImage("replaced")
+	if err := s.UpdateSurvey(survey.ID, &dto.UpdateSurveyRequest{Title: survey.Title, Questions: question}, users[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	assertRemoved(replaced)
+
+	deleted := addImage("deleted")
+	if err := s.DeleteSurvey(survey.ID, users[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	assertRemoved(deleted)
+}
+
+func TestUpdateSurveyNotifiesQuestionVotes(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 1)
+	s := NewSurveyService()
+
+	survey, err := s.CreateSurvey(&dto.CreateSurveyRequest{
+		Title:     "活动报名",
+		Deadline:  time.Now().Add(time.Hour).Unix(),
+		Questions: []dto.SurveyQuestionRequest{{Type: model.QuestionSingle, Title: "参加哪一场", Options: []string{"上午", "下午"}}},
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	questions, err := loadQuestions(database.GetDB(), survey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voteID := questions[0].Vote.ID
+
+	events, cancel := pubsub.GetBroker().Subscribe(pubsub.VoteTopic(voteID))
+	defer cancel()
+
+	deadline := time.Now().Add(2 * time.Hour).Unix()
+	if err := s.UpdateSurvey(survey.ID, &dto.UpdateSurveyRequest{Title: survey.Title, Deadline: deadline}, users[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		var state dto.StateEvent
+		if err := pubsub.Decode(event, &state); err != nil || state.State != StateUpdated {
+			t.Errorf("event = %+v, want state %q", event, StateUpdated)
+		}
+	default:
+		t.Fatal("no state event for the question's vote")
+	}
+	closed, got, err := NewVoteService().GetDeadline(voteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed || got != deadline {
+		t.Errorf("GetDeadline() = %v %d, want false %d", closed, got, deadline)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"errors"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+// ballot 一个用户在某个投票中的选择及权重
+type ballot struct {
+	OptionIDs []uint
+	Weight    float64
+}
+
+// directBallots 返回每个直接投票用户的选票
+func directBallots(voteID uint) (map[uint]*ballot, error) {
+	var userVotes []model.UserVote
+	if err := database.GetDB().Select("user_id", "option_id", "weight").Where("vote_id = ?", voteID).Find(&userVotes).Error; err != nil {
+		return nil, err
+	}
+
+	ballots := make(map[uint]*ballot)
+	for _, uv := range userVotes {
+		b, ok := ballots[uv.UserID]
+		if !ok {
+			b = &ballot{Weight: uv.Weight}
+			ballots[uv.UserID] = b
+		}
+		b.OptionIDs = append(b.OptionIDs, uv.OptionID)
+	}
+	return ballots, nil
+}
+
+// applicableDelegations 返回对该投票生效的委托，按标签委托优先于全局委托
+func applicableDelegations(voteID uint) (map[uint]uint, error) {
+	var tags []string
+	if err := database.GetDB().Model(&model.VoteTag{}).Where("vote_id = ?", voteID).Pluck("name", &tags).Error; err != nil {
+		return nil, err
+	}
+
+	query := database.GetDB().Where("tag = ''")
+	if len(tags) > 0 {
+		query = database.GetDB().Where("tag = '' OR tag IN ?", tags)
+	}
+
+	var delegations []model.Delegation
+	if err := query.Order("id").Find(&delegations).Error; err != nil {
+		return nil, err
+	}
+
+	edges := make(map[uint]uint)
+	scoped := make(map[uint]bool)
+	for _, d := range delegations {
+		if d.Tag != "" {
+			if !scoped[d.DelegatorID] {
+				edges[d.DelegatorID] = d.DelegateID
+				scoped[d.DelegatorID] = true
+			}
+			continue
+		}
+		if _, ok := edges[d.DelegatorID]; !ok {
+			edges[d.DelegatorID] = d.DelegateID
+		}
+	}
+	return edges, nil
+}
+
+// resolveDelegate 沿委托链找到最终直接投票的用户，出现环或链断开时返回 0
+func resolveDelegate(userID uint, edges map[uint]uint, ballots map[uint]*ballot) uint {
+	visited := map[uint]bool{userID: true}
+	current, ok := edges[userID]
+	for ok {
+		if _, voted := ballots[current]; voted {
+			return current
+		}
+		if visited[current] {
+			return 0
+		}
+		visited[current] = true
+		current, ok = edges[current]
+	}
+	return 0
+}
+
+// delegatedBallots 计算未直接投票的委托人继承的选票，委托人直接投票时以其本人为准，
+// 继承的选票按委托人自己的权重计入
+func delegatedBallots(vote *model.Vote, ballots map[uint]*ballot) (map[uint]*ballot, error) {
+	edges, err := applicableDelegations(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[uint]uint)
+	for delegator := range edges {
+		if _, voted := ballots[delegator]; voted {
+			continue
+		}
+		if delegate := resolveDelegate(delegator, edges, ballots); delegate != 0 {
+			resolved[delegator] = delegate
+		}
+	}
+
+	delegators := make([]uint, 0, len(resolved))
+	for delegator := range resolved {
+		delegators = append(delegators, delegator)
+	}
+	weights, err := voterWeights(vote, delegators)
+	if err != nil {
+		return nil, err
+	}
+
+	inherited := make(map[uint]*ballot)
+	for delegator, delegate := range resolved {
+		// 委托人不在权重名单中时没有投票权
+		weight, ok := weights[delegator]
+		if !ok {
+			continue
+		}
+		inherited[delegator] = &ballot{
+			OptionIDs: ballots[delegate].OptionIDs,
+			Weight:    weight,
+		}
+	}
+	return inherited, nil
+}
+
+// countBallots 按选项统计人数和权重
+func countBallots(ballots map[uint]*ballot) (map[uint]int, map[uint]float64) {
+	counts := make(map[uint]int)
+	weights := make(map[uint]float64)
+	for _, b := range ballots {
+		for _, optionID := range b.OptionIDs {
+			counts[optionID]++
+			weights[optionID] += b.Weight
+		}
+	}
+	return counts, weights
+}
+
+// computeTally 统计包含委托票在内的结果
+func computeTally(vote *model.Vote) (*dto.TallyResponse, error) {
+	ballots, err := directBallots(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+	inherited, err := delegatedBallots(vote, ballots)
+	if err != nil {
+		return nil, err
+	}
+
+	direct, directWeight := countBallots(ballots)
+	delegated, delegatedWeight := countBallots(inherited)
+
+	tally := &dto.TallyResponse{
+		VoteID:          vote.ID,
+		Weighted:        vote.WeightSetID != nil,
+		DirectVoters:    len(ballots),
+		DelegatedVoters: len(inherited),
+		Options:         make([]dto.TallyOption, 0, len(vote.Options)),
+	}
+	for _, b := range ballots {
+		tally.VoterWeight += b.Weight
+	}
+	for _, b := range inherited {
+		tally.VoterWeight += b.Weight
+	}
+	for _, option := range vote.Options {
+		tally.Options = append(tally.Options, dto.TallyOption{
+			OptionID:  option.ID,
+			Content:   option.Content,
+			Direct:    direct[option.ID],
+			Delegated: delegated[option.ID],
+			Total:     direct[option.ID] + delegated[option.ID],
+
+			DirectWeight:    directWeight[option.ID],
+			DelegatedWeight: delegatedWeight[option.ID],
+			TotalWeight:     directWeight[option.ID] + delegatedWeight[option.ID],
+		})
+	}
+	return tally, nil
+}
+
+// loadVisibleVote 加载投票及选项，并检查当前用户能否查看结果
+func loadVisibleVote(id uint, userID uint) (*model.Vote, error) {
+	var vote model.Vote
+	if err := database.GetDB().Preload("Options").First(&vote, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("投票不存在")
+		}
+		return nil, err
+	}
+
+	if !canViewResults(&vote, userID) {
+		return nil, errors.New("投票结果暂不可见")
+	}
+	return &vote, nil
+}
+
+// GetTally 统计包含委托票在内的结果
+func (s *VoteService) GetTally(id uint, userID uint) (*dto.TallyResponse, error) {
+	vote, err := loadVisibleVote(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return computeTally(vote)
+}
@@ -0,0 +1,29 @@
+package service
+
+import "testing"
+
+func TestResolveDelegate(t *testing.T) {
+	voted := &ballot{}
+	tests := []struct {
+		name    string
+		userID  uint
+		edges   map[uint]uint
+		ballots map[uint]*ballot
+		want    uint
+	}{
+		{"no delegation", 1, map[uint]uint{}, map[uint]*ballot{2: voted}, 0},
+		{"direct delegate voted", 1, map[uint]uint{1: 2}, map[uint]*ballot{2: voted}, 2},
+		{"follows chain", 1, map[uint]uint{1: 2, 2: 3, 3: 4}, map[uint]*ballot{4: voted}, 4},
+		{"stops at first voter", 1, map[uint]uint{1: 2, 2: 3}, map[uint]*ballot{2: voted, 3: voted}, 2},
+		{"broken chain", 1, map[uint]uint{1: 2, 2: 3}, map[uint]*ballot{}, 0},
+		{"cycle", 1, map[uint]uint{1: 2, 2: 3, 3: 1}, map[uint]*ballot{}, 0},
+		{"cycle not through start", 1, map[uint]uint{1: 2, 2: 3, 3: 2}, map[uint]*ballot{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveDelegate(tt.userID, tt.edges, tt.ballots); got != tt.want {
+				t.Errorf("resolveDelegate() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"vote-system-backend/database"
+	"vote-system-backend/model"
+)
+
+// testDialector 在 SQLite 上运行服务层测试。SQLite 不支持 MySQL 的全文索引，迁移时跳过
+type testDialector struct {
+	sqlite.Dialector
+}
+
+func (d testDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return testMigrator{d.Dialector.Migrator(db).(sqlite.Migrator)}
+}
+
+type testMigrator struct {
+	sqlite.Migrator
+}
+
+func (m testMigrator) CreateIndex(value interface{}, name string) error {
+	stmt := &gorm.Statement{DB: m.DB}
+	if err := stmt.Parse(value); err == nil {
+		if idx := stmt.Schema.LookIndex(name); idx != nil && idx.Class == "FULLTEXT" {
+			return nil
+		}
+	}
+	return m.Migrator.CreateIndex(value, name)
+}
+
+// setupTestDB 为每个测试创建独立的数据库并替换 database.DB，测试结束后恢复
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=off"
+	db, err := gorm.Open(testDialector{sqlite.Dialector{DSN: dsn}}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Skipf("无法打开测试数据库: %v", err)
+	}
+	err = db.AutoMigrate(
+		&model.User{}, &model.Vote{}, &model.VoteOption{}, &model.UserVote{},
+		&model.VoteTag{}, &model.VoteCollaborator{}, &model.VoteTransfer{},
+		&model.Delegation{}, &model.WeightSet{}, &model.WeightEntry{},
+		&model.ProxyGrant{}, &model.Presentation{}, &model.PresentationItem{}, &model.PresentationGuest{},
+		&model.WebhookSubscription{}, &model.OutboxEvent{}, &model.WebhookDelivery{},
+		&model.ResultSnapshot{},
+		&model.AttributeDefinition{}, &model.UserAttribute{}, &model.BallotAttribute{},
+		&model.PrivacyBudget{}, &model.PrivacyRelease{},
+		&model.Audit{}, &model.AuditSample{},
+		&model.Category{},
+		&model.Follow{}, &model.Bookmark{},
+		&model.Comment{}, &model.Notification{},
+		&model.OptionSuggestion{}, &model.WriteInAnswer{},
+		&model.OptionImage{},
+		&model.Survey{}, &model.SurveyQuestion{}, &model.SurveyResponse{}, &model.SurveyAnswer{},
+	)
+	if err != nil {
+		t.Fatalf("迁移测试数据库失败: %v", err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// createTestUsers 创建用户名为 user1、user2…… 的普通用户
+func createTestUsers(t *testing.T, n int) []model.User {
+	t.Helper()
+	users := make([]model.User, n)
+	for i := range users {
+		users[i] = model.User{Username: fmt.Sprintf("user%d", i+1), PasswordHash: "x"}
+	}
+	if err := database.GetDB().Create(&users).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	return users
+}
+
+// optionsByContent 返回投票的选项，按内容索引
+func optionsByContent(t *testing.T, voteID uint) map[string]model.VoteOption {
+	t.Helper()
+	var options []model.VoteOption
+	if err := database.GetDB().Where("vote_id = ?", voteID).Find(&options).Error; err != nil {
+		t.Fatal(err)
+	}
+	byContent := make(map[string]model.VoteOption, len(options))
+	for _, option := range options {
+		byContent[option.Content] = option
+	}
+	return byContent
+}
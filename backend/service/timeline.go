@@ -0,0 +1,259 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BucketMinute = "minute"
+	BucketHour   = "hour"
+	BucketDay    = "day"
+)
+
+// maxTimelineBuckets 单次返回的时间桶上限，超过时需要缩小时间范围或使用更大的粒度
+const maxTimelineBuckets = 10000
+
+// slotLayout 数据库聚合结果的时间格式，created_at 以服务器本地时间存储
+const slotLayout = "2006-01-02 15:04"
+
+type timelineRow struct {
+	OptionID uint
+	Slot     string
+	Count    int
+	Weight   float64
+}
+
+type timelineVoterRow struct {
+	Slot   string
+	Voters int
+}
+
+type timelineBucket struct {
+	voters  int
+	counts  []int
+	weights []float64
+}
+
+// slotExpr 返回在数据库中聚合选票的时间片表达式。所有时区的偏移都是15分钟的整数倍，
+// 按15分钟聚合后可以在任意时区内合并为小时或天，聚合结果的行数与选票数量无关
+func slotExpr(bucket string) string {
+	if bucket == BucketMinute {
+		return "DATE_FORMAT(created_at, '%Y-%m-%d %H:%i')"
+	}
+	return "CONCAT(DATE_FORMAT(created_at, '%Y-%m-%d %H:'), LPAD(FLOOR(MINUTE(created_at) / 15) * 15, 2, '0'))"
+}
+
+// bucketStart 返回 t 在指定时区中所属时间桶的起点
+func bucketStart(t time.Time, bucket string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch bucket {
+	case BucketMinute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+	case BucketDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	}
+}
+
+// nextBucket 返回下一个时间桶的起点，按天时考虑夏令时导致的长短日
+func nextBucket(start time.Time, bucket string, loc *time.Location) time.Time {
+	switch bucket {
+	case BucketMinute:
+		return start.Add(time.Minute)
+	case BucketDay:
+		return time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, loc)
+	default:
+		return start.Add(time.Hour)
+	}
+}
+
+// GetTimeline 按时间桶统计每个选项的新增票数和累计票数
+func (s *VoteService) GetTimeline(id uint, query *dto.TimelineQuery, userID uint) (*dto.TimelineResponse, error) {
+	bucket := query.Bucket
+	if bucket == "" {
+		bucket = BucketHour
+	}
+	loc, err := time.LoadLocation(query.TimeZone)
+	if err != nil {
+		return nil, errors.New("无效的时区")
+	}
+	if query.From > 0 && query.To > 0 && query.From > query.To {
+		return nil, errors.New("开始时间不能晚于结束时间")
+	}
+
+	vote, err := loadVisibleVote(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	scope := db.Model(&model.UserVote{}).Where("vote_id = ?", id)
+	if query.From > 0 {
+		scope = scope.Where("created_at >= ?", time.Unix(query.From, 0))
+	}
+	if query.To > 0 {
+		scope = scope.Where("created_at < ?", time.Unix(query.To, 0))
+	}
+	scope = scope.Session(&gorm.Session{})
+
+	var rows []timelineRow
+	if err := scope.
+		Select("option_id, " + slotExpr(bucket) + " AS slot, COUNT(*) AS count, SUM(weight) AS weight").
+		Group("option_id, slot").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// 多选选票的多条记录按用户最早的一条计入投票人数
+	firstBallots := scope.Select("user_id, MIN(created_at) AS created_at").Group("user_id")
+	var voterRows []timelineVoterRow
+	if err := db.Table("(?) AS b", firstBallots).
+		Select(slotExpr(bucket) + " AS slot, COUNT(*) AS voters").
+		Group("slot").
+		Scan(&voterRows).Error; err != nil {
+		return nil, err
+	}
+
+	optionIndex := make(map[uint]int, len(vote.Options))
+	resp := &dto.TimelineResponse{
+		VoteID:   vote.ID,
+		Bucket:   bucket,
+		TimeZone: loc.String(),
+		Options:  make([]dto.TimelineOption, 0, len(vote.Options)),
+		Points:   []dto.TimelinePoint{},
+	}
+	for i, option := range vote.Options {
+		optionIndex[option.ID] = i
+		resp.Options = append(resp.Options, dto.TimelineOption{OptionID: option.ID, Content: option.Content})
+	}
+
+	buckets := make(map[int64]*timelineBucket)
+	var first, last time.Time
+	getBucket := func(slot string) (*timelineBucket, error) {
+		t, err := time.ParseInLocation(slotLayout, slot, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		start := bucketStart(t, bucket, loc)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if last.IsZero() || start.After(last) {
+			last = start
+		}
+		b, ok := buckets[start.Unix()]
+		if !ok {
+			b = &timelineBucket{
+				counts:  make([]int, len(vote.Options)),
+				weights: make([]float64, len(vote.Options)),
+			}
+			buckets[start.Unix()] = b
+		}
+		return b, nil
+	}
+
+	for _, row := range rows {
+		i, ok := optionIndex[row.OptionID]
+		if !ok {
+			continue
+		}
+		b, err := getBucket(row.Slot)
+		if err != nil {
+			return nil, err
+		}
+		b.counts[i] += row.Count
+		b.weights[i] += row.Weight
+	}
+	for _, row := range voterRows {
+		b, err := getBucket(row.Slot)
+		if err != nil {
+			return nil, err
+		}
+		b.voters += row.Voters
+	}
+
+	// 指定了时间范围时补齐范围两端的空桶
+	if query.From > 0 {
+		first = bucketStart(time.Unix(query.From, 0), bucket, loc)
+	}
+	if query.To > 0 {
+		last = bucketStart(time.Unix(query.To-1, 0), bucket, loc)
+	}
+	if first.IsZero() || last.IsZero() {
+		return resp, nil
+	}
+
+	cumulativeVoters := 0
+	cumulativeCounts := make([]int, len(vote.Options))
+	cumulativeWeights := make([]float64, len(vote.Options))
+	if query.From > 0 {
+		// 累计值包含开始时间之前的选票
+		if err := timelineBaseline(id, time.Unix(query.From, 0), optionIndex, &cumulativeVoters, cumulativeCounts, cumulativeWeights); err != nil {
+			return nil, err
+		}
+	}
+	for start := first; !start.After(last); start = nextBucket(start, bucket, loc) {
+		if len(resp.Points) >= maxTimelineBuckets {
+			return nil, errors.New("时间范围过大，请缩小范围或使用更大的时间粒度")
+		}
+
+		point := dto.TimelinePoint{
+			Start:   start.Format(time.RFC3339),
+			Counts:  make([]int, len(vote.Options)),
+			Weights: make([]float64, len(vote.Options)),
+		}
+		if b, ok := buckets[start.Unix()]; ok {
+			point.Voters = b.voters
+			copy(point.Counts, b.counts)
+			copy(point.Weights, b.weights)
+		}
+
+		cumulativeVoters += point.Voters
+		for i := range vote.Options {
+			cumulativeCounts[i] += point.Counts[i]
+			cumulativeWeights[i] += point.Weights[i]
+		}
+		point.CumulativeVoters = cumulativeVoters
+		point.CumulativeCounts = append([]int(nil), cumulativeCounts...)
+		point.CumulativeWeights = append([]float64(nil), cumulativeWeights...)
+		resp.Points = append(resp.Points, point)
+	}
+	return resp, nil
+}
+
+// timelineBaseline 统计 before 之前的投票人数和各选项票数
+func timelineBaseline(voteID uint, before time.Time, optionIndex map[uint]int, voters *int, counts []int, weights []float64) error {
+	db := database.GetDB()
+
+	var rows []timelineRow
+	if err := db.Model(&model.UserVote{}).
+		Select("option_id, COUNT(*) AS count, SUM(weight) AS weight").
+		Where("vote_id = ? AND created_at < ?", voteID, before).
+		Group("option_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if i, ok := optionIndex[row.OptionID]; ok {
+			counts[i] = row.Count
+			weights[i] = row.Weight
+		}
+	}
+
+	var count int64
+	if err := db.Model(&model.UserVote{}).
+		Where("vote_id = ? AND created_at < ?", voteID, before).
+		Distinct("user_id").
+		Count(&count).Error; err != nil {
+		return err
+	}
+	*voters = int(count)
+	return nil
+}
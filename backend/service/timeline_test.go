@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // 与 main 一致，不依赖系统的时区数据库
+)
+
+func TestBucketStart(t *testing.T) {
+	// 2024-03-10 06:50:00 UTC：上海 14:50，纽约夏令时开始当天的 01:50（EST）
+	at := time.Date(2024, 3, 10, 6, 50, 0, 0, time.UTC)
+	tests := []struct {
+		zone   string
+		bucket string
+		want   string
+	}{
+		{"UTC", BucketHour, "2024-03-10T06:00:00Z"},
+		{"Asia/Shanghai", BucketMinute, "2024-03-10T14:50:00+08:00"},
+		{"Asia/Shanghai", BucketHour, "2024-03-10T14:00:00+08:00"},
+		{"Asia/Shanghai", BucketDay, "2024-03-10T00:00:00+08:00"},
+		{"Asia/Kolkata", BucketHour, "2024-03-10T12:00:00+05:30"},
+		{"America/New_York", BucketDay, "2024-03-10T00:00:00-05:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.zone+"/"+tt.bucket, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Fatalf("LoadLocation(%q): %v", tt.zone, err)
+			}
+			if got := bucketStart(at, tt.bucket, loc).Format(time.RFC3339); got != tt.want {
+				t.Errorf("bucketStart() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextBucketAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		day  int
+		want time.Duration
+	}{
+		{9, 24 * time.Hour},  // 普通的一天
+		{10, 23 * time.Hour}, // 夏令时开始，少一小时
+	}
+	for _, tt := range tests {
+		start := time.Date(2024, 3, tt.day, 0, 0, 0, 0, loc)
+		if got := nextBucket(start, BucketDay, loc).Sub(start); got != tt.want {
+			t.Errorf("day %d length = %v, want %v", tt.day, got, tt.want)
+		}
+	}
+	// 小时桶按绝对时间前进，跳过不存在的 02:00
+	start := time.Date(2024, 3, 10, 1, 0, 0, 0, loc)
+	if got := nextBucket(start, BucketHour, loc).Format(time.RFC3339); got != "2024-03-10T03:00:00-04:00" {
+		t.Errorf("next hour = %s, want 2024-03-10T03:00:00-04:00", got)
+	}
+}
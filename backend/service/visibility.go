@@ -0,0 +1,107 @@
+package service
+
+import (
+	"vote-system-backend/database"
+	"vote-system-backend/model"
+)
+
+// isRegisteredUser 判断用户是否为注册用户。投票详情和评论对所有注册用户可见，
+// 访客只能通过演示会话提交当前展示的投票，不能查看
+func isRegisteredUser(userID uint) bool {
+	ids, err := registeredUsers([]uint{userID})
+	return err == nil && len(ids) > 0
+}
+
+// registeredUsers 返回 userIDs 中的注册用户，过滤掉访客和不存在的用户
+func registeredUsers(userIDs []uint) ([]uint, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uint
+	err := database.GetDB().Model(&model.User{}).Where("id IN ? AND guest = ?", userIDs, false).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// canViewResults 判断用户能否查看精确的计票结果。启用差分隐私的投票只有管理员可见，
+// 其余按结果可见性判断
+func canViewResults(vote *model.Vote, userID uint) bool {
+	if privacyEnabled(vote.ID) {
+		return isAdmin(userID)
+	}
+	return visibilityAllows(vote, userID)
+}
+
+// visibilityAllows 按投票的结果可见性判断用户能否查看结果，
+// 创建者和可查看投票人的协作者始终可见
+func visibilityAllows(vote *model.Vote, userID uint) bool {
+	switch vote.ResultVisibility {
+	case model.ResultsAfterVote:
+		if voteEnded(vote) || !validSubmit(userID, vote.ID) {
+			return true
+		}
+	case model.ResultsAfterClose:
+		if voteEnded(vote) {
+			return true
+		}
+	default:
+		return true
+	}
+	return hasPermission(vote, userID, model.PermissionViewVoters)
+}
+
+// hideResults 清除投票中的计票数据
+func hideResults(vote *model.Vote) {
+	vote.VoterCount = 0
+	for i := range vote.Options {
+		vote.Options[i].Count = 0
+		vote.Options[i].WeightedCount = 0
+	}
+}
+
+// maskResults 对列表中当前用户不可见的投票隐藏计票结果
+func maskResults(votes []model.Vote, userID uint) error {
+	var votedIDs []uint
+	if err := database.GetDB().Model(&model.UserVote{}).Where("user_id = ?", userID).Distinct().Pluck("vote_id", &votedIDs).Error; err != nil {
+		return err
+	}
+	voted := make(map[uint]bool)
+	for _, id := range votedIDs {
+		voted[id] = true
+	}
+
+	ids := make([]uint, 0, len(votes))
+	for _, vote := range votes {
+		ids = append(ids, vote.ID)
+	}
+	private, err := privateVoteIDs(ids)
+	if err != nil {
+		return err
+	}
+	admin := isAdmin(userID)
+
+	for i := range votes {
+		vote := &votes[i]
+		if private[vote.ID] {
+			if !admin {
+				hideResults(vote)
+			}
+			continue
+		}
+		if vote.CreatorID == userID || voteEnded(vote) {
+			continue
+		}
+		switch vote.ResultVisibility {
+		case model.ResultsAfterVote:
+			if voted[vote.ID] {
+				continue
+			}
+		case model.ResultsAfterClose:
+		default:
+			continue
+		}
+		if !hasPermission(vote, userID, model.PermissionViewVoters) {
+			hideResults(vote)
+		}
+	}
+	return nil
+}
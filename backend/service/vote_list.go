@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+const defaultListLimit = 20
+
+// defaultTagCloudLimit 标签云默认返回的标签数
+const defaultTagCloudLimit = 50
+
+// endingKey 按截止时间排序的键，无截止时间的投票排在最后
+const endingKey = "CASE WHEN deadline = 0 THEN 9223372036854775807 ELSE deadline END"
+
+// votesKey 按参与人数排序的键。启用差分隐私的投票不公开参与人数，按0排序，
+// 避免通过排序位置推断精确人数
+const votesKey = "CASE WHEN id IN (SELECT vote_id FROM privacy_budgets) THEN 0 ELSE voter_count END"
+
+// VoteList 分页的投票列表
+type VoteList struct {
+	Items      []model.Vote `json:"items"`
+	Total      int64        `json:"total"`
+	NextCursor string       `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
+
+// listCursor 键集分页的位置，由上一页最后一条的排序键和 ID 组成
+type listCursor struct {
+	Key int64
+	ID  uint
+}
+
+func (c listCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Key, c.ID)))
+}
+
+func decodeCursor(s string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("无效的分页游标")
+	}
+	var c listCursor
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &c.Key, &c.ID); err != nil {
+		return nil, errors.New("无效的分页游标")
+	}
+	return &c, nil
+}
+
+// sortKey 返回投票在当前排序方式下的排序键
+func sortKey(vote *model.Vote, sort string) int64 {
+	switch sort {
+	case dto.SortEnding:
+		if vote.Deadline == 0 {
+			return math.MaxInt64
+		}
+		return vote.Deadline
+	case dto.SortVotes:
+		if privacyEnabled(vote.ID) {
+			return 0
+		}
+		return int64(vote.VoterCount)
+	}
+	return 0
+}
+
+// filterVotes 按查询条件过滤投票
+func filterVotes(db *gorm.DB, query *dto.VoteListQuery, userID uint) *gorm.DB {
+	now := time.Now().Unix()
+	// 问卷中的选择题只在问卷中出现
+	db = db.Where("survey_id IS NULL")
+	switch query.State {
+	case "open":
+		db = db.Where("closed = ? AND (deadline = 0 OR deadline >= ?)", false, now)
+	case "closed":
+		db = db.Where("closed = ? OR (deadline > 0 AND deadline < ?)", true, now)
+	}
+
+	if query.CreatorID != 0 {
+		db = db.Where("creator_id = ?", query.CreatorID)
+	}
+	if query.Multi != nil {
+		db = db.Where("multi = ?", *query.Multi)
+	}
+	if query.Weighted != nil {
+		if *query.Weighted {
+			db = db.Where("weight_set_id IS NOT NULL")
+		} else {
+			db = db.Where("weight_set_id IS NULL")
+		}
+	}
+	if query.Voted != nil {
+		voted := database.GetDB().Model(&model.UserVote{}).Select("vote_id").Where("user_id = ?", userID)
+		if *query.Voted {
+			db = db.Where("id IN (?)", voted)
+		} else {
+			db = db.Where("id NOT IN (?)", voted)
+		}
+	}
+	if query.DeadlineFrom > 0 {
+		db = db.Where("deadline >= ?", query.DeadlineFrom)
+	}
+	if query.DeadlineTo > 0 {
+		db = db.Where("deadline > 0 AND deadline <= ?", query.DeadlineTo)
+	}
+	if query.CategoryID != 0 {
+		db = db.Where("category_id = ?", query.CategoryID)
+	}
+	if tag := strings.TrimSpace(query.Tag); tag != "" {
+		db = db.Where("id IN (?)", database.GetDB().Model(&model.VoteTag{}).Select("vote_id").Where("name = ?", tag))
+	}
+	return db
+}
+
+// listVotes 按条件分页查询投票，scopes 为额外的过滤条件。使用键集分页，翻页时不会因为新增投票而重复或遗漏；
+// 按参与人数排序时，人数在翻页期间变化的投票可能出现在相邻的两页
+func listVotes(query *dto.VoteListQuery, userID uint, scopes ...func(*gorm.DB) *gorm.DB) (*VoteList, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	sort := query.Sort
+	if sort == "" {
+		sort = dto.SortNewest
+	}
+
+	list := &VoteList{Items: []model.Vote{}}
+	if err := filterVotes(database.GetDB().Model(&model.Vote{}), query, userID).Scopes(scopes...).Count(&list.Total).Error; err != nil {
+		return nil, err
+	}
+
+	db := filterVotes(database.GetDB(), query, userID).Scopes(scopes...)
+	if query.Cursor != "" {
+		cursor, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		switch sort {
+		case dto.SortEnding:
+			db = db.Where(endingKey+" > ? OR ("+endingKey+" = ? AND id > ?)", cursor.Key, cursor.Key, cursor.ID)
+		case dto.SortVotes:
+			db = db.Where(votesKey+" < ? OR ("+votesKey+" = ? AND id < ?)", cursor.Key, cursor.Key, cursor.ID)
+		default:
+			db = db.Where("id < ?", cursor.ID)
+		}
+	}
+	switch sort {
+	case dto.SortEnding:
+		db = db.Order(endingKey + " ASC, id ASC")
+	case dto.SortVotes:
+		db = db.Order(votesKey + " DESC, id DESC")
+	default:
+		db = db.Order("id DESC")
+	}
+
+	if err := db.Limit(limit + 1).Preload("Options").Preload("Tags").Find(&list.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(list.Items) > limit {
+		list.Items = list.Items[:limit]
+		last := &list.Items[limit-1]
+		list.HasMore = true
+		list.NextCursor = listCursor{Key: sortKey(last, sort), ID: last.ID}.encode()
+	}
+
+	if err := maskResults(list.Items, userID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetTagCloud 返回使用最多的标签及使用次数，不统计已删除的投票
+func (s *VoteService) GetTagCloud(limit int) ([]dto.TagCount, error) {
+	if limit == 0 {
+		limit = defaultTagCloudLimit
+	}
+
+	tags := []dto.TagCount{}
+	err := database.GetDB().Model(&model.VoteTag{}).
+		Select("vote_tags.name, COUNT(*) AS count").
+		Joins("JOIN votes ON votes.id = vote_tags.vote_id AND votes.deleted_at IS NULL").
+		Group("vote_tags.name").
+		Order("count DESC, vote_tags.name").
+		Limit(limit).
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
 	"vote-system-backend/database"
 	"vote-system-backend/dto"
 	"vote-system-backend/model"
+	"vote-system-backend/utils"
 
 	"gorm.io/gorm"
 )
@@ -25,23 +28,86 @@ func validSubmit(userId uint, voteId uint) bool {
 	return true
 }
 
+// normalizeTags 去除空白和重复的标签
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+func resultVisibility(visibility string) string {
+	if visibility == "" {
+		return model.ResultsAlways
+	}
+	return visibility
+}
+
 func (s *VoteService) CreateVote(req *dto.CreateVoteRequest, creatorID uint) (*model.Vote, error) {
+	if err := checkWeightSet(req.WeightSetID, creatorID); err != nil {
+		return nil, err
+	}
+	if err := checkCategory(req.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := checkOptionDescriptions(req.Options, req.OptionDescriptions); err != nil {
+		return nil, err
+	}
+
 	vote := model.Vote{
-		Title:     req.Title,
-		Multi:     req.Multi,
-		Deadline:  req.Deadline,
-		CreatorID: creatorID,
+		Title:            req.Title,
+		Description:      req.Description,
+		DescriptionHTML:  utils.RenderMarkdown(req.Description),
+		CategoryID:       req.CategoryID,
+		Multi:            req.Multi,
+		Deadline:         req.Deadline,
+		WeightSetID:      req.WeightSetID,
+		ResultVisibility: resultVisibility(req.ResultVisibility),
+		Suggestions:      suggestionMode(req.Suggestions),
+		WriteIn:          req.WriteIn,
+		WriteInThreshold: writeInThreshold(req.WriteInThreshold),
+		CreatorID:        creatorID,
 	}
 
 	// 创建选项
-	for _, optContent := range req.Options {
+	for i, optContent := range req.Options {
+		description := optionDescription(req.OptionDescriptions, i)
 		vote.Options = append(vote.Options, model.VoteOption{
-			Content: optContent,
-			Count:   0,
+			Content:         optContent,
+			Count:           0,
+			Description:     description,
+			DescriptionHTML: utils.RenderMarkdown(description),
 		})
 	}
+	if req.WriteIn {
+		vote.Options = append(vote.Options, model.VoteOption{Content: writeInOptionContent, WriteIn: true})
+	}
+
+	for _, name := range normalizeTags(req.Tags) {
+		vote.Tags = append(vote.Tags, model.VoteTag{Name: name})
+	}
 
-	if err := database.GetDB().Create(&vote).Error; err != nil {
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Create(&vote).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := writeOutbox(tx, model.EventPollCreated, &vote, vote); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
@@ -51,24 +117,64 @@ func (s *VoteService) CreateVote(req *dto.CreateVoteRequest, creatorID uint) (*m
 // 定义返回结构体
 type VoteWithStatus struct {
 	model.Vote
-	HasVoted bool `json:"has_voted"`
+	HasVoted        bool                       `json:"has_voted"`
+	MyWeight        float64                    `json:"my_weight"`
+	Permissions     []string                   `json:"permissions"`
+	Collaborators   []dto.CollaboratorResponse `json:"collaborators"`
+	PendingTransfer *dto.TransferResponse      `json:"pending_transfer"`
+	ResultsHidden   bool                       `json:"results_hidden"`
+	Bookmarked      bool                       `json:"bookmarked"`
 }
 
 func (s *VoteService) GetVote(id uint, userId uint) (*VoteWithStatus, error) {
 	var vote model.Vote
-	if err := database.GetDB().Preload("Options").First(&vote, id).Error; err != nil {
+	err := database.GetDB().
+		Preload("Options").
+		Preload("Options.Images", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Tags").
+		First(&vote, id).Error
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("投票不存在")
 		}
 		return nil, err
 	}
+	if !isRegisteredUser(userId) {
+		return nil, errors.New("没有权限查看此投票")
+	}
+	fillImageURLs(vote.Options)
 
 	// 检查用户是否已投票
 	hasVoted := !validSubmit(userId, vote.ID)
 
+	// 不在权重名单中时为0
+	myWeight, _ := voterWeight(&vote, userId)
+
+	resultsHidden := !canViewResults(&vote, userId)
+	if resultsHidden {
+		hideResults(&vote)
+	}
+
+	collaboratorService := NewCollaboratorService()
+	collaborators, err := collaboratorService.GetCollaborators(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingTransfer, err := collaboratorService.GetPendingTransfer(vote.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &VoteWithStatus{
-		Vote:     vote,
-		HasVoted: hasVoted,
+		Vote:            vote,
+		HasVoted:        hasVoted,
+		MyWeight:        myWeight,
+		Permissions:     userPermissions(&vote, userId),
+		Collaborators:   collaborators,
+		PendingTransfer: pendingTransfer,
+		ResultsHidden:   resultsHidden,
+		Bookmarked:      isBookmarked(vote.ID, userId),
 	}, nil
 }
 
@@ -81,44 +187,188 @@ func (s *VoteService) UpdateVote(req *dto.UpdateVoteRequest, userID uint) error
 		return err
 	}
 
+	if err := checkNotSurveyVote(&vote); err != nil {
+		return err
+	}
+
 	// 检查权限
-	if vote.CreatorID != userID {
+	if !hasPermission(&vote, userID, model.PermissionEdit) {
 		return errors.New("没有权限修改此投票")
 	}
 
-	// 开始事务
-	tx := database.GetDB().Begin()
-
-	// 删除旧选项
-	if err := tx.Where("vote_id = ?", vote.ID).Delete(&model.VoteOption{}).Error; err != nil {
-		tx.Rollback()
+	if err := checkWeightSet(req.WeightSetID, vote.CreatorID, userID); err != nil {
+		return err
+	}
+	if err := checkCategory(req.CategoryID); err != nil {
+		return err
+	}
+	if err := checkOptionDescriptions(req.Options, req.OptionDescriptions); err != nil {
 		return err
 	}
 
+	// 按内容把请求中的选项与现有选项对应，对应上的选项保留原来的选票、图片和补充答案，
+	// 其余现有选项连同选票一起删除
+	kept := make(map[int]*model.VoteOption, len(req.Options))
+	var writeInOption *model.VoteOption
+	removed := make(map[uint]bool)
+	for i := range vote.Options {
+		old := &vote.Options[i]
+		if old.WriteIn {
+			if req.WriteIn {
+				writeInOption = old
+			} else {
+				removed[old.ID] = true
+			}
+			continue
+		}
+		matched := false
+		for j, content := range req.Options {
+			if _, ok := kept[j]; !ok && content == old.Content {
+				kept[j] = old
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			removed[old.ID] = true
+		}
+	}
+	removedIDs := make([]uint, 0, len(removed))
+	for id := range removed {
+		removedIDs = append(removedIDs, id)
+	}
+	var removedImages []model.OptionImage
+	if len(removedIDs) > 0 {
+		if err := database.GetDB().Where("option_id IN ?", removedIDs).Find(&removedImages).Error; err != nil {
+			return err
+		}
+	}
+	// 保留的选票中有人多选时不能改为单选
+	if vote.Multi && !req.Multi {
+		var multiVoters int64
+		multi := database.GetDB().Model(&model.UserVote{}).Select("user_id").
+			Where("vote_id = ? AND option_id NOT IN ?", vote.ID, append(removedIDs, 0)).
+			Group("user_id").Having("COUNT(*) > 1")
+		if err := database.GetDB().Table("(?) AS multi", multi).Count(&multiVoters).Error; err != nil {
+			return err
+		}
+		if multiVoters > 0 {
+			return errors.New("已有投票人选择了多个选项，不能改为单选")
+		}
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
 	// 更新投票信息
 	vote.Title = req.Title
+	vote.Description = req.Description
+	vote.DescriptionHTML = utils.RenderMarkdown(req.Description)
+	vote.CategoryID = req.CategoryID
 	vote.Multi = req.Multi
 	vote.Deadline = req.Deadline
-
-	if err := tx.Save(&vote).Error; err != nil {
+	vote.WeightSetID = req.WeightSetID
+	vote.ResultVisibility = resultVisibility(req.ResultVisibility)
+	vote.Suggestions = suggestionMode(req.Suggestions)
+	vote.WriteIn = req.WriteIn
+	vote.WriteInThreshold = writeInThreshold(req.WriteInThreshold)
+
+	// 评论数由评论接口原子更新，参与人数在删除选项后重新统计，这里都不覆盖；选项在下面单独更新
+	if err := tx.Omit("CommentCount", "VoterCount", "Options").Save(&vote).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// 创建新选项
-	for _, optContent := range req.Options {
+	// 保留的选项只更新说明，新的选项排在保留的选项之后
+	for i, optContent := range req.Options {
+		description := optionDescription(req.OptionDescriptions, i)
+		if old, ok := kept[i]; ok {
+			if err := tx.Model(old).Updates(map[string]interface{}{
+				"description":      description,
+				"description_html": utils.RenderMarkdown(description),
+			}).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+			continue
+		}
 		option := model.VoteOption{
-			VoteID:  vote.ID,
-			Content: optContent,
-			Count:   0,
+			VoteID:          vote.ID,
+			Content:         optContent,
+			Count:           0,
+			Description:     description,
+			DescriptionHTML: utils.RenderMarkdown(description),
 		}
 		if err := tx.Create(&option).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
+	if req.WriteIn && writeInOption == nil {
+		if err := tx.Create(&model.VoteOption{VoteID: vote.ID, Content: writeInOptionContent, WriteIn: true}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
 
-	return tx.Commit().Error
+	// 删除去掉的选项及其补充答案、选票和图片
+	if len(removedIDs) > 0 {
+		removedBallots := tx.Model(&model.UserVote{}).Select("id").Where("option_id IN ?", removedIDs)
+		if err := tx.Where("user_vote_id IN (?)", removedBallots).Delete(&model.WriteInAnswer{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Model(&model.WriteInAnswer{}).Where("promoted_option_id IN ?", removedIDs).Update("promoted_option_id", nil).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Where("option_id IN ?", removedIDs).Delete(&model.UserVote{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Where("option_id IN ?", removedIDs).Delete(&model.OptionImage{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Where("id IN ?", removedIDs).Delete(&model.VoteOption{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		// 只投了被删除选项的投票人不再计入参与人数
+		voters := tx.Model(&model.UserVote{}).Select("COUNT(DISTINCT user_id)").Where("vote_id = ?", vote.ID)
+		if err := tx.Model(&model.Vote{}).Where("id = ?", vote.ID).UpdateColumn("voter_count", voters).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// 修改后需要重新生成最终结果
+	if err := tx.Where("vote_id = ?", vote.ID).Delete(&model.ResultSnapshot{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 替换标签
+	if err := tx.Where("vote_id = ?", vote.ID).Delete(&model.VoteTag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, name := range normalizeTags(req.Tags) {
+		if err := tx.Create(&model.VoteTag{VoteID: vote.ID, Name: name}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	deleteImageFiles(removedImages)
+
+	publishState(vote.ID, StateUpdated)
+	return nil
 }
 
 func (s *VoteService) DeleteVote(id uint, userID uint) error {
@@ -130,12 +380,37 @@ func (s *VoteService) DeleteVote(id uint, userID uint) error {
 		return err
 	}
 
+	if err := checkNotSurveyVote(&vote); err != nil {
+		return err
+	}
+
 	// 检查权限
 	if vote.CreatorID != userID {
 		return errors.New("没有权限删除此投票")
 	}
 
-	return database.GetDB().Delete(&vote).Error
+	// 投票是软删除，图片文件需要单独清理
+	var images []model.OptionImage
+	if err := database.GetDB().Where("vote_id = ?", vote.ID).Find(&images).Error; err != nil {
+		return err
+	}
+
+	tx := database.GetDB().Begin()
+	if err := tx.Where("vote_id = ?", vote.ID).Delete(&model.OptionImage{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&vote).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	deleteImageFiles(images)
+
+	publishState(vote.ID, StateDeleted)
+	return nil
 }
 
 func (s *VoteService) Vote(req *dto.VoteRequest, userID uint) error {
@@ -148,27 +423,69 @@ func (s *VoteService) Vote(req *dto.VoteRequest, userID uint) error {
 		return err
 	}
 
+	if err := checkNotSurveyVote(&vote); err != nil {
+		return err
+	}
+
+	if err := checkGuestVote(userID, vote.ID); err != nil {
+		return err
+	}
+
+	// 代理投票时以委托人身份投票，并记录实际提交人
+	var proxyID *uint
+	if req.OnBehalfOf != 0 && req.OnBehalfOf != userID {
+		if err := checkProxyGrant(vote.ID, req.OnBehalfOf, userID); err != nil {
+			return err
+		}
+		submitter := userID
+		proxyID = &submitter
+		userID = req.OnBehalfOf
+	}
+
 	// 检查是否投过票
 	if !validSubmit(userID, vote.ID) {
 		return errors.New("已投过票")
 	}
 
+	if vote.Closed {
+		return errors.New("投票已关闭")
+	}
+
 	// 检查是否已过期
 	if vote.Deadline > 0 && time.Now().Unix() > vote.Deadline {
 		return errors.New("投票已过期")
 	}
 
 	// 检查选项是否有效
-	validOptions := make(map[uint]bool)
-	for _, option := range vote.Options {
-		validOptions[option.ID] = true
+	if len(req.OptionIDs) == 0 {
+		return errors.New("请至少选择一个选项")
+	}
+	optionIDs, err := checkSelection(&vote, req.OptionIDs)
+	if err != nil {
+		return err
 	}
 
-	for _, optionID := range req.OptionIDs {
-		if !validOptions[optionID] {
-			return errors.New("无效的选项")
+	var writeInOptionID uint
+	for _, option := range vote.Options {
+		if option.WriteIn {
+			writeInOptionID = option.ID
 		}
 	}
+	writeInSelected := writeInOptionID != 0 && slices.Contains(optionIDs, writeInOptionID)
+
+	// 选择“其他”选项时必须填写内容
+	writeIn := strings.TrimSpace(req.WriteIn)
+	if writeInSelected && normalizeOption(writeIn) == "" {
+		return errors.New("请填写“其他”选项的内容")
+	}
+	if !writeInSelected && writeIn != "" {
+		return errors.New("选择“其他”选项后才能填写内容")
+	}
+
+	weight, err := voterWeight(&vote, userID)
+	if err != nil {
+		return err
+	}
 
 	// 开始事务
 	tx := database.GetDB().Begin()
@@ -188,39 +505,267 @@ func (s *VoteService) Vote(req *dto.VoteRequest, userID uint) error {
 	}
 
 	// 添加新的投票记录
-	for _, optionID := range req.OptionIDs {
-		userVote := model.UserVote{
-			UserID: userID,
-			VoteID: req.VoteID,
-		}
+	userVotes, err := castBallot(tx, &vote, userID, optionIDs, weight, proxyID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-		if err := tx.Create(&userVote).Error; err != nil {
+	for _, userVote := range userVotes {
+		if userVote.OptionID != writeInOptionID {
+			continue
+		}
+		answer := model.WriteInAnswer{
+			VoteID:     vote.ID,
+			UserVoteID: userVote.ID,
+			UserID:     userID,
+			Content:    writeIn,
+			Normalized: normalizeOption(writeIn),
+		}
+		if err := tx.Create(&answer).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	publishBallot(vote.ID, userID, optionIDs, weight)
+	return nil
+}
+
+var (
+	errSingleChoice  = errors.New("只能选择一个选项")
+	errInvalidOption = errors.New("无效的选项")
+)
+
+// checkSelection 检查选择的选项并去掉重复的选项：选项必须属于该投票，单选投票只能选择一个。
+// 投票和问卷的选择题共用，重复的选项不能重复计票
+func checkSelection(vote *model.Vote, optionIDs []uint) ([]uint, error) {
+	valid := make(map[uint]bool, len(vote.Options))
+	for _, option := range vote.Options {
+		valid[option.ID] = true
+	}
+
+	seen := make(map[uint]bool, len(optionIDs))
+	selected := make([]uint, 0, len(optionIDs))
+	for _, optionID := range optionIDs {
+		if !valid[optionID] {
+			return nil, errInvalidOption
+		}
+		if !seen[optionID] {
+			seen[optionID] = true
+			selected = append(selected, optionID)
+		}
+	}
+	if !vote.Multi && len(selected) > 1 {
+		return nil, errSingleChoice
+	}
+	return selected, nil
+}
+
+// castBallot 在事务中记录一张选票：创建投票记录、更新计数、记录投票人属性并写入 webhook 事件。
+// 提交事务后由调用方推送实时计票
+func castBallot(tx *gorm.DB, vote *model.Vote, userID uint, optionIDs []uint, weight float64, proxyID *uint) ([]model.UserVote, error) {
+	userVotes := make([]model.UserVote, 0, len(optionIDs))
+	for _, optionID := range optionIDs {
+		userVote := model.UserVote{
+			UserID:   userID,
+			VoteID:   vote.ID,
+			OptionID: optionID,
+			Weight:   weight,
+			ProxyID:  proxyID,
+		}
+		if err := tx.Create(&userVote).Error; err != nil {
+			return nil, err
+		}
+		userVotes = append(userVotes, userVote)
 
 		// 更新选项计数
-		if err := tx.Model(&model.VoteOption{}).Where("id = ?", optionID).UpdateColumn("count", gorm.Expr("count + 1")).Error; err != nil {
-			tx.Rollback()
-			return err
+		if err := tx.Model(&model.VoteOption{}).Where("id = ?", optionID).UpdateColumns(map[string]interface{}{
+			"count":          gorm.Expr("count + 1"),
+			"weighted_count": gorm.Expr("weighted_count + ?", weight),
+		}).Error; err != nil {
+			return nil, err
 		}
 	}
 
-	return tx.Commit().Error
-}
+	if err := tx.Model(&model.Vote{}).Where("id = ?", vote.ID).UpdateColumn("voter_count", gorm.Expr("voter_count + 1")).Error; err != nil {
+		return nil, err
+	}
+
+	if err := snapshotAttributes(tx, vote.ID, userID); err != nil {
+		return nil, err
+	}
 
-func (s *VoteService) GetUserVotes(userID uint) ([]model.Vote, error) {
-	var votes []model.Vote
-	if err := database.GetDB().Where("creator_id = ?", userID).Preload("Options").Find(&votes).Error; err != nil {
+	// 启用差分隐私的投票不向 webhook 透露所选选项
+	castData := dto.BallotCastData{
+		UserID:    userID,
+		OptionIDs: optionIDs,
+		Weight:    weight,
+		ProxyID:   proxyID,
+	}
+	if privacyEnabled(vote.ID) {
+		castData.OptionIDs = nil
+	}
+	if err := writeOutbox(tx, model.EventBallotCast, vote, castData); err != nil {
 		return nil, err
 	}
-	return votes, nil
+	return userVotes, nil
 }
 
-func (s *VoteService) GetAllVotes() ([]model.Vote, error) {
-	var votes []model.Vote
-	if err := database.GetDB().Preload("Options").Find(&votes).Error; err != nil {
+// CloseVote 提前结束投票
+func (s *VoteService) CloseVote(id uint, userID uint) error {
+	vote, err := findVote(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkNotSurveyVote(vote); err != nil {
+		return err
+	}
+
+	if !hasPermission(vote, userID, model.PermissionClose) {
+		return errors.New("没有权限关闭此投票")
+	}
+
+	if vote.Closed {
+		return errors.New("投票已关闭")
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Model(vote).Update("closed", true).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := writeOutbox(tx, model.EventPollClosed, vote, dto.PollStateData{Title: vote.Title}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	publishState(vote.ID, StateClosed)
+	return nil
+}
+
+// OpenVote 重新开启已关闭的投票
+func (s *VoteService) OpenVote(id uint, userID uint) error {
+	vote, err := findVote(id)
+	if err != nil {
+		return err
+	}
+
+	if err := checkNotSurveyVote(vote); err != nil {
+		return err
+	}
+
+	if !hasPermission(vote, userID, model.PermissionClose) {
+		return errors.New("没有权限开启此投票")
+	}
+
+	if !vote.Closed {
+		return nil
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Model(vote).Update("closed", false).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 重新开启后结果不再是最终结果
+	if err := tx.Where("vote_id = ?", vote.ID).Delete(&model.ResultSnapshot{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := writeOutbox(tx, model.EventPollOpened, vote, dto.PollStateData{Title: vote.Title}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	publishState(vote.ID, StateOpen)
+	return nil
+}
+
+// GetVoters 返回投票人及其选择
+func (s *VoteService) GetVoters(id uint, userID uint) ([]dto.VoterResponse, error) {
+	vote, err := findVote(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasPermission(vote, userID, model.PermissionViewVoters) {
+		return nil, errors.New("没有权限查看投票人")
+	}
+	if privacyEnabled(vote.ID) && !isAdmin(userID) {
+		return nil, errors.New("投票已启用差分隐私，只有管理员可以查看投票人")
+	}
+
+	var userVotes []model.UserVote
+	if err := database.GetDB().Where("vote_id = ?", vote.ID).Order("id").Find(&userVotes).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(userVotes))
+	for _, uv := range userVotes {
+		ids = append(ids, uv.UserID)
+		if uv.ProxyID != nil {
+			ids = append(ids, *uv.ProxyID)
+		}
+	}
+	names, err := usernamesByID(ids)
+	if err != nil {
 		return nil, err
 	}
-	return votes, nil
+
+	// 按用户合并多选记录
+	index := make(map[uint]int)
+	voters := []dto.VoterResponse{}
+	for _, uv := range userVotes {
+		i, ok := index[uv.UserID]
+		if !ok {
+			i = len(voters)
+			index[uv.UserID] = i
+			voters = append(voters, dto.VoterResponse{
+				UserID:    uv.UserID,
+				Username:  names[uv.UserID],
+				OptionIDs: []uint{},
+				VotedAt:   uv.CreatedAt,
+				ProxyID:   uv.ProxyID,
+			})
+			if uv.ProxyID != nil {
+				voters[i].ProxyUsername = names[*uv.ProxyID]
+			}
+		}
+		voters[i].OptionIDs = append(voters[i].OptionIDs, uv.OptionID)
+	}
+
+	return voters, nil
+}
+
+// GetUserVotes 分页返回当前用户创建的投票，创建者可以看到自己投票的结果，启用差分隐私的除外
+func (s *VoteService) GetUserVotes(userID uint, query *dto.VoteListQuery) (*VoteList, error) {
+	mine := *query
+	mine.CreatorID = userID
+	return listVotes(&mine, userID)
+}
+
+// GetAllVotes 按条件分页返回投票
+func (s *VoteService) GetAllVotes(userID uint, query *dto.VoteListQuery) (*VoteList, error) {
+	return listVotes(query, userID)
 }
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+)
+
+func TestCheckSelection(t *testing.T) {
+	options := []model.VoteOption{{ID: 1}, {ID: 2}, {ID: 3}}
+	tests := []struct {
+		name      string
+		multi     bool
+		optionIDs []uint
+		want      []uint
+		err       error
+	}{
+		{"single choice", false, []uint{2}, []uint{2}, nil},
+		{"single choice duplicated", false, []uint{2, 2}, []uint{2}, nil},
+		{"single choice with two options", false, []uint{1, 2}, nil, errSingleChoice},
+		{"multi choice deduplicated", true, []uint{1, 3, 1}, []uint{1, 3}, nil},
+		{"option of another vote", true, []uint{1, 9}, nil, errInvalidOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vote := &model.Vote{Multi: tt.multi, Options: options}
+			got, err := checkSelection(vote, tt.optionIDs)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkSelection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateVoteKeepsBallots(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 3)
+	s := NewVoteService()
+
+	vote, err := s.CreateVote(&dto.CreateVoteRequest{Title: "午饭", Options: []string{"面", "饭", "粥"}}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := optionsByContent(t, vote.ID)
+	ballots := map[uint]string{users[1].ID: "面", users[2].ID: "粥"}
+	for userID, content := range ballots {
+		if err := s.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{options[content].ID}}, userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 保留“面”，去掉“饭”和“粥”，新增“饺子”
+	err = s.UpdateVote(&dto.UpdateVoteRequest{
+		ID:                 vote.ID,
+		Title:              "午饭吃什么",
+		Options:            []string{"饺子", "面"},
+		OptionDescriptions: []string{"", "牛肉面"},
+	}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated := optionsByContent(t, vote.ID)
+	if len(updated) != 2 {
+		t.Fatalf("options = %v, want 面 and 饺子", updated)
+	}
+	kept, ok := updated["面"]
+	if !ok || kept.ID != options["面"].ID {
+		t.Fatalf("option 面 was recreated: %+v", kept)
+	}
+	if kept.Count != 1 || kept.Description != "牛肉面" {
+		t.Errorf("kept option = count %d description %q, want 1 %q", kept.Count, kept.Description, "牛肉面")
+	}
+	if updated["饺子"].Count != 0 {
+		t.Errorf("new option count = %d, want 0", updated["饺子"].Count)
+	}
+
+	var userVotes []model.UserVote
+	if err := database.GetDB().Where("vote_id = ?", vote.ID).Find(&userVotes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(userVotes) != 1 || userVotes[0].UserID != users[1].ID || userVotes[0].OptionID != kept.ID {
+		t.Errorf("ballots = %+v, want only user %d on option %d", userVotes, users[1].ID, kept.ID)
+	}
+
+	var got model.Vote
+	if err := database.GetDB().First(&got, vote.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "午饭吃什么" || got.VoterCount != 1 {
+		t.Errorf("vote = title %q voter_count %d, want %q 1", got.Title, got.VoterCount, "午饭吃什么")
+	}
+}
+
+func TestUpdateVoteRejectsSingleChoiceWithMultipleSelections(t *testing.T) {
+	setupTestDB(t)
+	users := createTestUsers(t, 2)
+	s := NewVoteService()
+
+	vote, err := s.CreateVote(&dto.CreateVoteRequest{Title: "周末活动", Options: []string{"爬山", "看电影", "读书"}, Multi: true}, users[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := optionsByContent(t, vote.ID)
+	err = s.Vote(&dto.VoteRequest{VoteID: vote.ID, OptionIDs: []uint{options["爬山"].ID, options["看电影"].ID}}, users[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options []string
+		wantErr bool
+	}{
+		// 两个选项都保留时该投票人仍然多选
+		{"both selections kept", []string{"爬山", "看电影"}, true},
+		// 去掉其中一个选项后只剩一个选择
+		{"one selection removed", []string{"爬山", "读书"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.UpdateVote(&dto.UpdateVoteRequest{ID: vote.ID, Title: vote.Title, Options: tt.options}, users[0].ID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var got model.Vote
+	if err := database.GetDB().First(&got, vote.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.Multi {
+		t.Error("vote is still multiple choice")
+	}
+}
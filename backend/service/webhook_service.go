@@ -0,0 +1,637 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	webhookPollInterval = 2 * time.Second
+	webhookBatchSize    = 50
+	webhookTimeout      = 10 * time.Second
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 10 * time.Second
+	webhookMaxBackoff   = time.Hour
+	// webhookWorkers 同时投递的订阅数上限，每个订阅同一时间只有一个投递在进行
+	webhookWorkers = 8
+)
+
+// webhookClient 只连接公网地址，防止通过 webhook 访问内网服务和云平台元数据接口。
+// 地址在建立连接时检查，域名解析到内网地址或重定向到内网地址也会被拒绝
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: webhookTimeout,
+			Control: webhookDialControl,
+		}).DialContext,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     time.Minute,
+	},
+}
+
+// inflightWebhooks 本进程中正在投递的订阅
+var inflightWebhooks = struct {
+	sync.Mutex
+	subs map[uint]bool
+}{subs: make(map[uint]bool)}
+
+type WebhookService struct{}
+
+func NewWebhookService() *WebhookService {
+	return &WebhookService{}
+}
+
+// writeOutbox 在业务事务中写入待分发的事件
+func writeOutbox(tx *gorm.DB, eventType string, vote *model.Vote, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.OutboxEvent{
+		Type:    eventType,
+		VoteID:  vote.ID,
+		OwnerID: vote.CreatorID,
+		Payload: string(payload),
+	}).Error
+}
+
+// blockedWebhookIP 判断地址是否为回环、内网、链路本地等不允许投递的地址
+func blockedWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 0 {
+		// 0.0.0.0/8 在部分系统上会连接到本机
+		return true
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// webhookDialControl 在连接前检查解析后的地址
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || blockedWebhookIP(ip) {
+		return fmt.Errorf("不允许投递到内网地址 %s", host)
+	}
+	return nil
+}
+
+// checkWebhookURL 只允许 http 和 https 地址，IP 地址形式的内网地址直接拒绝，
+// 域名在投递时按解析结果检查
+func checkWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return errors.New("无效的 webhook 地址")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("webhook 地址只支持 http 和 https")
+	}
+	if u.User != nil {
+		return errors.New("webhook 地址不能包含用户名和密码")
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && blockedWebhookIP(ip) {
+		return errors.New("webhook 地址不能是内网地址")
+	}
+	if strings.EqualFold(u.Hostname(), "localhost") {
+		return errors.New("webhook 地址不能是内网地址")
+	}
+	return nil
+}
+
+// signWebhook 计算 sha256=HMAC(secret, timestamp + "." + body)
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff 返回第 attempts 次失败后的等待时间
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// subscribesTo 判断订阅是否接收该事件，tags 为事件所属投票的标签
+func subscribesTo(sub *model.WebhookSubscription, event *model.OutboxEvent, tags []string) bool {
+	if sub.VoteID != nil && *sub.VoteID != event.VoteID {
+		return false
+	}
+	if sub.Tags != "" && !slices.ContainsFunc(strings.Split(sub.Tags, ","), func(tag string) bool {
+		return slices.Contains(tags, tag)
+	}) {
+		return false
+	}
+	if sub.Events == "" {
+		return true
+	}
+	for _, e := range strings.Split(sub.Events, ",") {
+		if e == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// StartWebhookDispatcher 启动后台投递器，定期分发 outbox 事件并投递到期的 webhook
+func StartWebhookDispatcher() {
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := notifyDeadlines(); err != nil {
+				log.Println("截止事件写入失败:", err)
+			}
+			if err := fanOutOutbox(); err != nil {
+				log.Println("webhook 事件分发失败:", err)
+			}
+			if err := deliverDue(); err != nil {
+				log.Println("webhook 投递失败:", err)
+			}
+		}
+	}()
+}
+
+// notifyDeadlines 为到达截止时间的投票写入 poll.closed 事件。手动关闭的投票在关闭时已经写入，
+// 问卷中的投票随问卷关闭，不单独发送
+func notifyDeadlines() error {
+	now := time.Now().Unix()
+	var votes []model.Vote
+	if err := database.GetDB().
+		Where("deadline > 0 AND deadline <= ? AND closed = ? AND deadline_notified <> deadline AND survey_id IS NULL", now, false).
+		Order("deadline").Limit(webhookBatchSize).Find(&votes).Error; err != nil {
+		return err
+	}
+
+	for i := range votes {
+		vote := &votes[i]
+		notified := false
+		err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+			// 多副本时只有一个实例能认领该投票
+			result := tx.Model(&model.Vote{}).
+				Where("id = ? AND deadline = ? AND deadline_notified <> deadline", vote.ID, vote.Deadline).
+				UpdateColumn("deadline_notified", vote.Deadline)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			notified = true
+			return writeOutbox(tx, model.EventPollClosed, vote, dto.PollStateData{Title: vote.Title})
+		})
+		if err != nil {
+			return err
+		}
+		if notified {
+			publishState(vote.ID, StateClosed)
+		}
+	}
+	return nil
+}
+
+// fanOutOutbox 为未处理的事件创建投递记录
+func fanOutOutbox() error {
+	var events []model.OutboxEvent
+	if err := database.GetDB().Where("processed = ?", false).Order("id").Limit(webhookBatchSize).Find(&events).Error; err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		var subs []model.WebhookSubscription
+		if err := database.GetDB().Where("user_id = ? AND active = ?", event.OwnerID, true).Find(&subs).Error; err != nil {
+			return err
+		}
+		var tags []string
+		if err := database.GetDB().Model(&model.VoteTag{}).Where("vote_id = ?", event.VoteID).Pluck("name", &tags).Error; err != nil {
+			return err
+		}
+
+		// 开始事务
+		tx := database.GetDB().Begin()
+
+		// 多副本时只有一个实例能认领该事件
+		result := tx.Model(&model.OutboxEvent{}).Where("id = ? AND processed = ?", event.ID, false).Update("processed", true)
+		if result.Error != nil {
+			tx.Rollback()
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			continue
+		}
+
+		for i := range subs {
+			if !subscribesTo(&subs[i], &event, tags) {
+				continue
+			}
+			delivery := model.WebhookDelivery{
+				SubscriptionID: subs[i].ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Status:         model.DeliveryPending,
+				NextAttemptAt:  time.Now(),
+			}
+			if err := tx.Create(&delivery).Error; err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverDue 为有到期投递记录的订阅启动投递。每个订阅按顺序投递，不同订阅并行，
+// 失效的地址只占用自己的投递协程，不影响其他订阅
+func deliverDue() error {
+	inflightWebhooks.Lock()
+	busy := make([]uint, 0, len(inflightWebhooks.subs))
+	for id := range inflightWebhooks.subs {
+		busy = append(busy, id)
+	}
+	inflightWebhooks.Unlock()
+	if len(busy) >= webhookWorkers {
+		return nil
+	}
+
+	query := database.GetDB().Model(&model.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, time.Now())
+	if len(busy) > 0 {
+		query = query.Where("subscription_id NOT IN ?", busy)
+	}
+	var subIDs []uint
+	if err := query.Distinct().Limit(webhookWorkers-len(busy)).Pluck("subscription_id", &subIDs).Error; err != nil {
+		return err
+	}
+
+	for _, subID := range subIDs {
+		inflightWebhooks.Lock()
+		if inflightWebhooks.subs[subID] || len(inflightWebhooks.subs) >= webhookWorkers {
+			inflightWebhooks.Unlock()
+			continue
+		}
+		inflightWebhooks.subs[subID] = true
+		inflightWebhooks.Unlock()
+
+		go func(subID uint) {
+			defer func() {
+				inflightWebhooks.Lock()
+				delete(inflightWebhooks.subs, subID)
+				inflightWebhooks.Unlock()
+			}()
+			if err := deliverSubscription(subID); err != nil {
+				log.Println("webhook 投递失败:", err)
+			}
+		}(subID)
+	}
+	return nil
+}
+
+// deliverSubscription 按顺序投递一个订阅到期的记录，失败后停止，剩余的记录留到下一轮，
+// 避免失效的地址在一轮中反复超时
+func deliverSubscription(subID uint) error {
+	var deliveries []model.WebhookDelivery
+	if err := database.GetDB().
+		Where("subscription_id = ? AND status = ? AND next_attempt_at <= ?", subID, model.DeliveryPending, time.Now()).
+		Order("next_attempt_at").Limit(webhookBatchSize).Find(&deliveries).Error; err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// 先推迟下次投递时间以认领该记录，避免多副本重复投递
+		claimUntil := time.Now().Add(webhookTimeout * 2)
+		result := database.GetDB().Model(&model.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, model.DeliveryPending, delivery.NextAttemptAt).
+			Update("next_attempt_at", claimUntil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		succeeded, err := attemptDelivery(delivery)
+		if err != nil {
+			return err
+		}
+		if !succeeded {
+			return nil
+		}
+	}
+	return nil
+}
+
+// attemptDelivery 执行一次投递并记录结果，返回是否投递成功
+func attemptDelivery(delivery *model.WebhookDelivery) (bool, error) {
+	var sub model.WebhookSubscription
+	if err := database.GetDB().First(&sub, delivery.SubscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, database.GetDB().Model(delivery).Updates(map[string]interface{}{
+				"status":     model.DeliveryFailed,
+				"last_error": "订阅已删除",
+			}).Error
+		}
+		return false, err
+	}
+	// 停用期间到期的投递不再发送，重新启用后可以手动重新投递
+	if !sub.Active {
+		return false, database.GetDB().Model(delivery).Updates(map[string]interface{}{
+			"status":     model.DeliveryFailed,
+			"last_error": "订阅已停用",
+		}).Error
+	}
+
+	var event model.OutboxEvent
+	if err := database.GetDB().First(&event, delivery.EventID).Error; err != nil {
+		return false, err
+	}
+
+	body, err := json.Marshal(dto.WebhookPayload{
+		ID:         event.ID,
+		Event:      event.Type,
+		VoteID:     event.VoteID,
+		OccurredAt: event.CreatedAt.Unix(),
+		Data:       json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	statusCode, sendErr := sendWebhook(&sub, delivery, event.Type, body)
+
+	delivery.Attempts++
+	updates := map[string]interface{}{
+		"attempts":         delivery.Attempts,
+		"last_status_code": statusCode,
+		"last_error":       "",
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = model.DeliverySucceeded
+	case delivery.Attempts >= webhookMaxAttempts:
+		updates["status"] = model.DeliveryFailed
+		updates["last_error"] = truncate(sendErr.Error(), 512)
+	default:
+		updates["next_attempt_at"] = time.Now().Add(webhookBackoff(delivery.Attempts))
+		updates["last_error"] = truncate(sendErr.Error(), 512)
+	}
+
+	return sendErr == nil, database.GetDB().Model(delivery).Updates(updates).Error
+}
+
+func sendWebhook(sub *model.WebhookSubscription, delivery *model.WebhookDelivery, eventType string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "vote-system-webhook")
+	req.Header.Set("X-Vote-Event", eventType)
+	req.Header.Set("X-Vote-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Vote-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Vote-Signature", signWebhook(sub.Secret, timestamp, body))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func (s *WebhookService) findOwnedSubscription(id uint, userID uint) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	if err := database.GetDB().First(&sub, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("订阅不存在")
+		}
+		return nil, err
+	}
+	if sub.UserID != userID {
+		return nil, errors.New("没有权限操作此订阅")
+	}
+	return &sub, nil
+}
+
+func checkWebhookVote(voteID *uint, userID uint) error {
+	if voteID == nil {
+		return nil
+	}
+	vote, err := findVote(*voteID)
+	if err != nil {
+		return err
+	}
+	if vote.CreatorID != userID {
+		return errors.New("只能订阅自己创建的投票")
+	}
+	return nil
+}
+
+// webhookTags 规范化订阅的标签过滤条件
+func webhookTags(tags []string) (string, error) {
+	tags = normalizeTags(tags)
+	for _, tag := range tags {
+		if strings.Contains(tag, ",") {
+			return "", errors.New("标签不能包含逗号")
+		}
+	}
+	return strings.Join(tags, ","), nil
+}
+
+// CreateSubscription 创建订阅，签名密钥只在创建时返回一次
+func (s *WebhookService) CreateSubscription(req *dto.CreateWebhookRequest, userID uint) (*model.WebhookSubscription, string, error) {
+	if err := checkWebhookURL(req.URL); err != nil {
+		return nil, "", err
+	}
+	if err := checkWebhookVote(req.VoteID, userID); err != nil {
+		return nil, "", err
+	}
+	tags, err := webhookTags(req.Tags)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	sub := model.WebhookSubscription{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: strings.Join(req.Events, ","),
+		VoteID: req.VoteID,
+		Tags:   tags,
+		Active: true,
+	}
+	if err := database.GetDB().Create(&sub).Error; err != nil {
+		return nil, "", err
+	}
+	return &sub, secret, nil
+}
+
+func (s *WebhookService) UpdateSubscription(id uint, req *dto.UpdateWebhookRequest, userID uint) error {
+	sub, err := s.findOwnedSubscription(id, userID)
+	if err != nil {
+		return err
+	}
+	if err := checkWebhookURL(req.URL); err != nil {
+		return err
+	}
+	if err := checkWebhookVote(req.VoteID, userID); err != nil {
+		return err
+	}
+	tags, err := webhookTags(req.Tags)
+	if err != nil {
+		return err
+	}
+
+	// 停用或更换地址后，尚未投递的记录不再发送，避免发往新的地址
+	reason := ""
+	switch {
+	case !req.Active:
+		reason = "订阅已停用"
+	case req.URL != sub.URL:
+		reason = "订阅地址已修改"
+	}
+
+	sub.URL = req.URL
+	sub.Events = strings.Join(req.Events, ",")
+	sub.VoteID = req.VoteID
+	sub.Tags = tags
+	sub.Active = req.Active
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(sub).Error; err != nil {
+			return err
+		}
+		if reason == "" {
+			return nil
+		}
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("subscription_id = ? AND status = ?", sub.ID, model.DeliveryPending).
+			Updates(map[string]interface{}{
+				"status":     model.DeliveryFailed,
+				"last_error": reason,
+			}).Error
+	})
+}
+
+func (s *WebhookService) DeleteSubscription(id uint, userID uint) error {
+	sub, err := s.findOwnedSubscription(id, userID)
+	if err != nil {
+		return err
+	}
+	return database.GetDB().Delete(sub).Error
+}
+
+func (s *WebhookService) GetMySubscriptions(userID uint) ([]model.WebhookSubscription, error) {
+	var subs []model.WebhookSubscription
+	if err := database.GetDB().Where("user_id = ?", userID).Order("id DESC").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// GetDeliveries 返回订阅的投递记录，可按状态过滤
+func (s *WebhookService) GetDeliveries(id uint, status string, userID uint) ([]model.WebhookDelivery, error) {
+	sub, err := s.findOwnedSubscription(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := database.GetDB().Where("subscription_id = ?", sub.ID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []model.WebhookDelivery
+	if err := query.Order("id DESC").Limit(200).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Redeliver 重新投递，重置重试次数
+func (s *WebhookService) Redeliver(deliveryID uint, userID uint) error {
+	var delivery model.WebhookDelivery
+	if err := database.GetDB().First(&delivery, deliveryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("投递记录不存在")
+		}
+		return err
+	}
+
+	sub, err := s.findOwnedSubscription(delivery.SubscriptionID, userID)
+	if err != nil {
+		return err
+	}
+	if !sub.Active {
+		return errors.New("订阅已停用")
+	}
+
+	return database.GetDB().Model(&delivery).Updates(map[string]interface{}{
+		"status":          model.DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+}
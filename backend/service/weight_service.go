@@ -0,0 +1,286 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"vote-system-backend/database"
+	"vote-system-backend/dto"
+	"vote-system-backend/model"
+
+	"gorm.io/gorm"
+)
+
+type WeightService struct{}
+
+func NewWeightService() *WeightService {
+	return &WeightService{}
+}
+
+// voterWeight 返回用户在该投票中的权重，未启用权重的投票每人权重为1
+func voterWeight(vote *model.Vote, userID uint) (float64, error) {
+	if vote.WeightSetID == nil {
+		return 1, nil
+	}
+
+	var entry model.WeightEntry
+	if err := database.GetDB().Where("set_id = ? AND user_id = ?", *vote.WeightSetID, userID).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("不在投票权重名单中")
+		}
+		return 0, err
+	}
+	if entry.Weight <= 0 {
+		return 0, errors.New("不在投票权重名单中")
+	}
+	return entry.Weight, nil
+}
+
+// voterWeights 批量返回用户权重，不在名单中的用户不会出现在结果中
+func voterWeights(vote *model.Vote, userIDs []uint) (map[uint]float64, error) {
+	weights := make(map[uint]float64)
+	if vote.WeightSetID == nil {
+		for _, id := range userIDs {
+			weights[id] = 1
+		}
+		return weights, nil
+	}
+	if len(userIDs) == 0 {
+		return weights, nil
+	}
+
+	var entries []model.WeightEntry
+	if err := database.GetDB().Where("set_id = ? AND user_id IN ? AND weight > 0", *vote.WeightSetID, userIDs).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		weights[e.UserID] = e.Weight
+	}
+	return weights, nil
+}
+
+// checkWeightSet 检查权重名单是否存在且属于指定用户之一
+func checkWeightSet(setID *uint, ownerIDs ...uint) error {
+	if setID == nil {
+		return nil
+	}
+
+	var set model.WeightSet
+	if err := database.GetDB().First(&set, *setID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("权重名单不存在")
+		}
+		return err
+	}
+
+	for _, id := range ownerIDs {
+		if set.OwnerID == id {
+			return nil
+		}
+	}
+	return errors.New("没有权限使用此权重名单")
+}
+
+func (s *WeightService) findOwnedSet(id uint, userID uint) (*model.WeightSet, error) {
+	var set model.WeightSet
+	if err := database.GetDB().First(&set, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("权重名单不存在")
+		}
+		return nil, err
+	}
+
+	if set.OwnerID != userID {
+		return nil, errors.New("没有权限操作此权重名单")
+	}
+	return &set, nil
+}
+
+func (s *WeightService) CreateWeightSet(req *dto.CreateWeightSetRequest, userID uint) (*model.WeightSet, error) {
+	set := model.WeightSet{
+		Name:    req.Name,
+		OwnerID: userID,
+	}
+
+	if err := database.GetDB().Create(&set).Error; err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (s *WeightService) GetMyWeightSets(userID uint) ([]dto.WeightSetSummary, error) {
+	var sets []model.WeightSet
+	if err := database.GetDB().Where("owner_id = ?", userID).Order("id DESC").Find(&sets).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.WeightSetSummary, 0, len(sets))
+	for _, set := range sets {
+		var summary struct {
+			Members int64
+			Total   float64
+		}
+		if err := database.GetDB().Model(&model.WeightEntry{}).
+			Select("COUNT(*) AS members, COALESCE(SUM(weight), 0) AS total").
+			Where("set_id = ?", set.ID).Scan(&summary).Error; err != nil {
+			return nil, err
+		}
+
+		result = append(result, dto.WeightSetSummary{
+			ID:          set.ID,
+			Name:        set.Name,
+			Members:     summary.Members,
+			TotalWeight: summary.Total,
+			CreatedAt:   set.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+func (s *WeightService) GetWeightSet(id uint, userID uint) (*dto.WeightSetResponse, error) {
+	set, err := s.findOwnedSet(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []model.WeightEntry
+	if err := database.GetDB().Where("set_id = ?", set.ID).Order("weight DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.UserID)
+	}
+	names, err := usernamesByID(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.WeightSetResponse{
+		ID:      set.ID,
+		Name:    set.Name,
+		Entries: make([]dto.WeightEntryResponse, 0, len(entries)),
+	}
+	for _, e := range entries {
+		response.Entries = append(response.Entries, dto.WeightEntryResponse{
+			UserID:   e.UserID,
+			Username: names[e.UserID],
+			Weight:   e.Weight,
+		})
+	}
+	return response, nil
+}
+
+// ImportCSV 用CSV替换名单内容，格式为 username,weight，允许带表头
+func (s *WeightService) ImportCSV(id uint, r io.Reader, userID uint) (int, error) {
+	set, err := s.findOwnedSet(id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	weights := make(map[string]float64)
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return 0, fmt.Errorf("第%d行格式错误", line)
+		}
+
+		username := strings.TrimSpace(record[0])
+		weight, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			// 第一行无法解析时视为表头
+			if line == 1 {
+				continue
+			}
+			return 0, fmt.Errorf("第%d行权重无效", line)
+		}
+		// ParseFloat 接受 NaN 和 Inf，会让加权票数和百分比失效
+		if username == "" || weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return 0, fmt.Errorf("第%d行权重无效", line)
+		}
+		weights[username] = weight
+	}
+
+	if len(weights) == 0 {
+		return 0, errors.New("名单为空")
+	}
+
+	usernames := make([]string, 0, len(weights))
+	for name := range weights {
+		usernames = append(usernames, name)
+	}
+
+	var users []model.User
+	if err := database.GetDB().Select("id", "username").Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		return 0, err
+	}
+	if len(users) != len(usernames) {
+		found := make(map[string]bool)
+		for _, u := range users {
+			found[u.Username] = true
+		}
+		for _, name := range usernames {
+			if !found[name] {
+				return 0, fmt.Errorf("用户 %s 不存在", name)
+			}
+		}
+	}
+
+	entries := make([]model.WeightEntry, 0, len(users))
+	for _, u := range users {
+		entries = append(entries, model.WeightEntry{
+			SetID:  set.ID,
+			UserID: u.ID,
+			Weight: weights[u.Username],
+		})
+	}
+
+	// 开始事务
+	tx := database.GetDB().Begin()
+
+	if err := tx.Where("set_id = ?", set.ID).Delete(&model.WeightEntry{}).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.CreateInBatches(&entries, 500).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+func (s *WeightService) DeleteWeightSet(id uint, userID uint) error {
+	set, err := s.findOwnedSet(id, userID)
+	if err != nil {
+		return err
+	}
+
+	var inUse int64
+	if err := database.GetDB().Model(&model.Vote{}).Where("weight_set_id = ?", set.ID).Count(&inUse).Error; err != nil {
+		return err
+	}
+	if inUse > 0 {
+		return errors.New("权重名单正在被投票使用")
+	}
+
+	return database.GetDB().Select("Entries").Delete(set).Error
+}
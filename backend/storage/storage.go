@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"fmt"
+	"vote-system-backend/config"
+)
+
+// Storage 上传文件的存储接口，Key 为以 / 分隔的相对路径
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	// Delete 删除文件，文件不存在时不报错
+	Delete(key string) error
+	// URL 返回浏览器访问文件的地址
+	URL(key string) string
+}
+
+var storage Storage = NewLocalStorage("uploads", "/uploads")
+
+func SetStorage(s Storage) {
+	storage = s
+}
+
+func GetStorage() Storage {
+	return storage
+}
+
+// New 按配置创建存储
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.Dir, cfg.BaseURL), nil
+	case "s3":
+		return NewS3Storage(cfg)
+	}
+	return nil, fmt.Errorf("不支持的存储类型: %s", cfg.Driver)
+}
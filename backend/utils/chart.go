@@ -0,0 +1,343 @@
+package utils
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// ChartPalette 结果横条的颜色，与前端结果页一致
+var ChartPalette = []color.RGBA{
+	{37, 99, 235, 255},  // blue-600
+	{34, 197, 94, 255},  // green-500
+	{245, 158, 11, 255}, // amber-500
+	{239, 68, 68, 255},  // red-500
+	{168, 85, 247, 255}, // purple-500
+	{236, 72, 153, 255}, // pink-500
+	{20, 184, 166, 255}, // teal-500
+	{99, 102, 241, 255}, // indigo-500
+}
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartTrack      = color.RGBA{229, 231, 235, 255} // gray-200
+	chartText       = color.RGBA{17, 24, 39, 255}    // gray-900
+	chartMuted      = color.RGBA{75, 85, 99, 255}    // gray-600
+)
+
+// chartFontFamily SVG 由浏览器渲染文字，优先使用常见的中文字体
+const chartFontFamily = `-apple-system, "PingFang SC", "Microsoft YaHei", "Noto Sans CJK SC", sans-serif`
+
+type ChartBar struct {
+	Label string
+	Ratio float64 // 0~1，决定横条长度
+	Value string  // 显示在横条右上方的文字
+}
+
+type Chart struct {
+	Title    string
+	Subtitle string
+	Bars     []ChartBar
+	Note     string // 没有横条时显示的提示
+}
+
+// chartOp 一个绘图操作，SVG 和 PNG 使用同一组操作保证外观一致
+type chartOp struct {
+	rect  bool
+	x, y  float64 // 文字为基线起点，右对齐时为基线终点
+	w, h  float64
+	size  float64
+	end   bool
+	color color.RGBA
+	text  string
+}
+
+// layout 按宽度和缩放比例排版，height 为 0 时按内容计算高度
+func (c *Chart) layout(width int, height int, scale float64) ([]chartOp, int) {
+	pad := 24 * scale
+	titleSize := 22 * scale
+	subSize := 14 * scale
+	labelSize := 15 * scale
+	barHeight := 12 * scale
+	inner := float64(width) - 2*pad
+
+	var ops []chartOp
+	y := pad + titleSize
+	ops = append(ops, chartOp{x: pad, y: y, size: titleSize, color: chartText, text: truncateText(c.Title, titleSize, inner)})
+	if c.Subtitle != "" {
+		y += subSize * 1.6
+		ops = append(ops, chartOp{x: pad, y: y, size: subSize, color: chartMuted, text: truncateText(c.Subtitle, subSize, inner)})
+	}
+	y += 12 * scale
+
+	if len(c.Bars) == 0 && c.Note != "" {
+		y += labelSize * 1.6
+		ops = append(ops, chartOp{x: pad, y: y, size: labelSize, color: chartMuted, text: c.Note})
+	}
+	for i, bar := range c.Bars {
+		ratio := bar.Ratio
+		if ratio < 0 {
+			ratio = 0
+		}
+		if ratio > 1 {
+			ratio = 1
+		}
+
+		valueWidth := textWidth(bar.Value, labelSize)
+		y += labelSize * 1.3
+		ops = append(ops,
+			chartOp{x: pad, y: y, size: labelSize, color: chartText, text: truncateText(bar.Label, labelSize, inner-valueWidth-labelSize)},
+			chartOp{x: pad + inner, y: y, size: labelSize, color: chartMuted, text: bar.Value, end: true},
+		)
+		y += 6 * scale
+		ops = append(ops, chartOp{rect: true, x: pad, y: y, w: inner, h: barHeight, color: chartTrack})
+		if ratio > 0 {
+			ops = append(ops, chartOp{rect: true, x: pad, y: y, w: inner * ratio, h: barHeight, color: ChartPalette[i%len(ChartPalette)]})
+		}
+		y += barHeight + 14*scale
+	}
+
+	if height == 0 {
+		height = int(y + pad - 14*scale + 0.5)
+	}
+	return ops, height
+}
+
+// RenderSVG 输出结果横条图的 SVG
+func (c *Chart) RenderSVG(width int) []byte {
+	ops, height := c.layout(width, 0, 1)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family='%s'>`,
+		width, height, width, height, chartFontFamily)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(chartBackground))
+	for _, op := range ops {
+		if op.rect {
+			fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="%.1f" fill="%s"/>`,
+				op.x, op.y, op.w, op.h, op.h/2, hexColor(op.color))
+			continue
+		}
+		anchor := ""
+		if op.end {
+			anchor = ` text-anchor="end"`
+		}
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" font-size="%.1f" fill="%s"%s>`, op.x, op.y, op.size, hexColor(op.color), anchor)
+		xml.EscapeText(&buf, []byte(op.text))
+		buf.WriteString(`</text>`)
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// RenderPNG 输出结果横条图的 PNG，height 为 0 时按内容计算高度
+func (c *Chart) RenderPNG(width int, height int, scale float64) ([]byte, error) {
+	fonts, err := chartFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	ops, height := c.layout(width, height, scale)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+
+	faces := make(map[float64][]font.Face)
+	defer func() {
+		for _, list := range faces {
+			for _, face := range list {
+				face.Close()
+			}
+		}
+	}()
+
+	for _, op := range ops {
+		if op.rect {
+			rect := image.Rect(int(op.x), int(op.y), int(op.x+op.w+0.5), int(op.y+op.h+0.5))
+			draw.Draw(img, rect, image.NewUniform(op.color), image.Point{}, draw.Over)
+			continue
+		}
+		list, ok := faces[op.size]
+		if !ok {
+			for _, f := range fonts {
+				face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: op.size, DPI: 72, Hinting: font.HintingFull})
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, face)
+			}
+			faces[op.size] = list
+		}
+		drawText(img, fonts, list, op)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	chartFontOnce     sync.Once
+	chartFontList     []*opentype.Font
+	chartFontErr      error
+	chartFallbackFont *opentype.Font
+)
+
+// embeddedFonts 编译时放入 fonts 目录的补充字体，Docker 镜像构建时放入文泉驿微米黑
+//
+//go:embed fonts
+var embeddedFonts embed.FS
+
+// parseChartFont 解析 TTF、OTF 和 TTC 字体，TTC 使用集合中的第一个字体
+func parseChartFont(data []byte) (*opentype.Font, error) {
+	// 空数据会使 ParseCollection 崩溃
+	if len(data) == 0 {
+		return nil, errors.New("字体文件为空")
+	}
+	f, err := opentype.Parse(data)
+	if err == nil {
+		return f, nil
+	}
+	collection, cerr := opentype.ParseCollection(data)
+	if cerr != nil {
+		return nil, err
+	}
+	return collection.Font(0)
+}
+
+// LoadChartFont 加载 PNG 中内置字体缺字时使用的字体文件，如中文字体，优先于编译时内置的补充字体。
+// 需在启动时、首次渲染前调用
+func LoadChartFont(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := parseChartFont(data)
+	if err != nil {
+		return err
+	}
+	chartFallbackFont = f
+	return nil
+}
+
+// embeddedFallbackFont 返回 fonts 目录中按文件名排序的第一个字体，没有时返回 nil
+func embeddedFallbackFont() (*opentype.Font, error) {
+	entries, err := fs.ReadDir(embeddedFonts, "fonts")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		switch path.Ext(entry.Name()) {
+		case ".ttf", ".otf", ".ttc":
+		default:
+			continue
+		}
+		data, err := embeddedFonts.ReadFile("fonts/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		return parseChartFont(data)
+	}
+	return nil, nil
+}
+
+// chartFonts 返回按优先级排列的字体，内置的 Go Regular 字体只包含西文字形，
+// 缺字时依次使用 CHART_FONT 指定的字体或编译时内置的补充字体
+func chartFonts() ([]*opentype.Font, error) {
+	chartFontOnce.Do(func() {
+		regular, err := opentype.Parse(goregular.TTF)
+		if err != nil {
+			chartFontErr = err
+			return
+		}
+		chartFontList = []*opentype.Font{regular}
+		fallback := chartFallbackFont
+		if fallback == nil {
+			if fallback, err = embeddedFallbackFont(); err != nil {
+				chartFontErr = err
+				return
+			}
+		}
+		if fallback != nil {
+			chartFontList = append(chartFontList, fallback)
+		}
+	})
+	return chartFontList, chartFontErr
+}
+
+// faceFor 返回第一个包含该字符的字体，都不包含时使用内置字体
+func faceFor(fonts []*opentype.Font, faces []font.Face, r rune) font.Face {
+	var buf sfnt.Buffer
+	for i, f := range fonts {
+		if index, err := f.GlyphIndex(&buf, r); err == nil && index != 0 {
+			return faces[i]
+		}
+	}
+	return faces[0]
+}
+
+func drawText(dst draw.Image, fonts []*opentype.Font, faces []font.Face, op chartOp) {
+	advance := func(r rune) fixed.Int26_6 {
+		a, _ := faceFor(fonts, faces, r).GlyphAdvance(r)
+		return a
+	}
+
+	x := fixed.Int26_6(op.x * 64)
+	if op.end {
+		for _, r := range op.text {
+			x -= advance(r)
+		}
+	}
+
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(op.color)}
+	d.Dot = fixed.Point26_6{X: x, Y: fixed.Int26_6(op.y * 64)}
+	for _, r := range op.text {
+		d.Face = faceFor(fonts, faces, r)
+		d.DrawString(string(r))
+	}
+}
+
+// textWidth 估算文字宽度，全角字符按一个字号、半角字符按半个字号计算
+func textWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		if r < 0x80 {
+			width += size * 0.55
+		} else {
+			width += size
+		}
+	}
+	return width
+}
+
+// truncateText 超出宽度时截断并加省略号
+func truncateText(text string, size float64, width float64) string {
+	if textWidth(text, size) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(string(runes), size)+size > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
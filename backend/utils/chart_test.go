@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestParseChartFont(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"ttf", goregular.TTF, false},
+		{"not a font", []byte("not a font"), true},
+		{"empty", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseChartFont(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseChartFont() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChartFontsIncludesEmbeddedFont(t *testing.T) {
+	fallback, err := embeddedFallbackFont()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fonts, err := chartFonts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 1
+	if fallback != nil {
+		want = 2
+	}
+	if len(fonts) != want {
+		t.Errorf("chartFonts() returned %d fonts, want %d", len(fonts), want)
+	}
+}
@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenderMarkdown 把 Markdown 渲染为可以直接嵌入页面的 HTML。
+//
+// 只支持常用的子集：标题、段落、引用、有序和无序列表、代码块、分隔线，以及行内的粗体、斜体、
+// 删除线、代码和链接。原文中的 HTML 一律转义；链接只允许 http、https 和 mailto，并在新窗口打开；
+// 图片只渲染为指向图片的链接，避免加载外部资源。
+func RenderMarkdown(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"))
+	return b.String()
+}
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	unorderedPattern   = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	rulePattern        = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	fencePattern       = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	blockquotePattern  = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	markdownPunctation = "\\`*_{}[]()#+-.!~>|"
+)
+
+// isRule 判断是否为分隔线，三个以上相同的 -、* 或 _
+func isRule(line string) bool {
+	if !rulePattern.MatchString(line) {
+		return false
+	}
+	trimmed := strings.Join(strings.Fields(line), "")
+	return strings.Count(trimmed, trimmed[:1]) == len(trimmed)
+}
+
+// blockStart 判断该行是否开始一个新的块，用于结束段落
+func blockStart(line string) bool {
+	return headingPattern.MatchString(line) || fencePattern.MatchString(line) ||
+		blockquotePattern.MatchString(line) || unorderedPattern.MatchString(line) ||
+		orderedPattern.MatchString(line) || isRule(line)
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case fencePattern.MatchString(line):
+			fence := fencePattern.FindStringSubmatch(line)[1]
+			i++
+			b.WriteString("<pre><code>")
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+				b.WriteString(html.EscapeString(lines[i]))
+				b.WriteString("\n")
+			}
+			b.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			level := string(rune('0' + len(m[1])))
+			b.WriteString("<h" + level + ">")
+			renderInline(b, m[2])
+			b.WriteString("</h" + level + ">\n")
+			i++
+
+		case isRule(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case blockquotePattern.MatchString(line):
+			var inner []string
+			for ; i < len(lines) && blockquotePattern.MatchString(lines[i]); i++ {
+				inner = append(inner, blockquotePattern.FindStringSubmatch(lines[i])[1])
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, inner)
+			b.WriteString("</blockquote>\n")
+
+		case unorderedPattern.MatchString(line), orderedPattern.MatchString(line):
+			pattern, tag := unorderedPattern, "ul"
+			if !unorderedPattern.MatchString(line) {
+				pattern, tag = orderedPattern, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			for i < len(lines) && pattern.MatchString(lines[i]) && !isRule(lines[i]) {
+				item := pattern.FindStringSubmatch(lines[i])[1]
+				i++
+				// 缩进的后续行属于同一列表项
+				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && (lines[i][0] == ' ' || lines[i][0] == '\t') &&
+					!unorderedPattern.MatchString(lines[i]) && !orderedPattern.MatchString(lines[i]) {
+					item += "\n" + strings.TrimSpace(lines[i])
+					i++
+				}
+				b.WriteString("<li>")
+				renderInline(b, item)
+				b.WriteString("</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			var para []string
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				if len(para) > 0 && blockStart(lines[i]) {
+					break
+				}
+				para = append(para, strings.TrimSpace(lines[i]))
+			}
+			b.WriteString("<p>")
+			renderInline(b, strings.Join(para, "\n"))
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+// safeURL 只允许 http、https 和 mailto 链接，其余返回空
+func safeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if fields := strings.Fields(raw); len(fields) > 0 {
+		// 忽略链接标题
+		raw = strings.Trim(fields[0], "<>")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return u.String()
+	}
+	return ""
+}
+
+// closingBracket 返回与 s[0] 的 '[' 匹配的 ']' 的位置
+func closingBracket(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isWordAt 判断位置上的字符是否为字母或数字，用于识别单词内的下划线
+func isWordAt(s string, i int, before bool) bool {
+	var r rune
+	if before {
+		if i <= 0 {
+			return false
+		}
+		r, _ = utf8.DecodeLastRuneInString(s[:i])
+	} else {
+		if i >= len(s) {
+			return false
+		}
+		r, _ = utf8.DecodeRuneInString(s[i:])
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// findClosing 在 s 中查找强调的结束标记，跳过代码和转义
+func findClosing(s, delim string) int {
+	for i := 0; i+len(delim) <= len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				i += end + 1
+			}
+		case strings.HasPrefix(s[i:], delim):
+			// 单个 * 或 _ 不能是 ** 或 __ 的一部分
+			if len(delim) == 1 && i+1 < len(s) && s[i+1] == delim[0] {
+				i++
+				continue
+			}
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\n' {
+				continue
+			}
+			if delim[0] == '_' && isWordAt(s, i+len(delim), false) {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func renderInline(b *strings.Builder, s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(markdownPunctation, s[i+1]) >= 0:
+			b.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '\n':
+			b.WriteString("<br>\n")
+			i++
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>")
+				b.WriteString(html.EscapeString(s[i+1 : i+1+end]))
+				b.WriteString("</code>")
+				i += end + 2
+				continue
+			}
+
+		case c == '[' || (c == '!' && i+1 < len(s) && s[i+1] == '['):
+			start := i
+			if c == '!' {
+				start++
+			}
+			if end := closingBracket(s[start:]); end > 0 {
+				textEnd := start + end
+				if textEnd+1 < len(s) && s[textEnd+1] == '(' {
+					if urlEnd := strings.IndexByte(s[textEnd+2:], ')'); urlEnd >= 0 {
+						text := s[start+1 : textEnd]
+						href := safeURL(s[textEnd+2 : textEnd+2+urlEnd])
+						if href == "" {
+							renderInline(b, text)
+						} else {
+							b.WriteString(`<a href="` + html.EscapeString(href) + `" target="_blank" rel="nofollow noopener noreferrer">`)
+							if c == '!' && text == "" {
+								b.WriteString(html.EscapeString(href))
+							} else {
+								renderInline(b, text)
+							}
+							b.WriteString("</a>")
+						}
+						i = textEnd + 3 + urlEnd
+						continue
+					}
+				}
+			}
+
+		case strings.HasPrefix(s[i:], "**") || strings.HasPrefix(s[i:], "__") || strings.HasPrefix(s[i:], "~~"):
+			delim := s[i : i+2]
+			tag := "strong"
+			if delim == "~~" {
+				tag = "del"
+			}
+			if !(delim == "__" && isWordAt(s, i, true)) && i+2 < len(s) && s[i+2] != ' ' {
+				if end := findClosing(s[i+2:], delim); end >= 0 {
+					b.WriteString("<" + tag + ">")
+					renderInline(b, s[i+2:i+2+end])
+					b.WriteString("</" + tag + ">")
+					i += end + 4
+					continue
+				}
+			}
+			b.WriteString(html.EscapeString(delim))
+			i += 2
+			continue
+
+		case c == '*' || c == '_':
+			delim := s[i : i+1]
+			if !(c == '_' && isWordAt(s, i, true)) && i+1 < len(s) && s[i+1] != ' ' {
+				if end := findClosing(s[i+1:], delim); end >= 0 {
+					b.WriteString("<em>")
+					renderInline(b, s[i+1:i+1+end])
+					b.WriteString("</em>")
+					i += end + 2
+					continue
+				}
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(html.EscapeString(s[i : i+size]))
+		i += size
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+const linkAttrs = ` target="_blank" rel="nofollow noopener noreferrer"`
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"heading", "# 标题", "<h1>标题</h1>\n"},
+		{"inline styles", "**粗体** 和 *斜体* 和 ~~删除~~ 和 `code`",
+			"<p><strong>粗体</strong> 和 <em>斜体</em> 和 <del>删除</del> 和 <code>code</code></p>\n"},
+		{"html is escaped", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"link", "[链接](https://example.com)", `<p><a href="https://example.com"` + linkAttrs + ">链接</a></p>\n"},
+		{"link attributes are escaped", `[x](https://e.com/?a=1&b="2")`,
+			`<p><a href="https://e.com/?a=1&amp;b=&#34;2&#34;"` + linkAttrs + ">x</a></p>\n"},
+		{"image becomes a link", "![图](https://e.com/a.png)", `<p><a href="https://e.com/a.png"` + linkAttrs + ">图</a></p>\n"},
+		{"unordered list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"ordered list", "1. a\n2. b", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"blockquote", "> 引用", "<blockquote>\n<p>引用</p>\n</blockquote>\n"},
+		{"code block is escaped", "```\n<b>x</b>\n```", "<pre><code>&lt;b&gt;x&lt;/b&gt;\n</code></pre>\n"},
+		{"rule", "---", "<hr>\n"},
+		{"underscores inside words", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"line break", "第一行\n第二行", "<p>第一行<br>\n第二行</p>\n"},
+		{"escaped emphasis", `\*不是斜体\*`, "<p>*不是斜体*</p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.in); got != tt.want {
+				t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownUnsafeLinks(t *testing.T) {
+	for _, in := range []string{
+		"[x](javascript:alert(1))",
+		"[x](JaVaScRiPt:alert(1))",
+		"![x](data:text/html,<script>alert(1)</script>)",
+		"[x](vbscript:msgbox)",
+	} {
+		got := RenderMarkdown(in)
+		if strings.Contains(got, "<a ") || strings.Contains(strings.ToLower(got), "script:") || strings.Contains(got, "<script") {
+			t.Errorf("RenderMarkdown(%q) = %q, want no link", in, got)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://e.com", "https://e.com"},
+		{"HTTP://E.com", "http://E.com"},
+		{"mailto:a@b.c", "mailto:a@b.c"},
+		{` https://e.com "标题"`, "https://e.com"},
+		{"<https://e.com>", "https://e.com"},
+		{"javascript:alert(1)", ""},
+		{"JaVaScRiPt:x", ""},
+		{"java\tscript:x", ""},
+		{"data:text/html,x", ""},
+		{"vbscript:x", ""},
+		{"/relative", ""},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.in); got != tt.want {
+			t.Errorf("safeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
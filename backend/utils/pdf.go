@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	pdfPageWidth  = 595.0 // A4，单位为点
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// PDFCell 一行中从横坐标 X 开始的一段文本
+type PDFCell struct {
+	X    float64
+	Text string
+}
+
+// PDFDocument 生成只包含文本、横线和色条的简单 PDF 文档。文字使用 Adobe 的
+// STSong-Light 中文字体，由阅读器提供字形，文件中不需要嵌入字体
+type PDFDocument struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func NewPDFDocument() *PDFDocument {
+	d := &PDFDocument{}
+	d.newPage()
+	return d
+}
+
+// ContentWidth 返回页面可写区域的宽度
+func (d *PDFDocument) ContentWidth() float64 {
+	return pdfPageWidth - 2*pdfMargin
+}
+
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+func (d *PDFDocument) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensure 剩余空间不足 height 时换页
+func (d *PDFDocument) ensure(height float64) {
+	if d.y-height < pdfMargin {
+		d.newPage()
+	}
+}
+
+// Text 写入一段文本，超出页面宽度时自动换行
+func (d *PDFDocument) Text(size float64, text string) {
+	for _, line := range wrapText(text, size, d.ContentWidth()) {
+		d.Line(size, PDFCell{X: 0, Text: line})
+	}
+}
+
+// Line 在同一行的不同位置写入多段文本
+func (d *PDFDocument) Line(size float64, cells ...PDFCell) {
+	height := size * 1.6
+	d.ensure(height)
+	d.y -= height
+	for _, cell := range cells {
+		fmt.Fprintf(d.page(), "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", size, pdfMargin+cell.X, d.y, encodeUCS2(cell.Text))
+	}
+}
+
+// Gap 留出空白
+func (d *PDFDocument) Gap(height float64) {
+	d.y -= height
+}
+
+// Rule 画一条横线
+func (d *PDFDocument) Rule() {
+	d.ensure(8)
+	d.y -= 4
+	fmt.Fprintf(d.page(), "0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, d.y, pdfPageWidth-pdfMargin, d.y)
+	d.y -= 4
+}
+
+// Bar 画一条宽度按 ratio 缩放的色条，颜色为 0~1 的 RGB 分量
+func (d *PDFDocument) Bar(ratio float64, r, g, b float64) {
+	if ratio < 0 {
+		ratio = 0
+	}
+	if ratio > 1 {
+		ratio = 1
+	}
+	d.ensure(10)
+	d.y -= 8
+	fmt.Fprintf(d.page(), "0.92 g %.2f %.2f %.2f 6 re f\n", pdfMargin, d.y, d.ContentWidth())
+	if ratio > 0 {
+		fmt.Fprintf(d.page(), "%.3f %.3f %.3f rg %.2f %.2f %.2f 6 re f\n", r, g, b, pdfMargin, d.y, d.ContentWidth()*ratio)
+	}
+	d.y -= 2
+}
+
+// Bytes 输出完整的 PDF 文件
+func (d *PDFDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 对象 1~5 为目录、页面树和字体，之后每页占用页面和内容两个对象
+	kids := bytes.Buffer{}
+	for i := range d.pages {
+		fmt.Fprintf(&kids, "%d 0 R ", 6+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 4 >> /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	object("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 7+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// encodeUCS2 把文本编码为 UCS-2 大端序的十六进制串，超出基本平面的字符替换为问号
+func encodeUCS2(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		if r > 0xFFFF || r == utf8.RuneError {
+			r = '?'
+		}
+		fmt.Fprintf(&buf, "%04X", r)
+	}
+	return buf.String()
+}
+
+// wrapText 按字符宽度估算换行，ASCII 字符按半角计算
+func wrapText(text string, size float64, width float64) []string {
+	var lines []string
+	var line []rune
+	lineWidth := 0.0
+	for _, r := range text {
+		w := size
+		if r < 0x80 {
+			w = size / 2
+		}
+		if lineWidth+w > width && len(line) > 0 {
+			lines = append(lines, string(line))
+			line = line[:0]
+			lineWidth = 0
+		}
+		line = append(line, r)
+		lineWidth += w
+	}
+	return append(lines, string(line))
+}
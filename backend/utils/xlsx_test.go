@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestXMLText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"普通文本", "普通文本"},
+		{"a\tb\nc\rd", "a\tb\nc\rd"},
+		{"a\x00b\x08c\x1fd", "abcd"},
+		{"a￾b￿c", "abc"},
+	}
+	for _, tt := range tests {
+		if got := xmlText(tt.in); got != tt.want {
+			t.Errorf("xmlText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXWriterControlCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewXLSXWriter(&buf)
+	if err := w.NewSheet("Results"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow("a\x01b", 1, 2.5, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := xml.NewDecoder(rc)
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+		rc.Close()
+	}
+}
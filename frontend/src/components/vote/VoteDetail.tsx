@@ -13,6 +13,7 @@ interface VoteOption {
   vote_id: number;
   content: string;
   count: number;
+  write_in?: boolean;
 }
 
 const VoteDetail: React.FC = () => {
@@ -22,6 +23,7 @@ const VoteDetail: React.FC = () => {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string>('');
   const [selectedOptions, setSelectedOptions] = useState<number[]>([]);
+  const [writeIn, setWriteIn] = useState('');
   const [submitting, setSubmitting] = useState(false);
 
   const [voteOwner, setVoteOwner] = useState(false);
@@ -74,15 +76,23 @@ const VoteDetail: React.FC = () => {
     }
   };
 
+  // 是否选择了“其他”选项
+  const writeInSelected = vote?.options.some((option) => option.write_in && selectedOptions.includes(option.id)) ?? false;
+
   // 提交投票
   const handleSubmitVote = async () => {
     if (!vote || selectedOptions.length === 0) return;
+    if (writeInSelected && !writeIn.trim()) {
+      alert('请填写“其他”选项的内容');
+      return;
+    }
 
     try {
       setSubmitting(true);
       const res = await submitVote({
         vote_id: vote.id,
         option_ids: selectedOptions,
+        write_in: writeInSelected ? writeIn.trim() : undefined,
       });
 
       if (res.code === 200) {
@@ -235,6 +245,14 @@ const VoteDetail: React.FC = () => {
       <div className="max-w-4xl mx-auto px-4 py-8">
         <div className="bg-white rounded-lg shadow-sm border border-gray-200">
           <div className="p-6">
+            {/* 投票描述，HTML 由服务端渲染并转义 */}
+            {vote.description_html && (
+              <div
+                className="mb-6 markdown text-gray-700"
+                dangerouslySetInnerHTML={{ __html: vote.description_html }}
+              />
+            )}
+
             {/* 投票说明 */}
             {!vote.has_voted && !expired && (
               <div className="mb-6 p-4 bg-blue-50 border border-blue-200 rounded-lg">
@@ -285,12 +303,47 @@ const VoteDetail: React.FC = () => {
                       </div>
                     </div>
 
+                    {option.description_html && (
+                      <div
+                        className="mb-2 markdown text-gray-600"
+                        dangerouslySetInnerHTML={{ __html: option.description_html }}
+                      />
+                    )}
+
+                    {option.images && option.images.length > 0 && (
+                      <div className="mb-2 flex flex-wrap gap-2">
+                        {option.images.map((image) => (
+                          <a
+                            key={image.id}
+                            href={image.url}
+                            target="_blank"
+                            rel="noopener noreferrer"
+                            onClick={(e) => e.stopPropagation()}>
+                            <img src={image.thumb_url} alt={option.content} className="h-20 rounded border border-gray-200" />
+                          </a>
+                        ))}
+                      </div>
+                    )}
+
                     {/* 进度条 */}
                     <div className="w-full bg-gray-200 rounded-full h-2">
                       <div
                         className="bg-blue-600 h-2 rounded-full transition-all duration-300"
                         style={{ width: `${percentage}%` }}></div>
                     </div>
+
+                    {/* “其他”选项的填写内容 */}
+                    {option.write_in && isSelected && !vote.has_voted && (
+                      <input
+                        type="text"
+                        value={writeIn}
+                        maxLength={100}
+                        placeholder="请填写内容"
+                        onClick={(e) => e.stopPropagation()}
+                        onChange={(e) => setWriteIn(e.target.value)}
+                        className="mt-3 w-full border border-gray-300 rounded-md px-3 py-2 text-sm focus:outline-none focus:ring-2 focus:ring-blue-500"
+                      />
+                    )}
                   </div>
                 );
               })}
@@ -10,6 +10,8 @@ interface Vote {
   deadline: number;
   creator_id: number;
   created_at: string;
+  voter_count?: number;
+  comment_count?: number;
   options: VoteOption[];
 }
 
@@ -23,7 +25,10 @@ interface VoteOption {
 const VoteList: React.FC = () => {
   const navigate = useNavigate();
   const [votes, setVotes] = useState<Vote[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState('');
   const [loading, setLoading] = useState(true);
+  const [loadingMore, setLoadingMore] = useState(false);
   const [error, setError] = useState<string>('');
   const [currentTime, setCurrentTime] = useState<number>(Date.now());
 
@@ -34,8 +39,10 @@ const VoteList: React.FC = () => {
       setLoading(true);
       const res = await getAllVotes();
 
-      if (res.code === 200) {
-        setVotes(res.data || []);
+      if (res.code === 200 && res.data) {
+        setVotes(res.data.items);
+        setTotal(res.data.total);
+        setNextCursor(res.data.has_more ? res.data.next_cursor : '');
       } else {
         setError(res.message || '获取投票失败');
       }
@@ -47,6 +54,29 @@ const VoteList: React.FC = () => {
     }
   };
 
+  // 加载下一页
+  const loadMore = async () => {
+    if (!nextCursor) return;
+    try {
+      setLoadingMore(true);
+      const res = await getAllVotes({ cursor: nextCursor });
+
+      if (res.code === 200 && res.data) {
+        const page = res.data;
+        setVotes((prev) => [...prev, ...page.items]);
+        setTotal(page.total);
+        setNextCursor(page.has_more ? page.next_cursor : '');
+      } else {
+        setError(res.message || '获取投票失败');
+      }
+    } catch (err) {
+      setError('网络错误，请稍后重试');
+      console.error('获取投票失败:', err);
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
   useEffect(() => {
     fetchVotes();
 
@@ -131,7 +161,7 @@ const VoteList: React.FC = () => {
               className="inline-flex items-center px-4 py-2 border border-gray-300 rounded-xl text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 hover:border-gray-400 transition-all duration-200 shadow-sm hover:shadow-md">
               🔄 刷新
             </button>
-            <div className="hidden sm:block text-sm text-gray-500">共 {votes.length} 个投票</div>
+            <div className="hidden sm:block text-sm text-gray-500">共 {total} 个投票</div>
           </div>
           <button
             onClick={() => navigate('/create')}
@@ -218,7 +248,8 @@ const VoteList: React.FC = () => {
                   {/* 投票统计 */}
                   <div className="flex items-center justify-between pt-4 border-t border-gray-100">
                     <div className="flex items-center text-sm text-gray-600">
-                      <span className="inline-flex items-center">👥 {0} 人参与</span>
+                      <span className="inline-flex items-center">👥 {vote.voter_count ?? 0} 人参与</span>
+                      <span className="inline-flex items-center ml-4">💬 {vote.comment_count ?? 0} 条评论</span>
                     </div>
                     <div
                       className={`text-sm font-medium ${
@@ -236,6 +267,16 @@ const VoteList: React.FC = () => {
             ))}
           </div>
         )}
+        {nextCursor && (
+          <div className="text-center mt-8">
+            <button
+              onClick={loadMore}
+              disabled={loadingMore}
+              className="inline-flex items-center px-6 py-3 border border-gray-300 rounded-xl text-sm font-medium text-gray-700 bg-white hover:bg-gray-50 hover:border-gray-400 transition-all duration-200 shadow-sm hover:shadow-md disabled:opacity-50">
+              {loadingMore ? '加载中...' : '加载更多'}
+            </button>
+          </div>
+        )}
       </div>
     </div>
   );
@@ -1,4 +1,4 @@
-import type { Vote, CreateVoteRequest, UpdateVoteRequest, VoteRequest } from '@/types/vote';
+import type { Vote, CreateVoteRequest, UpdateVoteRequest, VoteRequest, VoteList, VoteListQuery } from '@/types/vote';
 import type { Response } from '@/types/api';
 import api from './api';
 
@@ -40,11 +40,15 @@ export const deleteVote = (id: string | number) => api.delete(`${BASE_URL}/${id}
 export const submitVote = (data: VoteRequest): Promise<Response<void>> => api.post(`${BASE_URL}/submit`, data);
 
 /**
- * 获取用户的投票列表
+ * 获取用户创建的投票列表
+ * @param params 分页、筛选和排序参数
  */
-export const getUserVotes = () => api.get(`${BASE_URL}/my`);
+export const getUserVotes = (params?: VoteListQuery): Promise<Response<VoteList>> =>
+  api.get(`${BASE_URL}/my`, { params });
 
 /**
  * 获取所有投票列表
+ * @param params 分页、筛选和排序参数
  */
-export const getAllVotes = (): Promise<Response<Vote[]>> => api.get(`${BASE_URL}/all`);
+export const getAllVotes = (params?: VoteListQuery): Promise<Response<VoteList>> =>
+  api.get(`${BASE_URL}/all`, { params });
@@ -3,9 +3,22 @@ export interface VoteOption {
   vote_id: number;
   content: string;
   count: number;
+  write_in?: boolean; // “其他”选项，选择时需要填写内容
+  description?: string;
+  description_html?: string; // 服务端渲染并转义后的说明
+  images?: OptionImage[]; // 只有投票详情返回
   user_votes?: UserVote[];
 }
 
+export interface OptionImage {
+  id: number;
+  option_id: number;
+  width: number;
+  height: number;
+  url: string;
+  thumb_url: string;
+}
+
 export interface UserVote {
   id: number;
   user_id: number;
@@ -17,15 +30,42 @@ export interface UserVote {
 export interface Vote {
   id: number;
   title: string;
+  description?: string;
+  description_html?: string; // 服务端渲染并转义后的描述
   multi: boolean;
   deadline: number;
   creator_id: number;
   created_at: string;
   updated_at: string;
+  voter_count?: number;
+  comment_count?: number;
   options: VoteOption[];
   user_votes?: UserVote[];
 }
 
+// 投票列表查询参数
+export interface VoteListQuery {
+  cursor?: string;
+  limit?: number;
+  sort?: 'newest' | 'ending' | 'votes';
+  state?: 'open' | 'closed';
+  creator_id?: number;
+  multi?: boolean;
+  weighted?: boolean;
+  voted?: boolean;
+  deadline_from?: number;
+  deadline_to?: number;
+  tag?: string;
+}
+
+// 分页的投票列表
+export interface VoteList {
+  items: Vote[];
+  total: number;
+  next_cursor: string;
+  has_more: boolean;
+}
+
 // 创建投票请求
 export interface CreateVoteRequest {
   title: string;
@@ -47,6 +87,7 @@ export interface UpdateVoteRequest {
 export interface VoteRequest {
   vote_id: number;
   option_ids: number[];
+  write_in?: string;
 }
 
 // 投票响应